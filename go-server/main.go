@@ -12,6 +12,7 @@ import (
 	"github.com/gofiber/swagger"
 	_ "go-server/docs"
 	"go-server/pkg/authentication"
+	"go-server/pkg/collections"
 	"go-server/pkg/games"
 	"go-server/pkg/reviews"
 	"go.mongodb.org/mongo-driver/bson"
@@ -53,8 +54,17 @@ func main() {
 		panic(err)
 	}
 
+	// game media uploads may be larger than the default body limit, the rest of
+	// the multipart form gets a megabyte on top of the image
+	bodyLimit := int(games.GetMediaMaxUploadBytes()) + 1024*1024
+	if bodyLimit < fiber.DefaultBodyLimit {
+		bodyLimit = fiber.DefaultBodyLimit
+	}
+
 	var app *fiber.App
-	app = fiber.New()
+	app = fiber.New(fiber.Config{
+		BodyLimit: bodyLimit,
+	})
 
 	// log the requests
 	app.Use(cors.New())
@@ -80,19 +90,32 @@ func main() {
 
 	authNeeds := authentication.NewAuthNeeds()
 
+	// each package builds its indexes while registering, the server must not run
+	// without the unique ones
 	err = authentication.Register(initResponse.MongoDbClient, ctx, apiGroup)
-	err = games.Register(initResponse.MongoDbClient, ctx, apiGroup, authNeeds)
-	err = reviews.Register(initResponse.MongoDbClient, ctx, apiGroup, authNeeds)
+	if err != nil {
+		log.Fatal(err, "Error while registering the authentication routes")
+	}
 
-	//_generateGames(initResponse.MongoDbClient)
+	gameService, err := games.Register(initResponse.MongoDbClient, ctx, apiGroup, authNeeds)
+	if err != nil {
+		log.Fatal(err, "Error while registering the game routes")
+	}
 
-	//_generateRandomReviews(initResponse.MongoDbClient)
+	err = reviews.Register(initResponse.MongoDbClient, ctx, apiGroup, authNeeds, gameService)
+	if err != nil {
+		log.Fatal(err, "Error while registering the review routes")
+	}
 
+	err = collections.Register(initResponse.MongoDbClient, ctx, apiGroup, authNeeds)
 	if err != nil {
-		log.Fatal(err, "Error while registering routes")
-		return
+		log.Fatal(err, "Error while registering the collection routes")
 	}
 
+	//_generateGames(initResponse.MongoDbClient)
+
+	//_generateRandomReviews(initResponse.MongoDbClient)
+
 	port := os.Getenv("PORT")
 
 	fmt.Println("Server is running on port: " + port)
@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Entry is a cached response body with the validators it was served with.
+// Location is only set for redirects, which have no body.
+type Entry struct {
+	Body         []byte
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+	Location     string
+}
+
+// Cache stores responses by key. Keys are colon separated, such as
+// "games:en:/api/v1/games?limit=20", so related entries can be dropped together
+// by prefix. Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(ctx context.Context, key string) (*Entry, bool)
+	Set(ctx context.Context, key string, entry *Entry)
+	DeletePrefix(ctx context.Context, prefix string)
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRUCache keeps up to capacity entries in memory, evicting the least recently
+// used one when full. Entries older than ttl are treated as missing, a ttl of 0
+// keeps them until evicted or deleted.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type lruItem struct {
+	key      string
+	entry    *Entry
+	storedAt time.Time
+}
+
+func NewLRUCache(capacity int, ttl time.Duration) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &LRUCache{
+		capacity: capacity,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (l *LRUCache) Get(ctx context.Context, key string) (*Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := element.Value.(*lruItem)
+
+	if l.ttl > 0 && time.Since(item.storedAt) > l.ttl {
+		l.remove(element)
+		return nil, false
+	}
+
+	l.order.MoveToFront(element)
+
+	return item.entry, true
+}
+
+func (l *LRUCache) Set(ctx context.Context, key string, entry *Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		element.Value = &lruItem{key: key, entry: entry, storedAt: time.Now()}
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruItem{key: key, entry: entry, storedAt: time.Now()})
+
+	for l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *LRUCache) DeletePrefix(ctx context.Context, prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, element := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(element)
+		}
+	}
+}
+
+func (l *LRUCache) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*lruItem).key)
+}
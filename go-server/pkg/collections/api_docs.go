@@ -0,0 +1,267 @@
+package collections
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleAddCollection godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Create a collection
+//	@Description	Creates a collection of the user, e.g. a wishlist, a backlog or a curated list. Its slug is made from the title and used to share it
+//	@Tags			collections
+//	@ID				addCollection
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			addCollection	body		collections.AddCollectionRequest 	true			"addCollection request"
+//
+//	@Success		201				{object}	main.JSONResult{data=collections.Collection}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes	"Bad request"
+//	@Router			/api/v1/collections/add [post]
+func HandleAddCollection(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AddCollection(ctx, c)
+	}
+}
+
+// HandleGetMyCollections godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the collections of the user
+//	@Description	Gets the collections of the calling user, last updated first
+//	@Tags			collections
+//	@ID				getMyCollections
+//	@Produce		json
+//
+//	@Param			pagination	query		collections.PaginationQueries 	false			"pagination"
+//
+//	@Success		200				{object}	main.JSONResult{data=collections.PaginatedResponse[collections.Collection]}	"Success"
+//	@Router			/api/v1/collections/mine [get]
+func HandleGetMyCollections(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetMyCollections(ctx, c)
+	}
+}
+
+// HandleGetPublicCollections godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the public collections
+//	@Description	Gets the collections shared publicly by all users, last updated first
+//	@Tags			collections
+//	@ID				getPublicCollections
+//	@Produce		json
+//
+//	@Param			pagination	query		collections.PaginationQueries 	false			"pagination"
+//
+//	@Success		200				{object}	main.JSONResult{data=collections.PaginatedResponse[collections.Collection]}	"Success"
+//	@Router			/api/v1/collections/public [get]
+func HandleGetPublicCollections(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetPublicCollections(ctx, c)
+	}
+}
+
+// HandleGetCollection godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets a collection
+//	@Description	Gets a collection by its slug, private collections are only visible to their owner
+//	@Tags			collections
+//	@ID				getCollection
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//
+//	@Success		200				{object}	main.JSONResult{data=collections.Collection}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes	"Collection not found"
+//	@Router			/api/v1/collections/{slug} [get]
+func HandleGetCollection(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetCollection(ctx, c)
+	}
+}
+
+// HandleUpdateCollection godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Update a collection
+//	@Description	Updates the title, description or visibility of a collection of the user, the slug is kept
+//	@Tags			collections
+//	@ID				updateCollection
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//	@Param			updateCollection	body		collections.CollectionPatch 	true			"fields to update"
+//
+//	@Success		200				{object}	main.JSONResult{data=collections.Collection}	"Success"
+//	@Failure		403				{object}	main.JSONErrorRes	"Not the owner"
+//	@Failure		404				{object}	main.JSONErrorRes	"Collection not found"
+//	@Router			/api/v1/collections/{slug} [put]
+func HandleUpdateCollection(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.UpdateCollection(ctx, c)
+	}
+}
+
+// HandleDeleteCollection godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Delete a collection
+//	@Description	Deletes a collection of the user and its games, moderators can delete the collections they can see
+//	@Tags			collections
+//	@ID				deleteCollection
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		403				{object}	main.JSONErrorRes	"Not the owner"
+//	@Failure		404				{object}	main.JSONErrorRes	"Collection not found"
+//	@Router			/api/v1/collections/{slug} [delete]
+func HandleDeleteCollection(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DeleteCollection(ctx, c)
+	}
+}
+
+// HandleGetCollectionItems godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the games of a collection
+//	@Description	Gets the games of a collection in their order, optionally only the ones with a status
+//	@Tags			collections
+//	@ID				getCollectionItems
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//	@Param			getCollectionItems	query		collections.GetCollectionItemsQueries 	false			"status and pagination"
+//
+//	@Success		200				{object}	main.JSONResult{data=collections.PaginatedResponse[collections.CollectionItem]}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes	"Collection not found"
+//	@Router			/api/v1/collections/{slug}/items [get]
+func HandleGetCollectionItems(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetCollectionItems(ctx, c)
+	}
+}
+
+// HandleAddCollectionItem godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Add a game to a collection
+//	@Description	Adds a game at the end of a collection of the user, with an optional status and notes
+//	@Tags			collections
+//	@ID				addCollectionItem
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//	@Param			addCollectionItem	body		collections.AddCollectionItemRequest 	true			"addCollectionItem request"
+//
+//	@Success		201				{object}	main.JSONResult{data=collections.CollectionItem}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes	"Collection or game not found"
+//	@Failure		409				{object}	main.JSONErrorRes	"Game already in the collection"
+//	@Router			/api/v1/collections/{slug}/items [post]
+func HandleAddCollectionItem(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AddCollectionItem(ctx, c)
+	}
+}
+
+// HandleReorderCollection godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Reorder a collection
+//	@Description	Puts the games of a collection of the user in the given order, every game must be listed once
+//	@Tags			collections
+//	@ID				reorderCollection
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//	@Param			reorderCollection	body		collections.ReorderCollectionRequest 	true			"new order"
+//
+//	@Success		200				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes	"Invalid order"
+//	@Router			/api/v1/collections/{slug}/items/order [put]
+func HandleReorderCollection(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.ReorderCollection(ctx, c)
+	}
+}
+
+// HandleUpdateCollectionItem godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Update a game of a collection
+//	@Description	Updates the status or the notes of a game in a collection of the user
+//	@Tags			collections
+//	@ID				updateCollectionItem
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//	@Param			gameId	path		string 	true			"game id"
+//	@Param			updateCollectionItem	body		collections.CollectionItemPatch 	true			"fields to update"
+//
+//	@Success		200				{object}	main.JSONResult{data=collections.CollectionItem}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes	"Game not in the collection"
+//	@Router			/api/v1/collections/{slug}/items/{gameId} [put]
+func HandleUpdateCollectionItem(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.UpdateCollectionItem(ctx, c)
+	}
+}
+
+// HandleRemoveCollectionItem godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Remove a game from a collection
+//	@Description	Removes a game from a collection of the user
+//	@Tags			collections
+//	@ID				removeCollectionItem
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//	@Param			gameId	path		string 	true			"game id"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes	"Game not in the collection"
+//	@Router			/api/v1/collections/{slug}/items/{gameId} [delete]
+func HandleRemoveCollectionItem(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.RemoveCollectionItem(ctx, c)
+	}
+}
+
+func GetNewContext(ctx context.Context, c *fiber.Ctx) context.Context {
+	ctx = context.WithValue(ctx, "userId", c.Locals("userId"))
+	ctx = context.WithValue(ctx, "role", c.Locals("role"))
+	return ctx
+}
@@ -0,0 +1,23 @@
+package collections
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	auth "go-server/pkg/authentication"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func Register(mongoClient *mongo.Client, ctx context.Context, app fiber.Router, authNeeds *auth.AuthNeeds) error {
+
+	repo := NewRepositoryImpl(mongoClient)
+
+	if err := repo.ensureIndexes(ctx); err != nil {
+		return err
+	}
+
+	service := NewService(repo)
+
+	handler := NewHandler(service)
+
+	return router(ctx, app, handler, authNeeds.AuthMiddleware)
+}
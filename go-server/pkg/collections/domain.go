@@ -0,0 +1,453 @@
+package collections
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"go-server/pkg/games"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// how many times a collection is retried with a numbered slug when its slug is taken
+	maxSlugAttempts = 10
+)
+
+type Service struct {
+	validate   *validator.Validate
+	repository Repository
+}
+
+type PaginatedResponseType interface {
+	Collection | CollectionItem
+}
+
+type PaginatedResponse[V PaginatedResponseType] struct {
+	Data         []V  `json:"data"`
+	CurrentPage  int  `json:"currentPage"`
+	TotalPages   int  `json:"totalPages"`
+	TotalItems   int  `json:"totalItems"`
+	HasMore      bool `json:"hasMore"`
+	ItemsPerPage int  `json:"itemsPerPage"`
+}
+
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type Repository interface {
+	saveCollection(ctx context.Context, collection *Collection) error
+	getCollection(ctx context.Context, slug string) (*Collection, error)
+	getUserCollections(ctx context.Context, userId string, pagination *Pagination) (*PaginatedResponse[Collection], error)
+	getPublicCollections(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Collection], error)
+	updateCollection(ctx context.Context, collection *Collection) error
+	deleteCollection(ctx context.Context, id primitive.ObjectID) error
+	gameExists(ctx context.Context, id primitive.ObjectID) (bool, error)
+	getGamesByIds(ctx context.Context, ids []primitive.ObjectID) ([]games.Game, error)
+	addCollectionItem(ctx context.Context, item *CollectionItem) error
+	updateCollectionItem(ctx context.Context, item *CollectionItem) error
+	getCollectionItem(ctx context.Context, collectionId primitive.ObjectID, gameId primitive.ObjectID) (*CollectionItem, error)
+	removeCollectionItem(ctx context.Context, collectionId primitive.ObjectID, gameId primitive.ObjectID) error
+	getCollectionItems(ctx context.Context, collectionId primitive.ObjectID, status GameStatus, pagination *Pagination) (*PaginatedResponse[CollectionItem], error)
+	getCollectionGameIds(ctx context.Context, collectionId primitive.ObjectID) ([]primitive.ObjectID, error)
+	reorderCollectionItems(ctx context.Context, collectionId primitive.ObjectID, gameIds []primitive.ObjectID) error
+}
+
+func NewService(repository Repository) *Service {
+	return &Service{
+		validate:   validator.New(),
+		repository: repository,
+	}
+}
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// getSlug turns a title into a url friendly slug, "Best co-op games!" gives
+// "best-co-op-games".
+func getSlug(title string) string {
+	slug := nonSlugCharacters.ReplaceAllString(strings.ToLower(title), "-")
+	slug = strings.Trim(slug, "-")
+
+	if slug == "" {
+		slug = "collection"
+	}
+
+	return slug
+}
+
+func userIdFromContext(ctx context.Context) (string, error) {
+	userId, _ := ctx.Value("userId").(string)
+
+	if userId == "" {
+		return "", ErrUnauthorized
+	}
+
+	return userId, nil
+}
+
+func isModerator(ctx context.Context) bool {
+	role, _ := ctx.Value("role").(string)
+
+	return role == "admin" || role == "moderator"
+}
+
+// CreateCollection saves a new collection of the calling user. Its slug comes
+// from the title and gets a number appended while it is taken, e.g.
+// "best-co-op-games-2".
+func (s *Service) CreateCollection(ctx context.Context, collection *Collection) error {
+
+	userId, err := userIdFromContext(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	collection.Title = strings.TrimSpace(collection.Title)
+	collection.Desc = strings.TrimSpace(collection.Desc)
+
+	if collection.Visibility == "" {
+		collection.Visibility = VisibilityPrivate
+	}
+
+	if err := s.validate.Struct(collection); err != nil || !collection.Visibility.isValid() {
+		return ErrBadRequest
+	}
+
+	now := time.Now()
+
+	collection.UserId = userId
+	collection.ItemCount = 0
+	collection.NextPosition = 1
+	collection.CreatedAt = now
+	collection.UpdatedAt = now
+
+	base := getSlug(collection.Title)
+
+	for attempt := 1; attempt <= maxSlugAttempts; attempt++ {
+		collection.Slug = base
+
+		if attempt > 1 {
+			collection.Slug = base + "-" + strconv.Itoa(attempt)
+		}
+
+		err = s.repository.saveCollection(ctx, collection)
+
+		if err != ErrSlugTaken {
+			return err
+		}
+	}
+
+	// a popular title, fall back to a slug nobody else can have
+	collection.Id = primitive.NewObjectID()
+	collection.Slug = base + "-" + collection.Id.Hex()
+
+	return s.repository.saveCollection(ctx, collection)
+}
+
+// viewableCollection returns the collection if the calling user can see it.
+// Private collections of others are reported as not found.
+func (s *Service) viewableCollection(ctx context.Context, slug string) (*Collection, error) {
+
+	userId, err := userIdFromContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := s.repository.getCollection(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.Visibility == VisibilityPrivate && collection.UserId != userId {
+		return nil, ErrNotFound
+	}
+
+	return collection, nil
+}
+
+// ownCollection returns the collection if the calling user owns it.
+func (s *Service) ownCollection(ctx context.Context, slug string) (*Collection, error) {
+
+	collection, err := s.viewableCollection(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := userIdFromContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if collection.UserId != userId {
+		return nil, ErrUnauthorized
+	}
+
+	return collection, nil
+}
+
+func (s *Service) GetCollection(ctx context.Context, slug string) (*Collection, error) {
+	return s.viewableCollection(ctx, slug)
+}
+
+func (s *Service) GetMyCollections(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Collection], error) {
+
+	userId, err := userIdFromContext(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.getUserCollections(ctx, userId, pagination)
+}
+
+func (s *Service) GetPublicCollections(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Collection], error) {
+	return s.repository.getPublicCollections(ctx, pagination)
+}
+
+// UpdateCollection changes the title, description or visibility of a collection
+// of the calling user. The slug is kept so shared links keep working.
+func (s *Service) UpdateCollection(ctx context.Context, slug string, patch *CollectionPatch) (*Collection, error) {
+
+	collection, err := s.ownCollection(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Title != nil {
+		collection.Title = strings.TrimSpace(*patch.Title)
+	}
+
+	if patch.Desc != nil {
+		collection.Desc = strings.TrimSpace(*patch.Desc)
+	}
+
+	if patch.Visibility != nil {
+		collection.Visibility = *patch.Visibility
+	}
+
+	if err := s.validate.Struct(collection); err != nil || !collection.Visibility.isValid() {
+		return nil, ErrBadRequest
+	}
+
+	collection.UpdatedAt = time.Now()
+
+	if err := s.repository.updateCollection(ctx, collection); err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// DeleteCollection deletes a collection and its items. Moderators can delete the
+// collections others can see.
+func (s *Service) DeleteCollection(ctx context.Context, slug string) error {
+
+	collection, err := s.viewableCollection(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	userId, _ := ctx.Value("userId").(string)
+
+	if collection.UserId != userId && !isModerator(ctx) {
+		return ErrUnauthorized
+	}
+
+	return s.repository.deleteCollection(ctx, collection.Id)
+}
+
+// GetCollectionItems lists the games of a collection in their order, only the
+// ones with the status when one is given.
+func (s *Service) GetCollectionItems(ctx context.Context, slug string, status GameStatus, pagination *Pagination) (*PaginatedResponse[CollectionItem], error) {
+
+	if !status.isValid() {
+		return nil, ErrBadRequest
+	}
+
+	collection, err := s.viewableCollection(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.repository.getCollectionItems(ctx, collection.Id, status, pagination)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) == 0 {
+		return response, nil
+	}
+
+	gameIds := make([]primitive.ObjectID, 0, len(response.Data))
+	for _, item := range response.Data {
+		gameIds = append(gameIds, item.GameId)
+	}
+
+	games, err := s.repository.getGamesByIds(ctx, gameIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range games {
+		for j := range response.Data {
+			if response.Data[j].GameId == games[i].Id {
+				response.Data[j].Game = &games[i]
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// AddCollectionItem adds a game at the end of a collection of the calling user.
+func (s *Service) AddCollectionItem(ctx context.Context, slug string, item *CollectionItem) (*CollectionItem, error) {
+
+	collection, err := s.ownCollection(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	item.Notes = strings.TrimSpace(item.Notes)
+
+	if err := s.validate.Struct(item); err != nil || !item.Status.isValid() {
+		return nil, ErrBadRequest
+	}
+
+	exists, err := s.repository.gameExists(ctx, item.GameId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, ErrGameNotFound
+	}
+
+	now := time.Now()
+
+	item.CollectionId = collection.Id
+	item.AddedAt = now
+	item.UpdatedAt = now
+
+	if err := s.repository.addCollectionItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// UpdateCollectionItem changes the status or the notes of a game in a collection
+// of the calling user.
+func (s *Service) UpdateCollectionItem(ctx context.Context, slug string, gameId string, patch *CollectionItemPatch) (*CollectionItem, error) {
+
+	collection, err := s.ownCollection(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(gameId)
+
+	if err != nil {
+		return nil, ErrGameNotInCollection
+	}
+
+	item, err := s.repository.getCollectionItem(ctx, collection.Id, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.Status != nil {
+		item.Status = *patch.Status
+	}
+
+	if patch.Notes != nil {
+		item.Notes = strings.TrimSpace(*patch.Notes)
+	}
+
+	if err := s.validate.Struct(item); err != nil || !item.Status.isValid() {
+		return nil, ErrBadRequest
+	}
+
+	item.UpdatedAt = time.Now()
+
+	if err := s.repository.updateCollectionItem(ctx, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (s *Service) RemoveCollectionItem(ctx context.Context, slug string, gameId string) error {
+
+	collection, err := s.ownCollection(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(gameId)
+
+	if err != nil {
+		return ErrGameNotInCollection
+	}
+
+	return s.repository.removeCollectionItem(ctx, collection.Id, id)
+}
+
+// ReorderCollection puts the games of a collection of the calling user in the
+// given order, which must list every game of the collection exactly once.
+func (s *Service) ReorderCollection(ctx context.Context, slug string, gameIds []string) error {
+
+	collection, err := s.ownCollection(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	current, err := s.repository.getCollectionGameIds(ctx, collection.Id)
+
+	if err != nil {
+		return err
+	}
+
+	if len(gameIds) != len(current) {
+		return ErrInvalidOrder
+	}
+
+	inCollection := make(map[primitive.ObjectID]bool, len(current))
+	for _, id := range current {
+		inCollection[id] = true
+	}
+
+	order := make([]primitive.ObjectID, 0, len(gameIds))
+
+	for _, gameId := range gameIds {
+		id, err := primitive.ObjectIDFromHex(gameId)
+
+		if err != nil || !inCollection[id] {
+			return ErrInvalidOrder
+		}
+
+		// removed so a game listed twice is rejected
+		delete(inCollection, id)
+		order = append(order, id)
+	}
+
+	return s.repository.reorderCollectionItems(ctx, collection.Id, order)
+}
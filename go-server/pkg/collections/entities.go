@@ -0,0 +1,78 @@
+package collections
+
+import (
+	"go-server/pkg/games"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Visibility is who can see a collection. Unlisted collections can be opened by
+// anyone with their slug but are not listed with the public ones.
+type Visibility string
+
+const (
+	VisibilityPrivate  Visibility = "private"
+	VisibilityUnlisted Visibility = "unlisted"
+	VisibilityPublic   Visibility = "public"
+)
+
+func (v Visibility) isValid() bool {
+	return v == VisibilityPrivate || v == VisibilityUnlisted || v == VisibilityPublic
+}
+
+// GameStatus is where the player is with a game, it is optional on curated lists.
+type GameStatus string
+
+const (
+	StatusOwned      GameStatus = "owned"
+	StatusPlaying    GameStatus = "playing"
+	StatusFinished   GameStatus = "finished"
+	StatusWantToPlay GameStatus = "want-to-play"
+)
+
+func (s GameStatus) isValid() bool {
+	return s == "" || s == StatusOwned || s == StatusPlaying || s == StatusFinished || s == StatusWantToPlay
+}
+
+// Collection is a list of games curated by a user, e.g. a wishlist, a backlog
+// or "Best co-op games". Slug is unique and used to share it.
+type Collection struct {
+	Id         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserId     string             `json:"userId" bson:"userId"`
+	Title      string             `json:"title" bson:"title" validate:"required,max=100"`
+	Slug       string             `json:"slug" bson:"slug"`
+	Desc       string             `json:"desc" bson:"desc" validate:"max=1000"`
+	Visibility Visibility         `json:"visibility" bson:"visibility"`
+	ItemCount  int                `json:"itemCount" bson:"itemCount"`
+	// position given to the next game added, so added games go last
+	NextPosition int       `json:"-" bson:"nextPosition"`
+	CreatedAt    time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+// CollectionItem is a game in a collection. Items are listed by Position. Game
+// is only set when served.
+type CollectionItem struct {
+	Id           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	CollectionId primitive.ObjectID `json:"collectionId" bson:"collectionId"`
+	GameId       primitive.ObjectID `json:"gameId" bson:"gameId"`
+	Status       GameStatus         `json:"status,omitempty" bson:"status,omitempty"`
+	Notes        string             `json:"notes" bson:"notes" validate:"max=1000"`
+	Position     int                `json:"position" bson:"position"`
+	AddedAt      time.Time          `json:"addedAt" bson:"addedAt"`
+	UpdatedAt    time.Time          `json:"updatedAt" bson:"updatedAt"`
+	Game         *games.Game        `json:"game,omitempty" bson:"-"`
+}
+
+// CollectionPatch holds the collection fields to update, nil fields are kept.
+type CollectionPatch struct {
+	Title      *string     `json:"title,omitempty"`
+	Desc       *string     `json:"desc,omitempty"`
+	Visibility *Visibility `json:"visibility,omitempty"`
+}
+
+// CollectionItemPatch holds the item fields to update, nil fields are kept.
+type CollectionItemPatch struct {
+	Status *GameStatus `json:"status,omitempty"`
+	Notes  *string     `json:"notes,omitempty"`
+}
@@ -0,0 +1,21 @@
+package collections
+
+import "errors"
+
+var ErrBadRequest = errors.New("bad-request")
+
+var ErrNotFound = errors.New("not-found")
+
+var ErrUnauthorized = errors.New("unauthorized")
+
+var UnknownError = errors.New("internal-server-error")
+
+var ErrGameNotFound = errors.New("game-not-found")
+
+var ErrGameAlreadyInCollection = errors.New("game-already-in-collection")
+
+var ErrGameNotInCollection = errors.New("game-not-in-collection")
+
+var ErrSlugTaken = errors.New("slug-taken")
+
+var ErrInvalidOrder = errors.New("invalid-order")
@@ -0,0 +1,255 @@
+package collections
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+type AddCollectionRequest struct {
+	Title string `json:"title" validate:"required"`
+	Desc  string `json:"desc"`
+	// private, unlisted or public, private by default
+	Visibility Visibility `json:"visibility,omitempty"`
+}
+
+type PaginationQueries struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type GetCollectionItemsQueries struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// owned, playing, finished or want-to-play
+	Status GameStatus `json:"status,omitempty"`
+}
+
+type AddCollectionItemRequest struct {
+	GameId string     `json:"gameId" validate:"required"`
+	Status GameStatus `json:"status,omitempty"`
+	Notes  string     `json:"notes,omitempty"`
+}
+
+type ReorderCollectionRequest struct {
+	// every game id of the collection, in the new order
+	GameIds []string `json:"gameIds" validate:"required"`
+}
+
+func getPagination(limit int, offset int) *Pagination {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return &Pagination{Limit: limit, Offset: offset}
+}
+
+func (h *Handler) AddCollection(ctx context.Context, c *fiber.Ctx) error {
+	var req AddCollectionRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return AddCollectionErrorResponse(c, ErrBadRequest)
+	}
+
+	collection := &Collection{
+		Title:      req.Title,
+		Desc:       req.Desc,
+		Visibility: req.Visibility,
+	}
+
+	err = h.service.CreateCollection(ctx, collection)
+
+	if err != nil {
+		return AddCollectionErrorResponse(c, err)
+	}
+
+	return AddCollectionSuccessResp(c, collection)
+}
+
+func (h *Handler) GetMyCollections(ctx context.Context, c *fiber.Ctx) error {
+	var req PaginationQueries
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return GetCollectionsErrorResponse(c, ErrBadRequest)
+	}
+
+	collections, err := h.service.GetMyCollections(ctx, getPagination(req.Limit, req.Offset))
+
+	if err != nil {
+		return GetCollectionsErrorResponse(c, err)
+	}
+
+	return GetCollectionsSuccessResp(c, collections)
+}
+
+func (h *Handler) GetPublicCollections(ctx context.Context, c *fiber.Ctx) error {
+	var req PaginationQueries
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return GetCollectionsErrorResponse(c, ErrBadRequest)
+	}
+
+	collections, err := h.service.GetPublicCollections(ctx, getPagination(req.Limit, req.Offset))
+
+	if err != nil {
+		return GetCollectionsErrorResponse(c, err)
+	}
+
+	return GetCollectionsSuccessResp(c, collections)
+}
+
+func (h *Handler) GetCollection(ctx context.Context, c *fiber.Ctx) error {
+	collection, err := h.service.GetCollection(ctx, c.Params("slug"))
+
+	if err != nil {
+		return GetCollectionErrorResponse(c, err)
+	}
+
+	return GetCollectionSuccessResp(c, collection)
+}
+
+func (h *Handler) UpdateCollection(ctx context.Context, c *fiber.Ctx) error {
+	var req CollectionPatch
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return UpdateCollectionErrorResponse(c, ErrBadRequest)
+	}
+
+	collection, err := h.service.UpdateCollection(ctx, c.Params("slug"), &req)
+
+	if err != nil {
+		return UpdateCollectionErrorResponse(c, err)
+	}
+
+	return UpdateCollectionSuccessResp(c, collection)
+}
+
+func (h *Handler) DeleteCollection(ctx context.Context, c *fiber.Ctx) error {
+	err := h.service.DeleteCollection(ctx, c.Params("slug"))
+
+	if err != nil {
+		return DeleteCollectionErrorResponse(c, err)
+	}
+
+	return DeleteCollectionSuccessResp(c)
+}
+
+func (h *Handler) GetCollectionItems(ctx context.Context, c *fiber.Ctx) error {
+	var req GetCollectionItemsQueries
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return GetCollectionItemsErrorResponse(c, ErrBadRequest)
+	}
+
+	items, err := h.service.GetCollectionItems(ctx, c.Params("slug"), req.Status, getPagination(req.Limit, req.Offset))
+
+	if err != nil {
+		return GetCollectionItemsErrorResponse(c, err)
+	}
+
+	return GetCollectionItemsSuccessResp(c, items)
+}
+
+func (h *Handler) AddCollectionItem(ctx context.Context, c *fiber.Ctx) error {
+	var req AddCollectionItemRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return AddCollectionItemErrorResponse(c, ErrBadRequest)
+	}
+
+	gameId, err := primitive.ObjectIDFromHex(req.GameId)
+
+	if err != nil {
+		return AddCollectionItemErrorResponse(c, ErrGameNotFound)
+	}
+
+	item := &CollectionItem{
+		GameId: gameId,
+		Status: req.Status,
+		Notes:  req.Notes,
+	}
+
+	item, err = h.service.AddCollectionItem(ctx, c.Params("slug"), item)
+
+	if err != nil {
+		return AddCollectionItemErrorResponse(c, err)
+	}
+
+	return AddCollectionItemSuccessResp(c, item)
+}
+
+func (h *Handler) UpdateCollectionItem(ctx context.Context, c *fiber.Ctx) error {
+	var req CollectionItemPatch
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return UpdateCollectionItemErrorResponse(c, ErrBadRequest)
+	}
+
+	item, err := h.service.UpdateCollectionItem(ctx, c.Params("slug"), c.Params("gameId"), &req)
+
+	if err != nil {
+		return UpdateCollectionItemErrorResponse(c, err)
+	}
+
+	return UpdateCollectionItemSuccessResp(c, item)
+}
+
+func (h *Handler) RemoveCollectionItem(ctx context.Context, c *fiber.Ctx) error {
+	err := h.service.RemoveCollectionItem(ctx, c.Params("slug"), c.Params("gameId"))
+
+	if err != nil {
+		return RemoveCollectionItemErrorResponse(c, err)
+	}
+
+	return RemoveCollectionItemSuccessResp(c)
+}
+
+func (h *Handler) ReorderCollection(ctx context.Context, c *fiber.Ctx) error {
+	var req ReorderCollectionRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return ReorderCollectionErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.service.ReorderCollection(ctx, c.Params("slug"), req.GameIds)
+
+	if err != nil {
+		return ReorderCollectionErrorResponse(c, err)
+	}
+
+	return ReorderCollectionSuccessResp(c)
+}
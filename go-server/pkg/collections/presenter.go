@@ -0,0 +1,309 @@
+package collections
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+func AddCollectionErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "You need to be logged in"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func AddCollectionSuccessResp(c *fiber.Ctx, collection *Collection) error {
+	return c.Status(fiber.StatusCreated).JSON(&fiber.Map{
+		"message": "Collection added",
+		"data":    collection,
+	})
+}
+
+func GetCollectionsErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid query"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "You need to be logged in"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetCollectionsSuccessResp(c *fiber.Ctx, collections *PaginatedResponse[Collection]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Collections",
+		"data":    collections,
+	})
+}
+
+func GetCollectionErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Collection not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetCollectionSuccessResp(c *fiber.Ctx, collection *Collection) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Collection",
+		"data":    collection,
+	})
+}
+
+func UpdateCollectionErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Collection not found"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusForbidden
+		message = "You are not allowed to change this collection"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func UpdateCollectionSuccessResp(c *fiber.Ctx, collection *Collection) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Collection updated",
+		"data":    collection,
+	})
+}
+
+func DeleteCollectionErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Collection not found"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusForbidden
+		message = "You are not allowed to change this collection"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func DeleteCollectionSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Collection deleted",
+		"data":    "",
+	})
+}
+
+func GetCollectionItemsErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid query, status must be owned, playing, finished or want-to-play"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Collection not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetCollectionItemsSuccessResp(c *fiber.Ctx, items *PaginatedResponse[CollectionItem]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Collection games",
+		"data":    items,
+	})
+}
+
+func AddCollectionItemErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Collection not found"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusForbidden
+		message = "You are not allowed to change this collection"
+	} else if err == ErrGameNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrGameAlreadyInCollection {
+		status = fiber.StatusConflict
+		message = "Game is already in the collection"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func AddCollectionItemSuccessResp(c *fiber.Ctx, item *CollectionItem) error {
+	return c.Status(fiber.StatusCreated).JSON(&fiber.Map{
+		"message": "Game added to the collection",
+		"data":    item,
+	})
+}
+
+func UpdateCollectionItemErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Collection not found"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusForbidden
+		message = "You are not allowed to change this collection"
+	} else if err == ErrGameNotInCollection {
+		status = fiber.StatusNotFound
+		message = "Game is not in the collection"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func UpdateCollectionItemSuccessResp(c *fiber.Ctx, item *CollectionItem) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Collection game updated",
+		"data":    item,
+	})
+}
+
+func RemoveCollectionItemErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Collection not found"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusForbidden
+		message = "You are not allowed to change this collection"
+	} else if err == ErrGameNotInCollection {
+		status = fiber.StatusNotFound
+		message = "Game is not in the collection"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func RemoveCollectionItemSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Game removed from the collection",
+		"data":    "",
+	})
+}
+
+func ReorderCollectionErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Collection not found"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusForbidden
+		message = "You are not allowed to change this collection"
+	} else if err == ErrInvalidOrder {
+		status = fiber.StatusBadRequest
+		message = "The order must list every game of the collection once"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func ReorderCollectionSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Collection reordered",
+		"data":    "",
+	})
+}
@@ -0,0 +1,499 @@
+package collections
+
+import (
+	"context"
+	"go-server/pkg/games"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"math"
+	"time"
+)
+
+const (
+	collectionsCollection     = "collections"
+	collectionItemsCollection = "collectionItems"
+	gamesCollection           = "games"
+)
+
+type RepositoryImpl struct {
+	mongoDbClient *mongo.Client
+}
+
+func NewRepositoryImpl(mongoDbClient *mongo.Client) *RepositoryImpl {
+	return &RepositoryImpl{
+		mongoDbClient: mongoDbClient,
+	}
+}
+
+// ensureIndexes makes collection slugs unique and a game appear once per collection.
+func (r *RepositoryImpl) ensureIndexes(ctx context.Context) error {
+	db := r.mongoDbClient.Database("test")
+
+	_, err := db.Collection(collectionsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"slug", 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{"userId", 1}, {"updatedAt", -1}}},
+		{Keys: bson.D{{"visibility", 1}, {"updatedAt", -1}}},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(collectionItemsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"collectionId", 1}, {"gameId", 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{"collectionId", 1}, {"position", 1}}},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (r *RepositoryImpl) saveCollection(ctx context.Context, collection *Collection) error {
+	res, err := r.mongoDbClient.Database("test").Collection(collectionsCollection).InsertOne(ctx, collection)
+
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSlugTaken
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	collection.Id = res.InsertedID.(primitive.ObjectID)
+
+	return nil
+}
+
+func (r *RepositoryImpl) getCollection(ctx context.Context, slug string) (*Collection, error) {
+	var collection Collection
+
+	err := r.mongoDbClient.Database("test").Collection(collectionsCollection).FindOne(ctx, bson.D{{"slug", slug}}).Decode(&collection)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &collection, nil
+}
+
+func (r *RepositoryImpl) getUserCollections(ctx context.Context, userId string, pagination *Pagination) (*PaginatedResponse[Collection], error) {
+	return r.findCollections(ctx, bson.D{{"userId", userId}}, pagination)
+}
+
+func (r *RepositoryImpl) getPublicCollections(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Collection], error) {
+	return r.findCollections(ctx, bson.D{{"visibility", VisibilityPublic}}, pagination)
+}
+
+// findCollections lists the collections matching the filter, last updated first.
+func (r *RepositoryImpl) findCollections(ctx context.Context, filter bson.D, pagination *Pagination) (*PaginatedResponse[Collection], error) {
+	var collections []Collection
+
+	opts := options.Find().SetSort(bson.D{{"updatedAt", -1}}).SetLimit(int64(pagination.Limit)).SetSkip(int64(pagination.Offset))
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(collectionsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &collections)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if len(collections) == 0 {
+		return &PaginatedResponse[Collection]{
+			TotalItems:   0,
+			TotalPages:   0,
+			CurrentPage:  0,
+			ItemsPerPage: 0,
+			HasMore:      false,
+			Data:         []Collection{},
+		}, nil
+	}
+
+	count, err := r.mongoDbClient.Database("test").Collection(collectionsCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[Collection]{
+		Data:         collections,
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		TotalItems:   int(count),
+		HasMore:      int(count) > (pagination.Offset + pagination.Limit),
+		ItemsPerPage: pagination.Limit,
+	}, nil
+}
+
+func (r *RepositoryImpl) updateCollection(ctx context.Context, collection *Collection) error {
+	update := bson.D{{"$set", bson.D{
+		{"title", collection.Title},
+		{"desc", collection.Desc},
+		{"visibility", collection.Visibility},
+		{"updatedAt", collection.UpdatedAt},
+	}}}
+
+	res, err := r.mongoDbClient.Database("test").Collection(collectionsCollection).UpdateOne(ctx, bson.D{{"_id", collection.Id}}, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// deleteCollection removes the collection and its items.
+func (r *RepositoryImpl) deleteCollection(ctx context.Context, id primitive.ObjectID) error {
+	session, err := r.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := r.mongoDbClient.Database("test")
+
+		res, err := db.Collection(collectionsCollection).DeleteOne(sessCtx, bson.D{{"_id", id}})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.DeletedCount == 0 {
+			return nil, ErrNotFound
+		}
+
+		_, err = db.Collection(collectionItemsCollection).DeleteMany(sessCtx, bson.D{{"collectionId", id}})
+
+		return nil, err
+	})
+
+	if err == ErrNotFound {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (r *RepositoryImpl) gameExists(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	count, err := r.mongoDbClient.Database("test").Collection(gamesCollection).CountDocuments(ctx, bson.D{{"_id", id}, {"isDeleted", false}})
+
+	if err != nil {
+		log.Println(err)
+		return false, UnknownError
+	}
+
+	return count > 0, nil
+}
+
+func (r *RepositoryImpl) getGamesByIds(ctx context.Context, ids []primitive.ObjectID) ([]games.Game, error) {
+	var found []games.Game
+
+	filter := bson.D{{"_id", bson.D{{"$in", ids}}}, {"isDeleted", false}}
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &found)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return found, nil
+}
+
+// addCollectionItem saves the item at the next position of its collection and
+// counts it in the collection.
+func (r *RepositoryImpl) addCollectionItem(ctx context.Context, item *CollectionItem) error {
+	session, err := r.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := r.mongoDbClient.Database("test")
+
+		var collection Collection
+
+		update := bson.D{
+			{"$inc", bson.D{{"itemCount", 1}, {"nextPosition", 1}}},
+			{"$set", bson.D{{"updatedAt", item.AddedAt}}},
+		}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.Before)
+
+		err := db.Collection(collectionsCollection).FindOneAndUpdate(sessCtx, bson.D{{"_id", item.CollectionId}}, update, opts).Decode(&collection)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		item.Position = collection.NextPosition
+
+		res, err := db.Collection(collectionItemsCollection).InsertOne(sessCtx, item)
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrGameAlreadyInCollection
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		item.Id = res.InsertedID.(primitive.ObjectID)
+
+		return nil, nil
+	})
+
+	if err == ErrNotFound || err == ErrGameAlreadyInCollection {
+		return err
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (r *RepositoryImpl) getCollectionItem(ctx context.Context, collectionId primitive.ObjectID, gameId primitive.ObjectID) (*CollectionItem, error) {
+	var item CollectionItem
+
+	filter := bson.D{{"collectionId", collectionId}, {"gameId", gameId}}
+
+	err := r.mongoDbClient.Database("test").Collection(collectionItemsCollection).FindOne(ctx, filter).Decode(&item)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrGameNotInCollection
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &item, nil
+}
+
+func (r *RepositoryImpl) updateCollectionItem(ctx context.Context, item *CollectionItem) error {
+	update := bson.D{{"$set", bson.D{
+		{"status", item.Status},
+		{"notes", item.Notes},
+		{"updatedAt", item.UpdatedAt},
+	}}}
+
+	// an empty status is not stored, like on insert
+	if item.Status == "" {
+		update = bson.D{
+			{"$set", bson.D{{"notes", item.Notes}, {"updatedAt", item.UpdatedAt}}},
+			{"$unset", bson.D{{"status", ""}}},
+		}
+	}
+
+	res, err := r.mongoDbClient.Database("test").Collection(collectionItemsCollection).UpdateOne(ctx, bson.D{{"_id", item.Id}}, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrGameNotInCollection
+	}
+
+	return nil
+}
+
+// removeCollectionItem deletes the item and uncounts it from its collection.
+func (r *RepositoryImpl) removeCollectionItem(ctx context.Context, collectionId primitive.ObjectID, gameId primitive.ObjectID) error {
+	session, err := r.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := r.mongoDbClient.Database("test")
+
+		res, err := db.Collection(collectionItemsCollection).DeleteOne(sessCtx, bson.D{{"collectionId", collectionId}, {"gameId", gameId}})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.DeletedCount == 0 {
+			return nil, ErrGameNotInCollection
+		}
+
+		update := bson.D{
+			{"$inc", bson.D{{"itemCount", -1}}},
+			{"$set", bson.D{{"updatedAt", time.Now()}}},
+		}
+
+		_, err = db.Collection(collectionsCollection).UpdateOne(sessCtx, bson.D{{"_id", collectionId}}, update)
+
+		return nil, err
+	})
+
+	if err == ErrGameNotInCollection {
+		return err
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (r *RepositoryImpl) getCollectionItems(ctx context.Context, collectionId primitive.ObjectID, status GameStatus, pagination *Pagination) (*PaginatedResponse[CollectionItem], error) {
+	var items []CollectionItem
+
+	filter := bson.D{{"collectionId", collectionId}}
+
+	if status != "" {
+		filter = append(filter, bson.E{"status", status})
+	}
+
+	opts := options.Find().SetSort(bson.D{{"position", 1}}).SetLimit(int64(pagination.Limit)).SetSkip(int64(pagination.Offset))
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(collectionItemsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &items)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if len(items) == 0 {
+		return &PaginatedResponse[CollectionItem]{
+			TotalItems:   0,
+			TotalPages:   0,
+			CurrentPage:  0,
+			ItemsPerPage: 0,
+			HasMore:      false,
+			Data:         []CollectionItem{},
+		}, nil
+	}
+
+	count, err := r.mongoDbClient.Database("test").Collection(collectionItemsCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[CollectionItem]{
+		Data:         items,
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		TotalItems:   int(count),
+		HasMore:      int(count) > (pagination.Offset + pagination.Limit),
+		ItemsPerPage: pagination.Limit,
+	}, nil
+}
+
+func (r *RepositoryImpl) getCollectionGameIds(ctx context.Context, collectionId primitive.ObjectID) ([]primitive.ObjectID, error) {
+	var items []CollectionItem
+
+	opts := options.Find().SetProjection(bson.D{{"gameId", 1}})
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(collectionItemsCollection).Find(ctx, bson.D{{"collectionId", collectionId}}, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &items); err != nil {
+		return nil, UnknownError
+	}
+
+	gameIds := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		gameIds = append(gameIds, item.GameId)
+	}
+
+	return gameIds, nil
+}
+
+// reorderCollectionItems numbers the items in the given order, starting at 1.
+func (r *RepositoryImpl) reorderCollectionItems(ctx context.Context, collectionId primitive.ObjectID, gameIds []primitive.ObjectID) error {
+	session, err := r.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := r.mongoDbClient.Database("test")
+		now := time.Now()
+
+		if len(gameIds) > 0 {
+			models := make([]mongo.WriteModel, 0, len(gameIds))
+
+			for i, gameId := range gameIds {
+				models = append(models, mongo.NewUpdateOneModel().
+					SetFilter(bson.D{{"collectionId", collectionId}, {"gameId", gameId}}).
+					SetUpdate(bson.D{{"$set", bson.D{{"position", i + 1}}}}))
+			}
+
+			if _, err := db.Collection(collectionItemsCollection).BulkWrite(sessCtx, models); err != nil {
+				return nil, err
+			}
+		}
+
+		update := bson.D{{"$set", bson.D{{"nextPosition", len(gameIds) + 1}, {"updatedAt", now}}}}
+
+		_, err := db.Collection(collectionsCollection).UpdateOne(sessCtx, bson.D{{"_id", collectionId}}, update)
+
+		return nil, err
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package collections
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	auth "go-server/pkg/authentication"
+)
+
+func router(ctx context.Context, app fiber.Router, handler *Handler, middleware auth.Middleware) error {
+
+	apiVersion := ctx.Value("apiVersion").(string)
+	app = app.Group(apiVersion + "/collections")
+
+	app.Use(middleware.AuthMiddleware(allowAllAuthenticated))
+
+	app.Post("/add", HandleAddCollection(handler, ctx))
+
+	app.Get("/mine", HandleGetMyCollections(handler, ctx))
+
+	app.Get("/public", HandleGetPublicCollections(handler, ctx))
+
+	app.Get("/:slug/items", HandleGetCollectionItems(handler, ctx))
+
+	app.Post("/:slug/items", HandleAddCollectionItem(handler, ctx))
+
+	app.Put("/:slug/items/order", HandleReorderCollection(handler, ctx))
+
+	app.Put("/:slug/items/:gameId", HandleUpdateCollectionItem(handler, ctx))
+
+	app.Delete("/:slug/items/:gameId", HandleRemoveCollectionItem(handler, ctx))
+
+	app.Get("/:slug", HandleGetCollection(handler, ctx))
+
+	app.Put("/:slug", HandleUpdateCollection(handler, ctx))
+
+	app.Delete("/:slug", HandleDeleteCollection(handler, ctx))
+
+	return nil
+}
+
+func allowAllAuthenticated(claims *auth.JwtClaims) (string, bool) {
+	return "", true
+}
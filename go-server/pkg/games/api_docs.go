@@ -11,7 +11,7 @@ import (
 // @Security BearerAuth
 //
 //	@Summary		Adds a new game genre
-//	@Description	Adds a new game genre, the slug is generated from the title, and it must be unique
+//	@Description	Adds a new game genre, the slug is generated from the title, and it must be unique. The optional parent makes it a subgenre
 //	@Tags			games
 //	@ID				addGenre
 //	@Accept			json
@@ -33,7 +33,7 @@ func HandleAddGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
 // @Security BearerAuth
 //
 //	@Summary		Updates a game genre
-//	@Description	Updates a game genre, the slug is required
+//	@Description	Updates a game genre, the slug is required. The slug cannot be changed here, use the rename endpoint
 //	@Tags			games
 //	@ID				updateGenre
 //	@Accept			json
@@ -46,7 +46,7 @@ func HandleAddGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
 //	@Router			/api/v1/games/genres/update [put]
 func HandleUpdateGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.EditGenre(ctx, c)
 	}
 }
@@ -57,19 +57,24 @@ func HandleUpdateGenre(handler *GameHandler, ctx context.Context) fiber.Handler
 //
 //	@Summary		Gets all game genres
 //	@Description	Gets all game genres, limits and offset can be used to paginate the results
+//	@Description	Responses carry an ETag and Last-Modified, conditional requests get 304 Not Modified. Texts are translated to the best match of Accept-Language.
 //	@Tags			games
 //	@ID				getGenres
 //	@Accept			json
 //	@Produce		json
 //
 //	@Param			getGenres	query		games.Pagination 	true			"getGenres request"
+//	@Param			Accept-Language	header		string 	false			"preferred locales"
+//	@Param			If-None-Match	header		string 	false			"ETag of a cached response"
+//	@Param			If-Modified-Since	header		string 	false			"Last-Modified of a cached response"
 //
 //	@Success		200				{object}	main.JSONResult{data=games.PaginatedResponse[GameGenre]}	"Success"
+//	@Success		304				"Not modified"
 //	@Router			/api/v1/games/genres [get]
 func HandleGetGenres(handler *GameHandler, ctx context.Context) fiber.Handler {
 	// set downstream context value
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.GetGenres(ctx, c)
 	}
 }
@@ -79,18 +84,23 @@ func HandleGetGenres(handler *GameHandler, ctx context.Context) fiber.Handler {
 // @Security BearerAuth
 //
 //	@Summary		Gets a game genre
-//	@Description	the slug is required
+//	@Description	the slug is required, slugs of renamed or merged genres resolve to the current genre
+//	@Description	Responses carry an ETag and Last-Modified, conditional requests get 304 Not Modified. Texts are translated to the best match of Accept-Language.
 //	@Tags			games
 //	@ID				getGenre
 //	@Produce		json
 //
 //	@Param			slug	path		string 	true			"slug"
+//	@Param			Accept-Language	header		string 	false			"preferred locales"
+//	@Param			If-None-Match	header		string 	false			"ETag of a cached response"
+//	@Param			If-Modified-Since	header		string 	false			"Last-Modified of a cached response"
 //
 //	@Success		200				{object}	main.JSONResult{data=games.GameGenre}	"Success"
+//	@Success		304				"Not modified"
 //	@Router			/api/v1/games/genres/{slug} [get]
 func HandleGetGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.GetGenre(ctx, c)
 	}
 }
@@ -112,17 +122,67 @@ func HandleGetGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
 //	@Router			/api/v1/games/genres/{slug} [delete]
 func HandleDeleteGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.DeleteGenre(ctx, c)
 	}
 }
 
+// HandleRenameGenre godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Renames a game genre
+//	@Description	the slug is regenerated from the new title, games carrying the genre are updated and the old slug redirects to the new one
+//	@Tags			games
+//	@ID				renameGenre
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			slug			path		string 						true			"slug"
+//	@Param			renameGenre		body		games.RenameGenreRequest 	true			"renameGenre request"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.GameGenre}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Genre not found"
+//	@Failure		409				{object}	main.JSONErrorRes					"Genre with the new slug already exists"
+//	@Router			/api/v1/games/genres/{slug}/rename [post]
+func HandleRenameGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.RenameGenre(ctx, c)
+	}
+}
+
+// HandleMergeGenre godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Merges a game genre into another
+//	@Description	games and subgenres of the genre move to the target genre, the genre is removed and its slug redirects to the target
+//	@Tags			games
+//	@ID				mergeGenre
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			slug			path		string 						true			"slug of the genre to merge"
+//	@Param			mergeGenre		body		games.MergeGenreRequest 	true			"mergeGenre request"
+//
+//	@Success		200				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Cannot merge into itself or a subgenre"
+//	@Failure		404				{object}	main.JSONErrorRes					"Genre not found"
+//	@Router			/api/v1/games/genres/{slug}/merge [post]
+func HandleMergeGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.MergeGenre(ctx, c)
+	}
+}
+
 // HandleAddGame godoc
 //
 // @Security BearerAuth
 //
 //	@Summary		Adds a new game
-//	@Description	Adds a new game genre
+//	@Description	Adds a new game, its slug comes from the title and gets the release year or a number appended while it is taken
 //	@Tags			games
 //	@ID				addGame
 //	@Accept			json
@@ -135,7 +195,7 @@ func HandleDeleteGenre(handler *GameHandler, ctx context.Context) fiber.Handler
 //	@Router			/api/v1/games/add [post]
 func HandleAddGame(handler *GameHandler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.AddGame(ctx, c)
 	}
 }
@@ -145,18 +205,25 @@ func HandleAddGame(handler *GameHandler, ctx context.Context) fiber.Handler {
 // @Security BearerAuth
 //
 //	@Summary		Gets a game
-//	@Description	the slug is required
+//	@Description	the game is looked up by id or by slug, old slugs of renamed games redirect to the current one with 301 Moved Permanently
+//	@Description	Responses carry an ETag and Last-Modified, conditional requests get 304 Not Modified. Texts are translated to the best match of Accept-Language.
 //	@Tags			games
 //	@ID				getGame
 //	@Produce		json
 //
-//	@Param			id	path		string 	true			"id"
+//	@Param			id	path		string 	true			"id or slug"
+//	@Param			Accept-Language	header		string 	false			"preferred locales"
+//	@Param			If-None-Match	header		string 	false			"ETag of a cached response"
+//	@Param			If-Modified-Since	header		string 	false			"Last-Modified of a cached response"
 //
 //	@Success		200				{object}	main.JSONResult{data=games.Game}	"Success"
+//	@Success		301				"Moved permanently"
+//	@Success		304				"Not modified"
+//	@Failure		403				{object}	main.JSONErrorRes					"Game is above the content settings of the user"
 //	@Router			/api/v1/games/{id} [get]
 func HandleGetGame(handler *GameHandler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.GetGame(ctx, c)
 	}
 }
@@ -166,20 +233,27 @@ func HandleGetGame(handler *GameHandler, ctx context.Context) fiber.Handler {
 // @Security BearerAuth
 //
 //	@Summary		Gets all games
-//	@Description	Gets all games, limits and offset can be used to paginate the results
+//	@Description	Gets all games, limits and offset can be used to paginate the results. Filtering by genre includes its subgenres.
+//	@Description	sortBy is one of newest (default), rating (Bayesian weighted rating), average, reviews (review count) or a sub-score: gameplay, story, graphics, audio or performance
+//	@Description	subScore keeps the games with reviews scoring it, averaging at least minSubScore when given
+//	@Description	Responses carry an ETag and Last-Modified, conditional requests get 304 Not Modified. Texts are translated to the best match of Accept-Language.
 //	@Tags			games
 //	@ID				getGames
 //	@Accept			json
 //	@Produce		json
 //
 //	@Param			getGames	query		games.GetGamesQueries 	true			"getGames request"
+//	@Param			Accept-Language	header		string 	false			"preferred locales"
+//	@Param			If-None-Match	header		string 	false			"ETag of a cached response"
+//	@Param			If-Modified-Since	header		string 	false			"Last-Modified of a cached response"
 //
 //	@Success		200				{object}	main.JSONResult{data=games.PaginatedResponse[Game]}	"Success"
+//	@Success		304				"Not modified"
 //	@Router			/api/v1/games [get]
 func HandleGetGames(handler *GameHandler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("HandleGetGames")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		log.Println("HandleGetGames 2")
 		return handler.GetGames(ctx, c)
 	}
@@ -190,7 +264,8 @@ func HandleGetGames(handler *GameHandler, ctx context.Context) fiber.Handler {
 // @Security BearerAuth
 //
 //	@Summary		Updates a game
-//	@Description	the id is required
+//	@Description	the id is required, fields that are left out keep their current value.
+//	@Description	Every update is recorded as a revision.
 //	@Tags			games
 //	@ID				updateGame
 //	@Accept			json
@@ -202,14 +277,35 @@ func HandleGetGames(handler *GameHandler, ctx context.Context) fiber.Handler {
 //
 //	@Success		200				{object}	main.JSONResult{data=string}	"Success"
 //	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Failure		409				{object}	main.JSONErrorRes					"Game was modified concurrently"
 //	@Router			/api/v1/games/{id} [put]
 func HandleUpdateGame(handler *GameHandler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.UpdateGame(ctx, c)
 	}
 }
 
+// HandleRecalculateRatings godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Rebuilds the rating stats of every game
+//	@Description	sums, counts, histograms and averages are recomputed from the reviews with the current rating prior,
+//	@Description	the games whose stats had drifted are returned. The same check runs every RATING_RECONCILE_INTERVAL_MINUTES
+//	@Tags			games
+//	@ID				recalculateRatings
+//	@Produce		json
+//
+//	@Success		200				{object}	main.JSONResult{data=games.RecalculateRatingsRes}	"Success"
+//	@Router			/api/v1/games/ratings/recalculate [post]
+func HandleRecalculateRatings(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.RecalculateRatings(ctx, c)
+	}
+}
+
 // HandleDeleteGame godoc
 //
 // @Security BearerAuth
@@ -227,11 +323,386 @@ func HandleUpdateGame(handler *GameHandler, ctx context.Context) fiber.Handler {
 //	@Router			/api/v1/games/{id} [delete]
 func HandleDeleteGame(handler *GameHandler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.DeleteGame(ctx, c)
 	}
 }
 
+// HandleAddFranchise godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Adds a new franchise
+//	@Description	Adds a new franchise, the slug is generated from the title, and it must be unique
+//	@Tags			games
+//	@ID				addFranchise
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			addFranchise	body		games.AddFranchiseRequest 	true			"addFranchise request"
+//
+//	@Success		201				{object}	main.JSONResult{data=games.AddFranchiseRes}	"Success"
+//	@Failure		409				{object}	main.JSONErrorRes					"Franchise with the same slug already exists"
+//	@Router			/api/v1/games/franchises/add [post]
+func HandleAddFranchise(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AddFranchise(ctx, c)
+	}
+}
+
+// HandleGetFranchiseTimeline godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets a franchise timeline
+//	@Description	Gets a franchise and its games ordered by release date
+//	@Tags			games
+//	@ID				getFranchiseTimeline
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.FranchiseTimeline}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes											"Franchise not found"
+//	@Router			/api/v1/games/franchises/{slug}/timeline [get]
+func HandleGetFranchiseTimeline(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetFranchiseTimeline(ctx, c)
+	}
+}
+
+// HandleDeleteFranchise godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Delete a franchise
+//	@Description	the slug is required, the franchise's part-of-franchise relations are removed too
+//	@Tags			games
+//	@ID				deleteFranchise
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes											"Franchise not found"
+//	@Router			/api/v1/games/franchises/{slug} [delete]
+func HandleDeleteFranchise(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DeleteFranchise(ctx, c)
+	}
+}
+
+// HandleGetRelatedGames godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the related titles of a game
+//	@Description	Gets sequels, remasters, DLCs and franchises linked to the game, in either direction
+//	@Tags			games
+//	@ID				getRelatedGames
+//	@Produce		json
+//
+//	@Param			id	path		string 	true			"id"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.GameRelations}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Router			/api/v1/games/{id}/related [get]
+func HandleGetRelatedGames(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetRelatedGames(ctx, c)
+	}
+}
+
+// HandleGetSimilarGames godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets games similar to a game
+//	@Description	Gets the "you might also like" games, precomputed from shared genres, developer, publisher and users who rated both highly
+//	@Tags			games
+//	@ID				getSimilarGames
+//	@Produce		json
+//
+//	@Param			id		path		string 	true			"id"
+//	@Param			limit	query		int 	false			"number of games, at most 20"
+//
+//	@Success		200				{object}	main.JSONResult{data=[]games.SimilarGame}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Router			/api/v1/games/{id}/similar [get]
+func HandleGetSimilarGames(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetSimilarGames(ctx, c)
+	}
+}
+
+// HandleGetRecommendedGames godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets games recommended to the user
+//	@Description	Gets games the user has not reviewed or voted on, ranked from the games similar players liked. Users with little history get the top rated games of their preferred genres
+//	@Tags			games
+//	@ID				getRecommendedGames
+//	@Produce		json
+//
+//	@Param			limit	query		int 	false			"number of games, at most 50"
+//
+//	@Success		200				{object}	main.JSONResult{data=[]games.RecommendedGame}	"Success"
+//	@Failure		401				{object}	main.JSONErrorRes					"Unauthorized"
+//	@Router			/api/v1/games/recommended [get]
+func HandleGetRecommendedGames(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetRecommendedGames(ctx, c)
+	}
+}
+
+// HandleEvaluateRecommendations godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Evaluates the recommendations offline
+//	@Description	Holds out the latest games each user liked, trains on the rest and reports precision@k and recall@k
+//	@Tags			games
+//	@ID				evaluateRecommendations
+//	@Produce		json
+//
+//	@Param			k	query		int 	false			"number of recommendations scored per user, 10 by default"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.RecommendationEvaluation}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Invalid k"
+//	@Router			/api/v1/games/recommendations/evaluate [get]
+func HandleEvaluateRecommendations(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.EvaluateRecommendations(ctx, c)
+	}
+}
+
+// HandleGetChart godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets a chart of games
+//	@Description	chart is trending, top-rated, most-reviewed or rising. The charts are recomputed on a schedule from the reviews and votes of the window, which defaults to week for trending and rising and month for most-reviewed
+//	@Tags			games
+//	@ID				getChart
+//	@Produce		json
+//
+//	@Param			chart		path		string 	true			"trending, top-rated, most-reviewed or rising"
+//	@Param			getChart	query		games.GetChartQueries 	false			"window, genre, platform and pagination"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.PaginatedResponse[games.ChartEntry]}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Invalid window"
+//	@Failure		404				{object}	main.JSONErrorRes					"Chart not found"
+//	@Router			/api/v1/games/charts/{chart} [get]
+func HandleGetChart(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetChart(ctx, c)
+	}
+}
+
+// HandleAddGameRelation godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Adds a relation to a game
+//	@Description	type is one of sequel-of, remaster-of, dlc-of and part-of-franchise.
+//	@Description	relatedGameId is required for game relations, franchiseSlug for part-of-franchise.
+//	@Tags			games
+//	@ID				addGameRelation
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			id				path		string							true			"id"
+//	@Param			addGameRelation	body		games.AddGameRelationRequest 	true			"addGameRelation request"
+//
+//	@Success		201				{object}	main.JSONResult{data=games.AddGameRelationRes}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Invalid game relation"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game or franchise not found"
+//	@Failure		409				{object}	main.JSONErrorRes					"Game relation already exists"
+//	@Router			/api/v1/games/{id}/relations [post]
+func HandleAddGameRelation(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AddGameRelation(ctx, c)
+	}
+}
+
+// HandleDeleteGameRelation godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Delete a game relation
+//	@Description	the game id and relation id are required
+//	@Tags			games
+//	@ID				deleteGameRelation
+//	@Produce		json
+//
+//	@Param			id			path		string 	true			"id"
+//	@Param			relationId	path		string 	true			"relation id"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes											"Game relation not found"
+//	@Router			/api/v1/games/{id}/relations/{relationId} [delete]
+func HandleDeleteGameRelation(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DeleteGameRelation(ctx, c)
+	}
+}
+
+// HandleAddGameMedia godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Adds media to a game's gallery
+//	@Description	Upload a cover or screenshot as the multipart "file" field, thumbnails and WebP variants are generated.
+//	@Description	Trailers are links, send kind=trailer with the "url" field instead of a file.
+//	@Description	Uploading a cover also sets it as the game image.
+//	@Tags			games
+//	@ID				addGameMedia
+//	@Accept			multipart/form-data
+//	@Produce		json
+//
+//	@Param			id		path		string	true	"id"
+//	@Param			kind	formData	string	true	"cover, screenshot or trailer"
+//	@Param			file	formData	file	false	"jpeg, png, gif or webp image"
+//	@Param			url		formData	string	false	"trailer link"
+//
+//	@Success		201				{object}	main.JSONResult{data=games.GameMedia}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Invalid media kind or trailer url"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Failure		413				{object}	main.JSONErrorRes					"Image is too large"
+//	@Failure		415				{object}	main.JSONErrorRes					"Unsupported image type"
+//	@Router			/api/v1/games/{id}/media [post]
+func HandleAddGameMedia(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AddGameMedia(ctx, c)
+	}
+}
+
+// HandleGetGameMedia godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets a game's media gallery
+//	@Description	the id is required
+//	@Tags			games
+//	@ID				getGameMedia
+//	@Produce		json
+//
+//	@Param			id	path		string 	true			"id"
+//
+//	@Success		200				{object}	main.JSONResult{data=[]games.GameMedia}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Router			/api/v1/games/{id}/media [get]
+func HandleGetGameMedia(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetGameMedia(ctx, c)
+	}
+}
+
+// HandleDeleteGameMedia godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Delete a game media item
+//	@Description	removes the media and its stored variants
+//	@Tags			games
+//	@ID				deleteGameMedia
+//	@Produce		json
+//
+//	@Param			id		path		string 	true			"id"
+//	@Param			mediaId	path		string 	true			"media id"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes											"Game media not found"
+//	@Router			/api/v1/games/{id}/media/{mediaId} [delete]
+func HandleDeleteGameMedia(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DeleteGameMedia(ctx, c)
+	}
+}
+
+// HandleServeMedia godoc
+//
+//	@Summary		Serves a stored game image
+//	@Description	public and cacheable, the urls are found in the game media variants
+//	@Tags			games
+//	@ID				serveMedia
+//	@Produce		image/jpeg,image/png,image/gif,image/webp
+//
+//	@Param			key	path		string 	true			"media key"
+//
+//	@Success		200
+//	@Success		304
+//	@Failure		404				{object}	main.JSONErrorRes					"Media not found"
+//	@Router			/api/v1/games/media/{key} [get]
+func HandleServeMedia(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return handler.ServeMedia(ctx, c)
+	}
+}
+
+// HandleGetGameRevisions godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the edit history of a game
+//	@Description	newest revision first, limits and offset can be used to paginate the results
+//	@Tags			games
+//	@ID				getGameRevisions
+//	@Produce		json
+//
+//	@Param			id			path		string				true			"id"
+//	@Param			pagination	query		games.Pagination 	false			"pagination"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.PaginatedResponse[GameRevision]}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Router			/api/v1/games/{id}/revisions [get]
+func HandleGetGameRevisions(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetGameRevisions(ctx, c)
+	}
+}
+
+// HandleRevertGameRevision godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Reverts a game revision
+//	@Description	restores the fields changed by the revision to their previous values, the revert is recorded as a new revision
+//	@Tags			games
+//	@ID				revertGameRevision
+//	@Produce		json
+//
+//	@Param			id			path		string 	true			"id"
+//	@Param			revision	path		int 	true			"revision number"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game or revision not found"
+//	@Failure		409				{object}	main.JSONErrorRes					"Game was modified concurrently"
+//	@Router			/api/v1/games/{id}/revisions/{revision}/revert [post]
+func HandleRevertGameRevision(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.RevertGameRevision(ctx, c)
+	}
+}
+
+// GetNewContext builds the context of a request on the context of the routes.
+// Every request needs its own, the content filter is kept in it.
 func GetNewContext(ctx context.Context, c *fiber.Ctx) context.Context {
 	userId := c.Locals("userId")
 	role := c.Locals("role")
@@ -240,5 +711,457 @@ func GetNewContext(ctx context.Context, c *fiber.Ctx) context.Context {
 
 	ctx = context.WithValue(ctx, "userId", userId)
 	ctx = context.WithValue(ctx, "role", role)
+	ctx = context.WithValue(ctx, "contentFilter", &requestContentFilter{})
 	return ctx
 }
+
+// HandleGetTrashedGames godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the deleted games
+//	@Description	most recently deleted first, games are purged once they have been in the trash longer than TRASH_RETENTION_DAYS
+//	@Tags			games
+//	@ID				getTrashedGames
+//	@Produce		json
+//
+//	@Param			pagination	query		games.Pagination 	false			"pagination"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.PaginatedResponse[Game]}	"Success"
+//	@Router			/api/v1/games/trash/games [get]
+func HandleGetTrashedGames(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetTrash(ctx, c, false)
+	}
+}
+
+// HandleGetTrashedGenres godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the deleted game genres
+//	@Description	most recently deleted first, genres are purged once they have been in the trash longer than TRASH_RETENTION_DAYS
+//	@Tags			games
+//	@ID				getTrashedGenres
+//	@Produce		json
+//
+//	@Param			pagination	query		games.Pagination 	false			"pagination"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.PaginatedResponse[GameGenre]}	"Success"
+//	@Router			/api/v1/games/trash/genres [get]
+func HandleGetTrashedGenres(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetTrash(ctx, c, true)
+	}
+}
+
+// HandleRestoreGame godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Restores a deleted game
+//	@Description	the game's reviews become visible again
+//	@Tags			games
+//	@ID				restoreGame
+//	@Produce		json
+//
+//	@Param			id	path		string 	true			"id"
+//
+//	@Success		200				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Failure		409				{object}	main.JSONErrorRes					"Game is not in the trash"
+//	@Router			/api/v1/games/{id}/restore [post]
+func HandleRestoreGame(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.RestoreGame(ctx, c)
+	}
+}
+
+// HandlePurgeGame godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Permanently removes a deleted game
+//	@Description	also removes its reviews and their votes, relations, revisions and media
+//	@Tags			games
+//	@ID				purgeGame
+//	@Produce		json
+//
+//	@Param			id	path		string 	true			"id"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Failure		409				{object}	main.JSONErrorRes					"Game is not in the trash"
+//	@Router			/api/v1/games/{id}/purge [delete]
+func HandlePurgeGame(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.PurgeGame(ctx, c)
+	}
+}
+
+// HandleRestoreGenre godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Restores a deleted game genre
+//	@Description	fails if a genre with the same slug has been added since
+//	@Tags			games
+//	@ID				restoreGenre
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//
+//	@Success		200				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game genre not found in the trash"
+//	@Failure		409				{object}	main.JSONErrorRes					"A game genre with this slug already exists"
+//	@Router			/api/v1/games/genres/{slug}/restore [post]
+func HandleRestoreGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.RestoreGenre(ctx, c)
+	}
+}
+
+// HandlePurgeGenre godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Permanently removes a deleted game genre
+//	@Description	the genre is also removed from the games that still have it
+//	@Tags			games
+//	@ID				purgeGenre
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game genre not found in the trash"
+//	@Router			/api/v1/games/genres/{slug}/purge [delete]
+func HandlePurgeGenre(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.PurgeGenre(ctx, c)
+	}
+}
+
+// HandleGetGameByExternalId godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets a game by its id at a catalog provider
+//	@Description	providers are configured with EXTERNAL_ID_PROVIDERS
+//	@Tags			games
+//	@ID				getGameByExternalId
+//	@Produce		json
+//
+//	@Param			provider	path		string 	true			"provider, e.g. igdb"
+//	@Param			externalId	path		string 	true			"id of the game at the provider"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.Game}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Unknown external id provider"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Router			/api/v1/games/external/{provider}/{externalId} [get]
+func HandleGetGameByExternalId(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetGameByExternalId(ctx, c)
+	}
+}
+
+// HandleUpsertGameByExternalId godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Adds or updates a game by its id at a catalog provider
+//	@Description	idempotent, a game sharing one of the external ids, or the title and release year, is updated and gets the missing external ids.
+//	@Description	Otherwise the game is added and the games looking alike are flagged as likely duplicates.
+//	@Tags			games
+//	@ID				upsertGameByExternalId
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			provider	path		string 	true			"provider, e.g. igdb"
+//	@Param			externalId	path		string 	true			"id of the game at the provider"
+//	@Param			upsertGame	body		games.AddGameRequest 	true			"upsertGame request"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.UpsertGameRes}	"Updated"
+//	@Success		201				{object}	main.JSONResult{data=games.UpsertGameRes}	"Added"
+//	@Failure		400				{object}	main.JSONErrorRes					"Unknown external id provider"
+//	@Failure		409				{object}	main.JSONErrorRes					"External ids match different games"
+//	@Router			/api/v1/games/external/{provider}/{externalId} [put]
+func HandleUpsertGameByExternalId(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.UpsertGameByExternalId(ctx, c)
+	}
+}
+
+// HandleGetDuplicateFlags godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the games flagged as likely duplicates
+//	@Description	most likely first, a new game is flagged against the games with a similar title, the same developer or a close release year
+//	@Tags			games
+//	@ID				getDuplicateFlags
+//	@Produce		json
+//
+//	@Param			getDuplicateFlags	query		games.GetDuplicateFlagsQueries 	true			"getDuplicateFlags request"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.PaginatedResponse[DuplicateFlag]}	"Success"
+//	@Router			/api/v1/games/duplicates/flags [get]
+func HandleGetDuplicateFlags(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetDuplicateFlags(ctx, c)
+	}
+}
+
+// HandleReviewDuplicateFlag godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Reviews a duplicate flag
+//	@Description	confirmed when the games are the same, dismissed when they are not. A dismissed pair is not flagged again.
+//	@Tags			games
+//	@ID				reviewDuplicateFlag
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			flagId	path		string 	true			"flag id"
+//	@Param			reviewDuplicateFlag	body		games.ReviewDuplicateFlagRequest 	true			"reviewDuplicateFlag request"
+//
+//	@Success		200				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Duplicate flag not found"
+//	@Router			/api/v1/games/duplicates/flags/{flagId} [put]
+func HandleReviewDuplicateFlag(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.ReviewDuplicateFlag(ctx, c)
+	}
+}
+
+// HandleGetTranslationReport godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Counts the missing translations per locale
+//	@Description	for each supported locale but the default one, how many games lack a title or summary and how many genres lack a description in it
+//	@Tags			games
+//	@ID				getTranslationReport
+//	@Produce		json
+//
+//	@Success		200				{object}	main.JSONResult{data=games.TranslationReport}	"Success"
+//	@Router			/api/v1/games/translations/report [get]
+func HandleGetTranslationReport(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetTranslationReport(ctx, c)
+	}
+}
+
+// HandleGetMissingTranslations godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Lists the games or genres missing translations
+//	@Description	each item lists the locales it is not fully translated to, games with the most reviews first
+//	@Tags			games
+//	@ID				getMissingTranslations
+//	@Produce		json
+//
+//	@Param			getMissingTranslations	query		games.GetMissingTranslationsQueries 	true			"getMissingTranslations request"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.PaginatedResponse[MissingTranslations]}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Unsupported locale"
+//	@Router			/api/v1/games/translations/missing [get]
+func HandleGetMissingTranslations(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetMissingTranslations(ctx, c)
+	}
+}
+
+// HandleGetGameTranslations godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the translations of a game
+//	@Tags			games
+//	@ID				getGameTranslations
+//	@Produce		json
+//
+//	@Param			id	path		string 	true			"id or slug"
+//
+//	@Success		200				{object}	main.JSONResult{data=map[string]games.GameTranslation}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Router			/api/v1/games/{id}/translations [get]
+func HandleGetGameTranslations(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetGameTranslations(ctx, c)
+	}
+}
+
+// HandleSetGameTranslation godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Sets the translation of a game to a locale
+//	@Description	replaces the translation, a field left empty is served in the default locale
+//	@Tags			games
+//	@ID				setGameTranslation
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			id	path		string 	true			"id or slug"
+//	@Param			locale	path		string 	true			"locale, e.g. pt-BR"
+//	@Param			setGameTranslation	body		games.GameTranslation 	true			"setGameTranslation request"
+//
+//	@Success		200				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Unsupported locale"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game not found"
+//	@Router			/api/v1/games/{id}/translations/{locale} [put]
+func HandleSetGameTranslation(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.SetGameTranslation(ctx, c)
+	}
+}
+
+// HandleDeleteGameTranslation godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Deletes the translation of a game to a locale
+//	@Tags			games
+//	@ID				deleteGameTranslation
+//	@Produce		json
+//
+//	@Param			id	path		string 	true			"id or slug"
+//	@Param			locale	path		string 	true			"locale, e.g. pt-BR"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Translation not found"
+//	@Router			/api/v1/games/{id}/translations/{locale} [delete]
+func HandleDeleteGameTranslation(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DeleteGameTranslation(ctx, c)
+	}
+}
+
+// HandleGetGenreTranslations godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the translations of a game genre
+//	@Tags			games
+//	@ID				getGenreTranslations
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//
+//	@Success		200				{object}	main.JSONResult{data=map[string]games.GenreTranslation}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game genre not found"
+//	@Router			/api/v1/games/genres/{slug}/translations [get]
+func HandleGetGenreTranslations(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetGenreTranslations(ctx, c)
+	}
+}
+
+// HandleSetGenreTranslation godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Sets the description of a game genre in a locale
+//	@Tags			games
+//	@ID				setGenreTranslation
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//	@Param			locale	path		string 	true			"locale, e.g. pt-BR"
+//	@Param			setGenreTranslation	body		games.GenreTranslation 	true			"setGenreTranslation request"
+//
+//	@Success		200				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Unsupported locale"
+//	@Failure		404				{object}	main.JSONErrorRes					"Game genre not found"
+//	@Router			/api/v1/games/genres/{slug}/translations/{locale} [put]
+func HandleSetGenreTranslation(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.SetGenreTranslation(ctx, c)
+	}
+}
+
+// HandleDeleteGenreTranslation godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Deletes the description of a game genre in a locale
+//	@Tags			games
+//	@ID				deleteGenreTranslation
+//	@Produce		json
+//
+//	@Param			slug	path		string 	true			"slug"
+//	@Param			locale	path		string 	true			"locale, e.g. pt-BR"
+//
+//	@Success		202				{object}	main.JSONResult{data=string}	"Success"
+//	@Failure		404				{object}	main.JSONErrorRes					"Translation not found"
+//	@Router			/api/v1/games/genres/{slug}/translations/{locale} [delete]
+func HandleDeleteGenreTranslation(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DeleteGenreTranslation(ctx, c)
+	}
+}
+
+// HandleGetContentPreferences godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Gets the content settings of the user
+//	@Description	games rated above the age of the user, or their max age, or showing one of their hidden descriptors are left out of the game listings and reviews, or marked restricted in blur mode
+//	@Tags			games
+//	@ID				getContentPreferences
+//	@Produce		json
+//
+//	@Success		200				{object}	main.JSONResult{data=games.ContentPreferences}	"Success"
+//	@Router			/api/v1/games/content/preferences [get]
+func HandleGetContentPreferences(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetContentPreferences(ctx, c)
+	}
+}
+
+// HandleSetContentPreferences godoc
+//
+// @Security BearerAuth
+//
+//	@Summary		Sets the content settings of the user
+//	@Description	the birthdate can't be changed once set, users under 18 always get the games above their age hidden. Descriptors are violence, blood, gore, sexual-content, nudity, strong-language, crude-humor, drugs, alcohol, tobacco, gambling, fear, discrimination, in-game-purchases and online-interactions
+//	@Tags			games
+//	@ID				setContentPreferences
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			setContentPreferences	body		games.SetContentPreferencesRequest 	true			"setContentPreferences request"
+//
+//	@Success		200				{object}	main.JSONResult{data=games.ContentPreferences}	"Success"
+//	@Failure		400				{object}	main.JSONErrorRes					"Invalid content preferences"
+//	@Failure		409				{object}	main.JSONErrorRes					"Birthdate already set"
+//	@Router			/api/v1/games/content/preferences [put]
+func HandleSetContentPreferences(handler *GameHandler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.SetContentPreferences(ctx, c)
+	}
+}
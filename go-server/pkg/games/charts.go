@@ -0,0 +1,193 @@
+package games
+
+import (
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ChartsIntervalMinutes is how often the charts are recomputed.
+	ChartsIntervalMinutes = "CHARTS_INTERVAL_MINUTES"
+
+	defaultChartsIntervalMinutes = 30
+
+	// how many games each chart keeps
+	chartSize = 100
+	// weight of a net upvote on a review against a review of the game
+	chartVoteWeight = 0.5
+)
+
+func getChartsInterval() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(ChartsIntervalMinutes))
+
+	if err != nil || minutes < 1 {
+		minutes = defaultChartsIntervalMinutes
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// TimeWindow is a period of activity counted back from now. The reviews
+// locations share it.
+type TimeWindow int
+
+const (
+	Day TimeWindow = iota
+	Week
+	Month
+)
+
+var timeWindows = []TimeWindow{Day, Week, Month}
+
+// ParseTimeWindow parses "day", "week" or "month".
+func ParseTimeWindow(value string) (TimeWindow, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "day":
+		return Day, true
+	case "week":
+		return Week, true
+	case "month":
+		return Month, true
+	default:
+		return Day, false
+	}
+}
+
+func (w TimeWindow) String() string {
+	switch w {
+	case Week:
+		return "week"
+	case Month:
+		return "month"
+	default:
+		return "day"
+	}
+}
+
+// Since returns the start of the window spanning value days, weeks or months up
+// to now, a value of 0 counting as 1.
+func (w TimeWindow) Since(now time.Time, value int) time.Time {
+	if value == 0 {
+		value = 1
+	}
+
+	switch w {
+	case Week:
+		return now.AddDate(0, 0, -value*7)
+	case Month:
+		return now.AddDate(0, -value, 0)
+	default:
+		return now.AddDate(0, 0, -value)
+	}
+}
+
+type ChartKind string
+
+const (
+	// ChartTrending ranks games on the reviews and votes they got in the window.
+	ChartTrending ChartKind = "trending"
+	// ChartTopRated ranks games on their all time weighted rating.
+	ChartTopRated ChartKind = "top-rated"
+	// ChartMostReviewed ranks games on how many reviews they got in the window.
+	ChartMostReviewed ChartKind = "most-reviewed"
+	// ChartRising ranks games on how much more activity they got in the window
+	// than in the window before.
+	ChartRising ChartKind = "rising"
+
+	// chartAllTime is the window of the charts that are not windowed
+	chartAllTime = "all"
+)
+
+// chartDefaultWindows are the windows the charts are listed for when none is
+// asked, "trending this week", "most reviewed this month" and "rising this week".
+var chartDefaultWindows = map[ChartKind]TimeWindow{
+	ChartTrending:     Week,
+	ChartMostReviewed: Month,
+	ChartRising:       Week,
+}
+
+func (c ChartKind) isValid() bool {
+	return c == ChartTopRated || c.isWindowed()
+}
+
+func (c ChartKind) isWindowed() bool {
+	_, ok := chartDefaultWindows[c]
+	return ok
+}
+
+// GameActivity is what a game got in a window, its reviews and the votes on them.
+type GameActivity struct {
+	Reviews   int `json:"reviews" bson:"reviews"`
+	RatingSum int `json:"ratingSum" bson:"ratingSum"`
+	UpVotes   int `json:"upVotes" bson:"upVotes"`
+	DownVotes int `json:"downVotes" bson:"downVotes"`
+}
+
+// trendingScore counts the reviews in proportion to their rating, a 5 star
+// review counting as 1, plus the net votes.
+func (a GameActivity) trendingScore() float64 {
+	return float64(a.RatingSum)/MaxRating + chartVoteWeight*float64(a.UpVotes-a.DownVotes)
+}
+
+// risingScore is the growth of the trending score over the previous window,
+// relative to the square root of the previous score so established games need
+// a bigger jump than new ones.
+func risingScore(current GameActivity, previous GameActivity) float64 {
+	before := math.Max(previous.trendingScore(), 0)
+
+	return (current.trendingScore() - before) / math.Sqrt(before+1)
+}
+
+// rankChart keeps the best chartSize games with a positive score and ranks them.
+func rankChart(chart ChartKind, window string, games []Game, score func(game *Game) (float64, GameActivity), computedAt time.Time) []ChartEntry {
+	entries := []ChartEntry{}
+
+	for i := range games {
+		game := &games[i]
+		value, activity := score(game)
+
+		if value <= 0 {
+			continue
+		}
+
+		genres := make([]string, 0, len(game.Genres))
+		for _, genre := range game.Genres {
+			if genre != nil {
+				genres = append(genres, genre.Slug)
+			}
+		}
+
+		entries = append(entries, ChartEntry{
+			Chart:      chart,
+			Window:     window,
+			GameId:     game.Id,
+			Score:      value,
+			Activity:   activity,
+			Genres:     genres,
+			Platforms:  game.Platforms,
+			ComputedAt: computedAt,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+
+		return entries[i].GameId.Hex() < entries[j].GameId.Hex()
+	})
+
+	if len(entries) > chartSize {
+		entries = entries[:chartSize]
+	}
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	return entries
+}
@@ -0,0 +1,397 @@
+package games
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// age from which no game is restricted for its rating, the one of ESRB AO and PEGI 18
+const adultAge = 18
+
+// minimum ages of the ESRB categories, a pending rating restricts nobody
+var esrbMinimumAges = map[EsrbRating]int{
+	EsrbEarlyChildhood: 3,
+	EsrbEveryone:       6,
+	EsrbEveryone10:     10,
+	EsrbTeen:           13,
+	EsrbMature:         17,
+	EsrbAdultsOnly:     18,
+	EsrbRatingPending:  0,
+}
+
+var pegiAges = map[int]bool{3: true, 7: true, 12: true, 16: true, 18: true}
+
+// contentDescriptors are the descriptors games can carry, merging the ESRB and
+// PEGI ones that users tell apart.
+var contentDescriptors = map[ContentDescriptor]bool{
+	"violence":            true,
+	"blood":               true,
+	"gore":                true,
+	"sexual-content":      true,
+	"nudity":              true,
+	"strong-language":     true,
+	"crude-humor":         true,
+	"drugs":               true,
+	"alcohol":             true,
+	"tobacco":             true,
+	"gambling":            true,
+	"fear":                true,
+	"discrimination":      true,
+	"in-game-purchases":   true,
+	"online-interactions": true,
+}
+
+func (r *AgeRating) isValid() bool {
+	if r == nil {
+		return true
+	}
+
+	esrb := EsrbRating(strings.ToUpper(strings.TrimSpace(string(r.Esrb))))
+
+	if _, ok := esrbMinimumAges[esrb]; esrb != "" && !ok {
+		return false
+	}
+
+	return r.Pegi == 0 || pegiAges[r.Pegi]
+}
+
+// normalize upper cases the ESRB category, an empty rating gives nil.
+func (r *AgeRating) normalize() *AgeRating {
+	if r == nil {
+		return nil
+	}
+
+	normalized := &AgeRating{Esrb: EsrbRating(strings.ToUpper(strings.TrimSpace(string(r.Esrb)))), Pegi: r.Pegi}
+
+	if normalized.Esrb == "" && normalized.Pegi == 0 {
+		return nil
+	}
+
+	return normalized
+}
+
+// minimumAge is the highest of the ages of the two ratings, 0 when unrated.
+func (r *AgeRating) minimumAge() int {
+	if r == nil {
+		return 0
+	}
+
+	return max(esrbMinimumAges[r.Esrb], r.Pegi)
+}
+
+func sameAgeRating(a *AgeRating, b *AgeRating) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+func validContentDescriptors(descriptors []ContentDescriptor) bool {
+	for _, descriptor := range descriptors {
+		if !contentDescriptors[ContentDescriptor(strings.ToLower(strings.TrimSpace(string(descriptor))))] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizeContentDescriptors lower cases the descriptors, drops duplicates and
+// sorts them so they compare and cache the same whatever their order.
+func normalizeContentDescriptors(descriptors []ContentDescriptor) []ContentDescriptor {
+	normalized := []ContentDescriptor{}
+	seen := make(map[ContentDescriptor]bool)
+
+	for _, descriptor := range descriptors {
+		descriptor = ContentDescriptor(strings.ToLower(strings.TrimSpace(string(descriptor))))
+
+		if descriptor == "" || seen[descriptor] {
+			continue
+		}
+
+		seen[descriptor] = true
+		normalized = append(normalized, descriptor)
+	}
+
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i] < normalized[j] })
+
+	return normalized
+}
+
+func sameContentDescriptors(a []ContentDescriptor, b []ContentDescriptor) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ageOn is how old someone born on birthdate is on the given day.
+func ageOn(birthdate time.Time, day time.Time) int {
+	age := day.Year() - birthdate.Year()
+
+	if day.Month() < birthdate.Month() || (day.Month() == birthdate.Month() && day.Day() < birthdate.Day()) {
+		age--
+	}
+
+	return age
+}
+
+// ContentFilter restricts the games rated for players older than MaxAge and the
+// ones showing any of Descriptors. Restricted games are left out of the reads,
+// or marked restricted when Blur is set. A nil filter restricts nothing.
+type ContentFilter struct {
+	MaxAge      int
+	Descriptors []ContentDescriptor
+	Blur        bool
+}
+
+// contentFilter returns the filter of the content settings of the user of ctx,
+// nil when they have none. It is loaded once per request.
+func (g *Service) contentFilter(ctx context.Context) (*ContentFilter, error) {
+	if memo, ok := ctx.Value("contentFilter").(*requestContentFilter); ok && memo != nil {
+		memo.once.Do(func() {
+			memo.filter, memo.err = g.loadContentFilter(ctx)
+		})
+
+		return memo.filter, memo.err
+	}
+
+	return g.loadContentFilter(ctx)
+}
+
+// requestContentFilter keeps the content filter of a request, the cache key and
+// the reads of the request need it.
+type requestContentFilter struct {
+	once   sync.Once
+	filter *ContentFilter
+	err    error
+}
+
+func (g *Service) loadContentFilter(ctx context.Context) (*ContentFilter, error) {
+	userId, _ := ctx.Value("userId").(string)
+
+	if userId == "" {
+		return nil, nil
+	}
+
+	preferences, err := g.repository.getContentPreferences(ctx, userId)
+
+	if err == ErrNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return preferences.filter(time.Now()), nil
+}
+
+// filter turns the preferences into the filter of the reads on the given day.
+func (p *ContentPreferences) filter(day time.Time) *ContentFilter {
+	maxAge := adultAge
+	minor := false
+
+	if p.MaxAge != nil && *p.MaxAge < maxAge {
+		maxAge = *p.MaxAge
+	}
+
+	if p.Birthdate != nil {
+		if age := ageOn(*p.Birthdate, day); age < adultAge {
+			maxAge = min(maxAge, age)
+			minor = true
+		}
+	}
+
+	if maxAge >= adultAge && len(p.HiddenDescriptors) == 0 {
+		return nil
+	}
+
+	return &ContentFilter{
+		MaxAge:      maxAge,
+		Descriptors: p.HiddenDescriptors,
+		Blur:        p.Mode == ContentBlur && !minor,
+	}
+}
+
+func (f *ContentFilter) access(game *Game) ContentAccess {
+	if f == nil {
+		return ContentVisible
+	}
+
+	restricted := game.MinimumAge > f.MaxAge
+
+	for _, descriptor := range game.ContentDescriptors {
+		for _, hidden := range f.Descriptors {
+			if descriptor == hidden {
+				restricted = true
+			}
+		}
+	}
+
+	if !restricted {
+		return ContentVisible
+	}
+
+	if f.Blur {
+		return ContentBlurred
+	}
+
+	return ContentHidden
+}
+
+// apply marks the game restricted when it is to be blurred, and tells if it
+// can be shown at all.
+func (f *ContentFilter) apply(game *Game) bool {
+	switch f.access(game) {
+	case ContentHidden:
+		return false
+	case ContentBlurred:
+		game.Restricted = true
+	}
+
+	return true
+}
+
+// cacheKey tells apart the cached responses of users with different settings,
+// it is empty for users with none.
+func (f *ContentFilter) cacheKey() string {
+	if f == nil {
+		return ""
+	}
+
+	key := ";age=" + strconv.Itoa(f.MaxAge)
+
+	if len(f.Descriptors) > 0 {
+		descriptors := make([]string, len(f.Descriptors))
+
+		for i, descriptor := range f.Descriptors {
+			descriptors[i] = string(descriptor)
+		}
+
+		key += ";hide=" + strings.Join(descriptors, ",")
+	}
+
+	if f.Blur {
+		key += ";blur"
+	}
+
+	return key
+}
+
+func (g *Service) GetContentPreferences(ctx context.Context) (*ContentPreferences, error) {
+
+	userId, _ := ctx.Value("userId").(string)
+
+	if userId == "" {
+		return nil, ErrUnauthorized
+	}
+
+	preferences, err := g.repository.getContentPreferences(ctx, userId)
+
+	if err == ErrNotFound {
+		return &ContentPreferences{UserId: userId, HiddenDescriptors: []ContentDescriptor{}, Mode: ContentHide}, nil
+	}
+
+	return preferences, err
+}
+
+// SetContentPreferences replaces the content settings of the calling user. A
+// birthdate, once set, can't be changed.
+func (g *Service) SetContentPreferences(ctx context.Context, preferences *ContentPreferences) error {
+
+	current, err := g.GetContentPreferences(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if preferences.Mode == "" {
+		preferences.Mode = ContentHide
+	}
+
+	if !preferences.Mode.isValid() || !validContentDescriptors(preferences.HiddenDescriptors) {
+		return ErrBadRequest
+	}
+
+	if preferences.MaxAge != nil && (*preferences.MaxAge < 0 || *preferences.MaxAge > adultAge) {
+		return ErrBadRequest
+	}
+
+	now := time.Now()
+
+	if preferences.Birthdate != nil {
+		birthdate := preferences.Birthdate.UTC().Truncate(24 * time.Hour)
+
+		if birthdate.After(now) || ageOn(birthdate, now) > 130 {
+			return ErrBadRequest
+		}
+
+		preferences.Birthdate = &birthdate
+	}
+
+	if current.Birthdate != nil {
+		if preferences.Birthdate != nil && !preferences.Birthdate.Equal(*current.Birthdate) {
+			return ErrBirthdateAlreadySet
+		}
+
+		preferences.Birthdate = current.Birthdate
+	}
+
+	preferences.UserId = current.UserId
+	preferences.HiddenDescriptors = normalizeContentDescriptors(preferences.HiddenDescriptors)
+	preferences.UpdatedAt = now
+
+	return g.repository.saveContentPreferences(ctx, preferences)
+}
+
+// GetContentAccess tells, for each of the games, how the calling user gets it
+// shown given their content settings. Games that can't be found are visible, the
+// reads of other packages report them missing on their own.
+func (g *Service) GetContentAccess(ctx context.Context, gameIds []string) (map[string]ContentAccess, error) {
+
+	access := make(map[string]ContentAccess, len(gameIds))
+
+	for _, id := range gameIds {
+		access[id] = ContentVisible
+	}
+
+	content, err := g.contentFilter(ctx)
+
+	if err != nil || content == nil || len(gameIds) == 0 {
+		return access, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(gameIds))
+
+	for _, id := range gameIds {
+		if objectId, err := primitive.ObjectIDFromHex(id); err == nil {
+			ids = append(ids, objectId)
+		}
+	}
+
+	games, err := g.repository.getGamesByIds(ctx, ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range games {
+		access[games[i].Id.Hex()] = content.access(&games[i])
+	}
+
+	return access, nil
+}
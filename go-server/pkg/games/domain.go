@@ -1,10 +1,18 @@
 package games
 
 import (
+	"bytes"
 	"context"
 	"github.com/go-playground/validator/v10"
+	"go-server/pkg/cache"
+	"go-server/pkg/storage"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
 	"log"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -12,10 +20,19 @@ import (
 type Service struct {
 	repository Repository
 	validate   *validator.Validate
+	storage    storage.BlobStorage
+	media      *MediaConfig
+	// how long deleted games and genres stay in the trash before they are purged
+	trashRetention time.Duration
+	ratingPrior    RatingPrior
+	cache          *catalogCache
+	// catalog providers games can carry an id of
+	externalIdProviders map[string]bool
+	locales             *LocaleConfig
 }
 
 type PaginatedResponseType interface {
-	GameGenre | Game
+	GameGenre | Game | GameRevision | ChartEntry | DuplicateFlag | MissingTranslations
 }
 
 type PaginatedResponse[V PaginatedResponseType] struct {
@@ -31,6 +48,10 @@ type Pagination struct {
 	Limit        int                    `json:"limit"`
 	Offset       int                    `json:"offset"`
 	QueryFilters map[string]interface{} `json:"filters"`
+	// one of the keys of gameSortKeys, games are listed newest first by default
+	SortBy string `json:"sortBy,omitempty"`
+	// content settings of the calling user the games must be within, if any
+	Content *ContentFilter `json:"-"`
 }
 
 type EmbeddedGameGenre struct {
@@ -38,29 +59,71 @@ type EmbeddedGameGenre struct {
 	Slug  string `json:"slug" bson:"slug"`
 }
 
+// RatingStats are the review totals of a game. Histogram counts the reviews per
+// star, keyed "1" to "5". Average and Weighted are derived from Sum and Count,
+// Weighted being the Bayesian average used to rank games, see RatingPrior.
 type RatingStats struct {
-	Sum   int `json:"sum" bson:"sum"`
-	Count int `json:"count" bson:"count"`
+	Sum       int            `json:"sum" bson:"sum"`
+	Count     int            `json:"count" bson:"count"`
+	Histogram map[string]int `json:"histogram" bson:"histogram"`
+	Average   float64        `json:"average" bson:"average"`
+	Weighted  float64        `json:"weighted" bson:"weighted"`
+	// when a review last changed the stats
+	UpdatedAt time.Time `json:"updatedAt,omitempty" bson:"updatedAt,omitempty"`
+	// totals of the reviews scoring an aspect of the game, by SubScoreDimensions
+	SubScores map[string]SubScoreStats `json:"subScores,omitempty" bson:"subScores,omitempty"`
 }
 
 type Game struct {
 	Title       string               `json:"title" bson:"title"`
+	Slug        string               `json:"slug" bson:"slug,omitempty"`
+	ExternalIds map[string]string    `json:"externalIds,omitempty" bson:"externalIds,omitempty"`
 	Summary     string               `json:"summary" bson:"summary"`
 	Id          primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
 	ReleaseDate int                  `json:"releaseDate" bson:"releaseDate"`
 	Developer   string               `json:"developer" bson:"developer"`
 	Publisher   string               `json:"publisher" bson:"publisher"`
 	Genres      []*EmbeddedGameGenre `json:"genres" bson:"genres"`
+	Platforms   []string             `json:"platforms" bson:"platforms"`
 	Rating      RatingStats          `json:"rating" bson:"rating"`
 	CreatedAt   time.Time            `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt" bson:"updatedAt"`
 	IsDeleted   bool                 `json:"isDeleted" bson:"isDeleted"`
+	DeletedAt   time.Time            `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
 	Image       string               `json:"image" bson:"image"`
+	Revision    int                  `json:"revision" bson:"revision"`
+	// title and summary by locale, Locale is the one they are served in on
+	// localized reads
+	Translations map[string]GameTranslation `json:"translations,omitempty" bson:"translations,omitempty"`
+	Locale       string                     `json:"locale,omitempty" bson:"-"`
+	// MinimumAge is the highest age of the ESRB and PEGI ratings, the one content
+	// settings are checked against
+	AgeRating          *AgeRating          `json:"ageRating,omitempty" bson:"ageRating,omitempty"`
+	ContentDescriptors []ContentDescriptor `json:"contentDescriptors,omitempty" bson:"contentDescriptors,omitempty"`
+	MinimumAge         int                 `json:"minimumAge" bson:"minimumAge"`
+	// set on reads when the game is above the content settings of the user, who
+	// asked for such games to be blurred
+	Restricted bool `json:"restricted,omitempty" bson:"-"`
 }
 
-func NewGameService(repository Repository) *Service {
+func NewGameService(repository Repository, blobStorage storage.BlobStorage, media *MediaConfig, trashRetention time.Duration, ratingPrior RatingPrior, responseCache cache.Cache, externalIdProviders []string, locales *LocaleConfig) *Service {
+	providers := make(map[string]bool, len(externalIdProviders))
+
+	for _, provider := range externalIdProviders {
+		providers[provider] = true
+	}
+
 	return &Service{
-		validate:   validator.New(),
-		repository: repository,
+		validate:       validator.New(),
+		repository:     repository,
+		storage:        blobStorage,
+		media:          media,
+		trashRetention: trashRetention,
+		ratingPrior:    ratingPrior,
+		cache:          newCatalogCache(responseCache),
+
+		externalIdProviders: providers,
+		locales:             locales,
 	}
 }
 
@@ -71,10 +134,74 @@ type Repository interface {
 	getAllGameGenres(ctx context.Context, pagination *Pagination) (*PaginatedResponse[GameGenre], error)
 	deleteGameGenre(ctx context.Context, slug string) error
 	getGame(ctx context.Context, id string) (*Game, error)
+	getGameBySlug(ctx context.Context, slug string) (*Game, error)
+	getGameSlugRedirect(ctx context.Context, slug string) (*GameSlugRedirect, error)
+	getGameSlugOwner(ctx context.Context, slug string) (primitive.ObjectID, error)
+	getGamesWithoutSlug(ctx context.Context) ([]Game, error)
+	setGameSlug(ctx context.Context, id primitive.ObjectID, slug string) error
+	getGamesByExternalIds(ctx context.Context, externalIds map[string]string) ([]Game, error)
+	getGameByTitle(ctx context.Context, title string, releaseDate int) (*Game, error)
+	setGameExternalIds(ctx context.Context, id primitive.ObjectID, externalIds map[string]string) error
+	getDuplicateCandidates(ctx context.Context, game *Game) ([]Game, error)
+	saveDuplicateFlags(ctx context.Context, flags []DuplicateFlag) error
+	getDuplicateFlags(ctx context.Context, status DuplicateStatus, pagination *Pagination) (*PaginatedResponse[DuplicateFlag], error)
+	updateDuplicateFlag(ctx context.Context, id primitive.ObjectID, status DuplicateStatus, reviewerId string) error
+	setGameTranslation(ctx context.Context, id primitive.ObjectID, locale string, translation *GameTranslation) error
+	deleteGameTranslation(ctx context.Context, id primitive.ObjectID, locale string) error
+	setGenreTranslation(ctx context.Context, slug string, locale string, translation *GenreTranslation) error
+	deleteGenreTranslation(ctx context.Context, slug string, locale string) error
+	countMissingTranslations(ctx context.Context, locale string) (int, int, error)
+	getGamesMissingTranslations(ctx context.Context, locales []string, pagination *Pagination) (*PaginatedResponse[Game], error)
+	getGenresMissingTranslations(ctx context.Context, locales []string, pagination *Pagination) (*PaginatedResponse[GameGenre], error)
 	saveGame(ctx context.Context, game *Game) error
-	updateGame(ctx context.Context, game *Game) error
+	updateGame(ctx context.Context, game *Game, revision *GameRevision, previousSlug string) error
+	getGameRevisions(ctx context.Context, gameId primitive.ObjectID, pagination *Pagination) (*PaginatedResponse[GameRevision], error)
+	getGameRevision(ctx context.Context, gameId primitive.ObjectID, revision int) (*GameRevision, error)
 	deleteGame(ctx context.Context, id string) error
 	getAllGames(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Game], error)
+	getGamesByIds(ctx context.Context, ids []primitive.ObjectID) ([]Game, error)
+	saveFranchise(ctx context.Context, franchise *Franchise) error
+	getFranchise(ctx context.Context, slug string) (*Franchise, error)
+	getFranchises(ctx context.Context, slugs []string) ([]Franchise, error)
+	deleteFranchise(ctx context.Context, slug string) error
+	getFranchiseGames(ctx context.Context, slug string) ([]Game, error)
+	saveGameRelation(ctx context.Context, relation *GameRelation) error
+	gameRelationExists(ctx context.Context, relation *GameRelation) (bool, error)
+	getGameRelations(ctx context.Context, gameId primitive.ObjectID) ([]GameRelation, error)
+	deleteGameRelation(ctx context.Context, gameId primitive.ObjectID, relationId string) error
+	saveGameMedia(ctx context.Context, media *GameMedia) error
+	getGameMedia(ctx context.Context, gameId primitive.ObjectID) ([]GameMedia, error)
+	getGameMediaItem(ctx context.Context, gameId primitive.ObjectID, mediaId string) (*GameMedia, error)
+	deleteGameMedia(ctx context.Context, id primitive.ObjectID) error
+	updateGameImage(ctx context.Context, gameId primitive.ObjectID, image string) error
+	getDeletedGames(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Game], error)
+	getDeletedGameGenres(ctx context.Context, pagination *Pagination) (*PaginatedResponse[GameGenre], error)
+	getDeletedGameGenre(ctx context.Context, slug string) (*GameGenre, error)
+	restoreGame(ctx context.Context, id primitive.ObjectID) error
+	restoreGameGenre(ctx context.Context, slug string) error
+	purgeGame(ctx context.Context, id primitive.ObjectID) error
+	purgeGameGenre(ctx context.Context, slug string) error
+	getExpiredTrash(ctx context.Context, deletedBefore time.Time) ([]primitive.ObjectID, []string, error)
+	getChildGenres(ctx context.Context, parents []string) ([]GameGenre, error)
+	getGenreRedirect(ctx context.Context, slug string) (*GenreRedirect, error)
+	renameGameGenre(ctx context.Context, oldSlug string, genre *GameGenre) error
+	mergeGameGenres(ctx context.Context, source *GameGenre, target *GameGenre) error
+	getGameRatings(ctx context.Context) (map[primitive.ObjectID]RatingStats, error)
+	getReviewRatingTotals(ctx context.Context) (map[primitive.ObjectID]RatingStats, error)
+	setGameRating(ctx context.Context, id primitive.ObjectID, before RatingStats, after RatingStats) (bool, error)
+	getGameFeatures(ctx context.Context) ([]Game, error)
+	getUserLikes(ctx context.Context, minRating int) (map[string][]primitive.ObjectID, error)
+	saveGameSimilarities(ctx context.Context, similarities []GameSimilarity, computedAt time.Time) error
+	getGameSimilarity(ctx context.Context, gameId primitive.ObjectID) (*GameSimilarity, error)
+	getGameInteractions(ctx context.Context, userId string) ([]GameInteraction, error)
+	saveUserRecommendations(ctx context.Context, recommendations []UserRecommendations, computedAt time.Time) error
+	getUserRecommendations(ctx context.Context, userId string) (*UserRecommendations, error)
+	getTopRatedGames(ctx context.Context, genres []string, exclude []primitive.ObjectID, content *ContentFilter, limit int) ([]Game, error)
+	getGameActivity(ctx context.Context, since time.Time, until time.Time) (map[primitive.ObjectID]GameActivity, error)
+	saveChart(ctx context.Context, chart ChartKind, window string, entries []ChartEntry) error
+	getChart(ctx context.Context, chart ChartKind, window string, genres []string, platform string, pagination *Pagination) (*PaginatedResponse[ChartEntry], error)
+	getContentPreferences(ctx context.Context, userId string) (*ContentPreferences, error)
+	saveContentPreferences(ctx context.Context, preferences *ContentPreferences) error
 }
 
 func (g *Service) AddGameGenre(ctx context.Context, genre *GameGenre) error {
@@ -89,16 +216,26 @@ func (g *Service) AddGameGenre(ctx context.Context, genre *GameGenre) error {
 		return ErrGameGenreAlreadyExists
 	}
 
+	if genre.Parent != "" {
+		if _, err = g.repository.getGameGenre(ctx, genre.Parent); err != nil {
+			return ErrInvalidGenreParent
+		}
+	}
+
 	err = g.repository.saveGameGenre(ctx, genre)
 
 	if err != nil {
 		return err
 	}
 
+	g.invalidateGenres(ctx)
+
 	return nil
 }
 
-func (g *Service) EditGameGenre(ctx context.Context, genre *GameGenre) error {
+// EditGameGenre updates the title, description and, when parent is not nil,
+// the parent of a genre. An empty parent makes it a top level genre.
+func (g *Service) EditGameGenre(ctx context.Context, genre *GameGenre, parent *string) error {
 	slug := strings.TrimSpace(genre.Slug)
 
 	if genre.Slug == "" {
@@ -113,17 +250,58 @@ func (g *Service) EditGameGenre(ctx context.Context, genre *GameGenre) error {
 	}
 	updateGenre(genre, oldGenre)
 
+	if parent != nil {
+		genre.Parent = strings.TrimSpace(*parent)
+
+		if err = g.checkGenreParent(ctx, genre.Slug, genre.Parent); err != nil {
+			return err
+		}
+	}
+
 	err = g.repository.updateGameGenre(ctx, genre)
 
 	if err != nil {
 		return err
 	}
 
+	g.invalidateGenres(ctx)
+
+	return nil
+}
+
+// checkGenreParent makes sure the parent exists and is not the genre itself
+// or one of its subgenres.
+func (g *Service) checkGenreParent(ctx context.Context, slug string, parent string) error {
+	if parent == "" {
+		return nil
+	}
+
+	if parent == slug {
+		return ErrInvalidGenreParent
+	}
+
+	if _, err := g.repository.getGameGenre(ctx, parent); err != nil {
+		return ErrInvalidGenreParent
+	}
+
+	family, err := g.genreFamily(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	for _, s := range family {
+		if s == parent {
+			return ErrInvalidGenreParent
+		}
+	}
+
 	return nil
 }
 
 func updateGenre(new *GameGenre, old *GameGenre) {
 	new.Slug = old.Slug
+	new.Parent = old.Parent
 	new.CreatedAt = old.CreatedAt
 
 	if strings.TrimSpace(new.Title) == "" {
@@ -137,17 +315,127 @@ func updateGenre(new *GameGenre, old *GameGenre) {
 	new.UpdatedAt = time.Now()
 }
 
+// GetGameGenre returns the genre with the slug, following the redirect left by
+// a rename or merge when the slug is no longer in use.
 func (g *Service) GetGameGenre(ctx context.Context, slug string) (*GameGenre, error) {
 
 	genre, err := g.repository.getGameGenre(ctx, slug)
 
+	if err == nil {
+		return genre, nil
+	}
+
+	redirect, err := g.repository.getGenreRedirect(ctx, slug)
+
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return g.repository.getGameGenre(ctx, redirect.To)
+}
+
+// genreFamily returns the slug of the genre and of all its subgenres.
+func (g *Service) genreFamily(ctx context.Context, slug string) ([]string, error) {
+	family := []string{slug}
+	seen := map[string]bool{slug: true}
+	parents := []string{slug}
+
+	for len(parents) > 0 {
+		children, err := g.repository.getChildGenres(ctx, parents)
+
+		if err != nil {
+			return nil, err
+		}
+
+		parents = nil
+
+		for _, child := range children {
+			if seen[child.Slug] {
+				continue
+			}
+
+			seen[child.Slug] = true
+			family = append(family, child.Slug)
+			parents = append(parents, child.Slug)
+		}
+	}
+
+	return family, nil
+}
+
+// RenameGameGenre gives the genre a new title and slug. Games carrying the genre
+// are updated and the old slug keeps resolving through a redirect.
+func (g *Service) RenameGameGenre(ctx context.Context, slug string, title string) (*GameGenre, error) {
+
+	genre, err := g.repository.getGameGenre(ctx, slug)
+
 	if err != nil {
 		return nil, err
 	}
 
+	newSlug := getSlug(title)
+
+	if newSlug == "" {
+		return nil, ErrBadRequest
+	}
+
+	if newSlug != slug {
+		if _, err = g.repository.getGameGenre(ctx, newSlug); err == nil {
+			return nil, ErrGameGenreAlreadyExists
+		}
+	}
+
+	genre.Title = title
+	genre.Slug = newSlug
+	genre.UpdatedAt = time.Now()
+
+	if err = g.repository.renameGameGenre(ctx, slug, genre); err != nil {
+		return nil, err
+	}
+
+	g.invalidateGenres(ctx)
+
 	return genre, nil
 }
 
+// MergeGameGenres folds the source genre into the target. Games and subgenres
+// of the source move to the target, and the source slug redirects to it.
+func (g *Service) MergeGameGenres(ctx context.Context, sourceSlug string, targetSlug string) error {
+
+	source, err := g.repository.getGameGenre(ctx, sourceSlug)
+
+	if err != nil {
+		return err
+	}
+
+	target, err := g.repository.getGameGenre(ctx, targetSlug)
+
+	if err != nil {
+		return err
+	}
+
+	// merging into a subgenre would leave it as its own ancestor
+	family, err := g.genreFamily(ctx, source.Slug)
+
+	if err != nil {
+		return err
+	}
+
+	for _, s := range family {
+		if s == target.Slug {
+			return ErrInvalidGenreMerge
+		}
+	}
+
+	if err = g.repository.mergeGameGenres(ctx, source, target); err != nil {
+		return err
+	}
+
+	g.invalidateGenres(ctx)
+
+	return nil
+}
+
 func (g *Service) GetAllGenres(ctx context.Context, pagination *Pagination) (*PaginatedResponse[GameGenre], error) {
 
 	paginatedResponse, err := g.repository.getAllGameGenres(ctx, pagination)
@@ -167,71 +455,243 @@ func (g *Service) DeleteGameGenre(ctx context.Context, slug string) error {
 		return err
 	}
 	// ideally, we should isAdminOrModerator if the genre is being used by any game before deleting it
-	return g.repository.deleteGameGenre(ctx, slug)
+	if err = g.repository.deleteGameGenre(ctx, slug); err != nil {
+		return err
+	}
+
+	g.invalidateGenres(ctx)
+
+	return nil
 }
 
+// AddGame saves a new game unless it shares an external id with another game, or
+// its title and release year. Games that only look alike are flagged for review.
 func (g *Service) AddGame(ctx context.Context, newGame *Game) error {
 	if err := g.validate.Struct(newGame); err != nil {
 		return err
 	}
 
-	game, err := g.repository.getGame(ctx, newGame.Id.Hex())
+	if !newGame.AgeRating.isValid() || !validContentDescriptors(newGame.ContentDescriptors) {
+		return ErrInvalidAgeRating
+	}
+
+	var err error
+
+	if newGame.ExternalIds, err = g.normalizeExternalIds(newGame.ExternalIds); err != nil {
+		return err
+	}
+
+	newGame.AgeRating = newGame.AgeRating.normalize()
+	newGame.ContentDescriptors = normalizeContentDescriptors(newGame.ContentDescriptors)
+	newGame.MinimumAge = newGame.AgeRating.minimumAge()
 
-	if err == nil && !game.IsDeleted {
+	_, err = g.findExistingGame(ctx, newGame)
+
+	if err == nil {
 		return ErrGameAlreadyExists
 	}
 
+	if err != ErrNotFound {
+		return err
+	}
+
+	if newGame.Id.IsZero() {
+		newGame.Id = primitive.NewObjectID()
+	}
+
+	newGame.Slug, err = g.uniqueGameSlug(ctx, newGame)
+
+	if err != nil {
+		return err
+	}
+
+	newGame.Rating = g.ratingPrior.stats(0, 0, nil)
+
 	err = g.repository.saveGame(ctx, newGame)
 
 	if err != nil {
 		return err
 	}
 
+	g.invalidateGames(ctx)
+
+	g.flagDuplicates(ctx, newGame)
+
 	return nil
 }
 
-func (g *Service) UpdateGame(ctx context.Context, game *Game) error {
-	if err := g.validate.Struct(game); err != nil {
-		return err
+// UpdateGame applies a partial update, fields left empty in the patch keep
+// their current value. Each update that changes something is recorded as a revision.
+func (g *Service) UpdateGame(ctx context.Context, id string, patch *GameContent) error {
+
+	if !patch.AgeRating.isValid() || !validContentDescriptors(patch.ContentDescriptors) {
+		return ErrInvalidAgeRating
+	}
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil || game.IsDeleted {
+		return ErrNotFound
 	}
 
-	_, err := g.repository.getGame(ctx, game.Id.Hex())
+	current := game.content()
+	updated := mergeGameContent(current, *patch)
+
+	return g.saveGameRevision(ctx, game, current, updated, 0)
+}
+
+func (g *Service) GetGameRevisions(ctx context.Context, id string, pagination *Pagination) (*PaginatedResponse[GameRevision], error) {
+
+	game, err := g.findGame(ctx, id)
 
 	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return g.repository.getGameRevisions(ctx, game.Id, pagination)
+}
+
+// RevertGameRevision undoes the changes made by the given revision, restoring
+// the fields it touched to their previous values. The revert is itself recorded
+// as a new revision.
+func (g *Service) RevertGameRevision(ctx context.Context, id string, revisionNumber int) error {
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil || game.IsDeleted {
 		return ErrNotFound
 	}
 
-	err = g.repository.updateGame(ctx, game)
+	revision, err := g.repository.getGameRevision(ctx, game.Id, revisionNumber)
+
 	if err != nil {
 		return err
 	}
 
+	current := game.content()
+	reverted := revertGameContent(current, revision)
+
+	return g.saveGameRevision(ctx, game, current, reverted, revision.Revision)
+}
+
+func (g *Service) saveGameRevision(ctx context.Context, game *Game, before GameContent, after GameContent, revertOf int) error {
+	changes := diffGameContent(before, after)
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	authorId, _ := ctx.Value("userId").(string)
+
+	applyGameContent(game, after)
+	game.Revision++
+	game.UpdatedAt = time.Now()
+
+	previousSlug := game.Slug
+
+	if before.Title != after.Title || game.Slug == "" {
+		slug, err := g.uniqueGameSlug(ctx, game)
+
+		if err != nil {
+			return err
+		}
+
+		game.Slug = slug
+	}
+
+	revision := &GameRevision{
+		Id:        primitive.NewObjectID(),
+		GameId:    game.Id,
+		Revision:  game.Revision,
+		AuthorId:  authorId,
+		RevertOf:  revertOf,
+		Changes:   changes,
+		Before:    before,
+		After:     after,
+		CreatedAt: game.UpdatedAt,
+	}
+
+	if err := g.repository.updateGame(ctx, game, revision, previousSlug); err != nil {
+		return err
+	}
+
+	g.invalidateGames(ctx)
+
 	return nil
 }
 
 func (g *Service) DeleteGame(ctx context.Context, id string) error {
 
-	err := g.repository.deleteGame(ctx, id)
+	game, err := g.findGame(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = g.repository.deleteGame(ctx, game.Id.Hex())
 	if err != nil {
 		return err
 	}
 
+	g.invalidateGames(ctx)
+
 	return nil
 }
 
 func (g *Service) GetGame(ctx context.Context, id string) (*Game, error) {
 
-	game, err := g.repository.getGame(ctx, id)
+	game, err := g.findGame(ctx, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if game.IsDeleted {
+		return nil, ErrNotFound
+	}
+
+	content, err := g.contentFilter(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if !content.apply(game) {
+		return nil, ErrContentRestricted
+	}
+
 	return game, nil
 }
 
 func (g *Service) GetAllGames(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Game], error) {
 	log.Println("GetAllGames")
+
+	if _, ok := gameSortKeys[pagination.SortBy]; !ok && pagination.SortBy != "" {
+		return nil, ErrBadRequest
+	}
+
+	// filtering by a genre includes its subgenres
+	if slug, ok := pagination.QueryFilters["genres.slug"].(string); ok {
+		if genre, err := g.GetGameGenre(ctx, slug); err == nil {
+			slug = genre.Slug
+		}
+
+		family, err := g.genreFamily(ctx, slug)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pagination.QueryFilters["genres.slug"] = family
+	}
+
+	content, err := g.contentFilter(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	pagination.Content = content
+
 	paginatedResponse, err := g.repository.getAllGames(ctx, pagination)
 
 	if err != nil {
@@ -239,5 +699,1469 @@ func (g *Service) GetAllGames(ctx context.Context, pagination *Pagination) (*Pag
 		return nil, err
 	}
 
+	// the restricted games are left out by the query unless they are to be blurred
+	for i := range paginatedResponse.Data {
+		content.apply(&paginatedResponse.Data[i])
+	}
+
 	return paginatedResponse, nil
 }
+
+func (g *Service) AddFranchise(ctx context.Context, franchise *Franchise) error {
+	if err := g.validate.Struct(franchise); err != nil {
+		return err
+	}
+
+	_, err := g.repository.getFranchise(ctx, franchise.Slug)
+
+	if err == nil {
+		return ErrFranchiseAlreadyExists
+	}
+
+	return g.repository.saveFranchise(ctx, franchise)
+}
+
+func (g *Service) DeleteFranchise(ctx context.Context, slug string) error {
+
+	_, err := g.repository.getFranchise(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	return g.repository.deleteFranchise(ctx, slug)
+}
+
+// GetFranchiseTimeline returns the franchise with its games ordered by release year.
+func (g *Service) GetFranchiseTimeline(ctx context.Context, slug string) (*FranchiseTimeline, error) {
+
+	franchise, err := g.repository.getFranchise(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	games, err := g.repository.getFranchiseGames(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := g.contentFilter(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &FranchiseTimeline{
+		Franchise: *franchise,
+		Games:     []Game{},
+	}
+
+	for i := range games {
+		if content.apply(&games[i]) {
+			timeline.Games = append(timeline.Games, games[i])
+		}
+	}
+
+	return timeline, nil
+}
+
+func (g *Service) AddGameRelation(ctx context.Context, relation *GameRelation) error {
+	if err := g.validate.Struct(relation); err != nil {
+		return ErrInvalidGameRelation
+	}
+
+	game, err := g.repository.getGame(ctx, relation.GameId.Hex())
+
+	if err != nil || game.IsDeleted {
+		return ErrNotFound
+	}
+
+	if relation.Type == RelationPartOfFranchise {
+		if relation.FranchiseSlug == "" {
+			return ErrInvalidGameRelation
+		}
+
+		if _, err := g.repository.getFranchise(ctx, relation.FranchiseSlug); err != nil {
+			return err
+		}
+
+		relation.RelatedGameId = primitive.NilObjectID
+	} else {
+		if relation.RelatedGameId.IsZero() || relation.RelatedGameId == relation.GameId {
+			return ErrInvalidGameRelation
+		}
+
+		relatedGame, err := g.repository.getGame(ctx, relation.RelatedGameId.Hex())
+
+		if err != nil || relatedGame.IsDeleted {
+			return ErrNotFound
+		}
+
+		relation.FranchiseSlug = ""
+
+		// a game can't be both the sequel and the original of the same title
+		inverse := &GameRelation{
+			GameId:        relation.RelatedGameId,
+			Type:          relation.Type,
+			RelatedGameId: relation.GameId,
+		}
+
+		exists, err := g.repository.gameRelationExists(ctx, inverse)
+
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return ErrInvalidGameRelation
+		}
+	}
+
+	exists, err := g.repository.gameRelationExists(ctx, relation)
+
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrGameRelationAlreadyExists
+	}
+
+	relation.Id = primitive.NewObjectID()
+	relation.CreatedAt = time.Now()
+
+	return g.repository.saveGameRelation(ctx, relation)
+}
+
+// GetGameRelations returns the games related to the given game in either
+// direction, and the franchises it belongs to.
+func (g *Service) GetGameRelations(ctx context.Context, id string) (*GameRelations, error) {
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil || game.IsDeleted {
+		return nil, ErrNotFound
+	}
+
+	relations, err := g.repository.getGameRelations(ctx, game.Id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var gameIds []primitive.ObjectID
+	var franchiseSlugs []string
+
+	for _, relation := range relations {
+		if relation.Type == RelationPartOfFranchise {
+			franchiseSlugs = append(franchiseSlugs, relation.FranchiseSlug)
+			continue
+		}
+
+		if relation.GameId == game.Id {
+			gameIds = append(gameIds, relation.RelatedGameId)
+		} else {
+			gameIds = append(gameIds, relation.GameId)
+		}
+	}
+
+	response := &GameRelations{
+		Related:    []RelatedGame{},
+		Franchises: []Franchise{},
+	}
+
+	if len(gameIds) > 0 {
+		games, err := g.repository.getGamesByIds(ctx, gameIds)
+
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := g.contentFilter(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		gamesById := make(map[primitive.ObjectID]Game)
+		for _, relatedGame := range games {
+			if content.apply(&relatedGame) {
+				gamesById[relatedGame.Id] = relatedGame
+			}
+		}
+
+		for _, relation := range relations {
+			if relation.Type == RelationPartOfFranchise {
+				continue
+			}
+
+			isInverse := relation.RelatedGameId == game.Id
+			otherId := relation.RelatedGameId
+			if isInverse {
+				otherId = relation.GameId
+			}
+
+			relatedGame, ok := gamesById[otherId]
+			if !ok {
+				continue
+			}
+
+			response.Related = append(response.Related, RelatedGame{
+				RelationId: relation.Id.Hex(),
+				Type:       relation.Type,
+				IsInverse:  isInverse,
+				Game:       relatedGame,
+			})
+		}
+	}
+
+	if len(franchiseSlugs) > 0 {
+		franchises, err := g.repository.getFranchises(ctx, franchiseSlugs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		response.Franchises = franchises
+	}
+
+	return response, nil
+}
+
+func (g *Service) DeleteGameRelation(ctx context.Context, gameId string, relationId string) error {
+
+	id, err := primitive.ObjectIDFromHex(gameId)
+
+	if err != nil {
+		return ErrBadRequest
+	}
+
+	return g.repository.deleteGameRelation(ctx, id, relationId)
+}
+
+// AddGameImage stores an uploaded cover or screenshot along with its
+// thumbnails and WebP variants. Uploading a cover also makes it the game's image.
+func (g *Service) AddGameImage(ctx context.Context, gameId string, kind MediaKind, data []byte) (*GameMedia, error) {
+	if kind != MediaCover && kind != MediaScreenshot {
+		return nil, ErrInvalidMediaKind
+	}
+
+	if int64(len(data)) > g.media.MaxUploadBytes {
+		return nil, ErrMediaTooLarge
+	}
+
+	game, err := g.findGame(ctx, gameId)
+
+	if err != nil || game.IsDeleted {
+		return nil, ErrNotFound
+	}
+
+	contentType, ext, err := detectImageType(data)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	media := &GameMedia{
+		Id:        primitive.NewObjectID(),
+		GameId:    game.Id,
+		Kind:      kind,
+		Variants:  []MediaVariant{},
+		CreatedAt: time.Now(),
+	}
+
+	encoded := map[string]*encodedImage{
+		"original": {
+			data:        data,
+			contentType: contentType,
+			ext:         ext,
+			width:       img.Bounds().Dx(),
+			height:      img.Bounds().Dy(),
+		},
+	}
+	names := []string{"original", "webp"}
+
+	if encoded["webp"], err = encodeWebP(img); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	for _, size := range thumbnailSizes {
+		// always keep the smallest thumbnail, skip the sizes that would only upscale
+		if img.Bounds().Dx() <= size.width && size.name != thumbnailSizes[0].name {
+			continue
+		}
+
+		resized := resizeImage(img, size.width)
+
+		if encoded[size.name], err = encodeThumbnail(resized, contentType); err != nil {
+			log.Println(err)
+			return nil, UnknownError
+		}
+
+		if encoded[size.name+"-webp"], err = encodeWebP(resized); err != nil {
+			log.Println(err)
+			return nil, UnknownError
+		}
+
+		names = append(names, size.name, size.name+"-webp")
+	}
+
+	keyPrefix := "games/" + game.Id.Hex() + "/" + media.Id.Hex() + "/"
+
+	for _, name := range names {
+		variant := encoded[name]
+		key := keyPrefix + name + variant.ext
+
+		info, err := g.storage.Put(ctx, key, variant.contentType, bytes.NewReader(variant.data))
+
+		if err != nil {
+			g.deleteMediaBlobs(ctx, media)
+			return nil, UnknownError
+		}
+
+		media.Variants = append(media.Variants, MediaVariant{
+			Name:        name,
+			Url:         g.media.BaseUrl + key,
+			Key:         key,
+			ContentType: variant.contentType,
+			Width:       variant.width,
+			Height:      variant.height,
+			Size:        info.Size,
+		})
+	}
+
+	media.Url = media.Variants[0].Url
+
+	if err = g.repository.saveGameMedia(ctx, media); err != nil {
+		g.deleteMediaBlobs(ctx, media)
+		return nil, err
+	}
+
+	if kind == MediaCover {
+		if err = g.repository.updateGameImage(ctx, game.Id, media.Url); err != nil {
+			return nil, err
+		}
+
+		g.invalidateGames(ctx)
+	}
+
+	return media, nil
+}
+
+func (g *Service) AddGameTrailer(ctx context.Context, gameId string, link string) (*GameMedia, error) {
+	trailerUrl, err := url.ParseRequestURI(strings.TrimSpace(link))
+
+	if err != nil || (trailerUrl.Scheme != "http" && trailerUrl.Scheme != "https") || trailerUrl.Host == "" {
+		return nil, ErrInvalidTrailerUrl
+	}
+
+	game, err := g.findGame(ctx, gameId)
+
+	if err != nil || game.IsDeleted {
+		return nil, ErrNotFound
+	}
+
+	media := &GameMedia{
+		Id:        primitive.NewObjectID(),
+		GameId:    game.Id,
+		Kind:      MediaTrailer,
+		Url:       trailerUrl.String(),
+		Variants:  []MediaVariant{},
+		CreatedAt: time.Now(),
+	}
+
+	if err = g.repository.saveGameMedia(ctx, media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+func (g *Service) GetGameMedia(ctx context.Context, gameId string) ([]GameMedia, error) {
+
+	game, err := g.findGame(ctx, gameId)
+
+	if err != nil || game.IsDeleted {
+		return nil, ErrNotFound
+	}
+
+	return g.repository.getGameMedia(ctx, game.Id)
+}
+
+func (g *Service) DeleteGameMedia(ctx context.Context, gameId string, mediaId string) error {
+
+	game, err := g.findGame(ctx, gameId)
+
+	if err != nil {
+		return ErrNotFound
+	}
+
+	media, err := g.repository.getGameMediaItem(ctx, game.Id, mediaId)
+
+	if err != nil {
+		return err
+	}
+
+	if err = g.repository.deleteGameMedia(ctx, media.Id); err != nil {
+		return err
+	}
+
+	if media.Kind == MediaCover && game.Image == media.Url {
+		if err = g.repository.updateGameImage(ctx, game.Id, ""); err != nil {
+			return err
+		}
+
+		g.invalidateGames(ctx)
+	}
+
+	g.deleteMediaBlobs(ctx, media)
+
+	return nil
+}
+
+func (g *Service) GetMediaBlob(ctx context.Context, key string) (io.ReadCloser, *storage.BlobInfo, error) {
+	if !strings.HasPrefix(key, "games/") {
+		return nil, nil, ErrMediaNotFound
+	}
+
+	reader, info, err := g.storage.Get(ctx, key)
+
+	if err != nil {
+		return nil, nil, ErrMediaNotFound
+	}
+
+	return reader, info, nil
+}
+
+func (g *Service) deleteMediaBlobs(ctx context.Context, media *GameMedia) {
+	for _, variant := range media.Variants {
+		if err := g.storage.Delete(ctx, variant.Key); err != nil {
+			log.Println("Error deleting media blob: " + variant.Key)
+		}
+	}
+}
+
+func (game *Game) content() GameContent {
+	return GameContent{
+		Title:       game.Title,
+		Summary:     game.Summary,
+		ReleaseDate: game.ReleaseDate,
+		Developer:   game.Developer,
+		Publisher:   game.Publisher,
+		Genres:      game.Genres,
+		Platforms:   game.Platforms,
+		Image:       game.Image,
+
+		AgeRating:          game.AgeRating,
+		ContentDescriptors: game.ContentDescriptors,
+	}
+}
+
+func applyGameContent(game *Game, content GameContent) {
+	game.Title = content.Title
+	game.Summary = content.Summary
+	game.ReleaseDate = content.ReleaseDate
+	game.Developer = content.Developer
+	game.Publisher = content.Publisher
+	game.Genres = content.Genres
+	game.Platforms = content.Platforms
+	game.Image = content.Image
+	game.AgeRating = content.AgeRating
+	game.ContentDescriptors = content.ContentDescriptors
+	game.MinimumAge = content.AgeRating.minimumAge()
+}
+
+func mergeGameContent(old GameContent, patch GameContent) GameContent {
+	merged := old
+
+	if strings.TrimSpace(patch.Title) != "" {
+		merged.Title = strings.TrimSpace(patch.Title)
+	}
+
+	if strings.TrimSpace(patch.Summary) != "" {
+		merged.Summary = strings.TrimSpace(patch.Summary)
+	}
+
+	if patch.ReleaseDate != 0 {
+		merged.ReleaseDate = patch.ReleaseDate
+	}
+
+	if strings.TrimSpace(patch.Developer) != "" {
+		merged.Developer = strings.TrimSpace(patch.Developer)
+	}
+
+	if strings.TrimSpace(patch.Publisher) != "" {
+		merged.Publisher = strings.TrimSpace(patch.Publisher)
+	}
+
+	if patch.Genres != nil {
+		merged.Genres = patch.Genres
+	}
+
+	if patch.Platforms != nil {
+		merged.Platforms = normalizePlatforms(patch.Platforms)
+	}
+
+	if strings.TrimSpace(patch.Image) != "" {
+		merged.Image = strings.TrimSpace(patch.Image)
+	}
+
+	if patch.AgeRating != nil {
+		merged.AgeRating = patch.AgeRating.normalize()
+	}
+
+	if patch.ContentDescriptors != nil {
+		merged.ContentDescriptors = normalizeContentDescriptors(patch.ContentDescriptors)
+	}
+
+	return merged
+}
+
+// diffGameContent returns the json names of the fields that differ.
+func diffGameContent(old GameContent, new GameContent) []string {
+	var changes []string
+
+	if old.Title != new.Title {
+		changes = append(changes, "title")
+	}
+
+	if old.Summary != new.Summary {
+		changes = append(changes, "summary")
+	}
+
+	if old.ReleaseDate != new.ReleaseDate {
+		changes = append(changes, "releaseDate")
+	}
+
+	if old.Developer != new.Developer {
+		changes = append(changes, "developer")
+	}
+
+	if old.Publisher != new.Publisher {
+		changes = append(changes, "publisher")
+	}
+
+	if !sameGenres(old.Genres, new.Genres) {
+		changes = append(changes, "genres")
+	}
+
+	if !samePlatforms(old.Platforms, new.Platforms) {
+		changes = append(changes, "platforms")
+	}
+
+	if old.Image != new.Image {
+		changes = append(changes, "image")
+	}
+
+	if !sameAgeRating(old.AgeRating, new.AgeRating) {
+		changes = append(changes, "ageRating")
+	}
+
+	if !sameContentDescriptors(old.ContentDescriptors, new.ContentDescriptors) {
+		changes = append(changes, "contentDescriptors")
+	}
+
+	return changes
+}
+
+func sameGenres(a []*EmbeddedGameGenre, b []*EmbeddedGameGenre) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] == nil || b[i] == nil {
+			if a[i] != b[i] {
+				return false
+			}
+			continue
+		}
+
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizePlatforms lower cases the platforms, e.g. "pc" or "ps5", and drops
+// blanks and duplicates so they can be filtered on.
+func normalizePlatforms(platforms []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool)
+
+	for _, platform := range platforms {
+		platform = strings.ToLower(strings.TrimSpace(platform))
+
+		if platform == "" || seen[platform] {
+			continue
+		}
+
+		seen[platform] = true
+		normalized = append(normalized, platform)
+	}
+
+	return normalized
+}
+
+func samePlatforms(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func revertGameContent(current GameContent, revision *GameRevision) GameContent {
+	reverted := current
+
+	for _, field := range revision.Changes {
+		switch field {
+		case "title":
+			reverted.Title = revision.Before.Title
+		case "summary":
+			reverted.Summary = revision.Before.Summary
+		case "releaseDate":
+			reverted.ReleaseDate = revision.Before.ReleaseDate
+		case "developer":
+			reverted.Developer = revision.Before.Developer
+		case "publisher":
+			reverted.Publisher = revision.Before.Publisher
+		case "genres":
+			reverted.Genres = revision.Before.Genres
+		case "platforms":
+			reverted.Platforms = revision.Before.Platforms
+		case "image":
+			reverted.Image = revision.Before.Image
+		case "ageRating":
+			reverted.AgeRating = revision.Before.AgeRating
+		case "contentDescriptors":
+			reverted.ContentDescriptors = revision.Before.ContentDescriptors
+		}
+	}
+
+	return reverted
+}
+
+func (g *Service) GetTrashedGames(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Game], error) {
+	return g.repository.getDeletedGames(ctx, pagination)
+}
+
+func (g *Service) GetTrashedGenres(ctx context.Context, pagination *Pagination) (*PaginatedResponse[GameGenre], error) {
+	return g.repository.getDeletedGameGenres(ctx, pagination)
+}
+
+func (g *Service) RestoreGame(ctx context.Context, id string) error {
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil {
+		return ErrNotFound
+	}
+
+	if !game.IsDeleted {
+		return ErrNotInTrash
+	}
+
+	if err = g.repository.restoreGame(ctx, game.Id); err != nil {
+		return err
+	}
+
+	g.invalidateGames(ctx)
+
+	return nil
+}
+
+func (g *Service) RestoreGameGenre(ctx context.Context, slug string) error {
+
+	_, err := g.repository.getDeletedGameGenre(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	// a new genre may have taken the slug while this one was in the trash
+	_, err = g.repository.getGameGenre(ctx, slug)
+
+	if err == nil {
+		return ErrGameGenreAlreadyExists
+	}
+
+	if err = g.repository.restoreGameGenre(ctx, slug); err != nil {
+		return err
+	}
+
+	g.invalidateGenres(ctx)
+
+	return nil
+}
+
+// PurgeGame permanently removes a game from the trash together with its reviews,
+// their votes, its relations, revisions, old slugs, duplicate flags and media.
+func (g *Service) PurgeGame(ctx context.Context, id string) error {
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil {
+		return ErrNotFound
+	}
+
+	if !game.IsDeleted {
+		return ErrNotInTrash
+	}
+
+	return g.purgeGame(ctx, game.Id)
+}
+
+func (g *Service) purgeGame(ctx context.Context, id primitive.ObjectID) error {
+
+	media, err := g.repository.getGameMedia(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	if err = g.repository.purgeGame(ctx, id); err != nil {
+		return err
+	}
+
+	for i := range media {
+		g.deleteMediaBlobs(ctx, &media[i])
+	}
+
+	return nil
+}
+
+func (g *Service) PurgeGameGenre(ctx context.Context, slug string) error {
+
+	_, err := g.repository.getDeletedGameGenre(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	if err = g.repository.purgeGameGenre(ctx, slug); err != nil {
+		return err
+	}
+
+	g.invalidateGenres(ctx)
+
+	return nil
+}
+
+// PurgeExpiredTrash purges the games and genres that have been in the trash
+// for longer than the retention period.
+func (g *Service) PurgeExpiredTrash(ctx context.Context) (int, error) {
+
+	gameIds, genreSlugs, err := g.repository.getExpiredTrash(ctx, time.Now().Add(-g.trashRetention))
+
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, id := range gameIds {
+		if err := g.purgeGame(ctx, id); err != nil {
+			log.Println("Error purging game: " + id.Hex())
+			continue
+		}
+		purged++
+	}
+
+	for _, slug := range genreSlugs {
+		if err := g.repository.purgeGameGenre(ctx, slug); err != nil {
+			log.Println("Error purging game genre: " + slug)
+			continue
+		}
+		purged++
+	}
+
+	if len(genreSlugs) > 0 {
+		g.invalidateGenres(ctx)
+	}
+
+	return purged, nil
+}
+
+func (g *Service) runTrashPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := g.PurgeExpiredTrash(ctx)
+
+		if err != nil {
+			log.Println("Error purging trash: " + err.Error())
+		} else if purged > 0 {
+			log.Printf("Purged %d items from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RecalculateRatings rebuilds the rating stats of every game from the reviews
+// collection, using the current prior, and returns the games whose stored stats
+// had drifted. A game written to by a review since its stats were read is left
+// for the next run.
+func (g *Service) RecalculateRatings(ctx context.Context) ([]RatingDrift, error) {
+
+	// the games are read before the reviews, a review saved in between is then
+	// in the totals and the stats are still written
+	current, err := g.repository.getGameRatings(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := g.repository.getReviewRatingTotals(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	drifts := []RatingDrift{}
+
+	for id, stats := range current {
+		total := totals[id]
+		recalculated := g.ratingPrior.stats(total.Sum, total.Count, total.Histogram)
+		recalculated.SubScores = subScoreStats(total.SubScores)
+		recalculated.UpdatedAt = time.Now()
+
+		if sameRatingStats(stats, recalculated) {
+			continue
+		}
+
+		updated, err := g.repository.setGameRating(ctx, id, stats, recalculated)
+
+		if err != nil {
+			return drifts, err
+		}
+
+		if updated {
+			drifts = append(drifts, RatingDrift{GameId: id.Hex(), Before: stats, After: recalculated})
+		}
+	}
+
+	if len(drifts) > 0 {
+		g.invalidateGames(ctx)
+	}
+
+	return drifts, nil
+}
+
+// runRatingReconciler periodically recalculates the ratings and logs the drift it corrects.
+func (g *Service) runRatingReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		drifts, err := g.RecalculateRatings(ctx)
+
+		if err != nil {
+			log.Println("Error reconciling ratings: " + err.Error())
+		}
+
+		for _, drift := range drifts {
+			log.Printf("Corrected rating drift for game %s: sum %d -> %d, count %d -> %d",
+				drift.GameId, drift.Before.Sum, drift.After.Sum, drift.Before.Count, drift.After.Count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func sameRatingStats(a RatingStats, b RatingStats) bool {
+	if a.Sum != b.Sum || a.Count != b.Count {
+		return false
+	}
+
+	if math.Abs(a.Average-b.Average) > 1e-9 || math.Abs(a.Weighted-b.Weighted) > 1e-9 {
+		return false
+	}
+
+	for rating := MinRating; rating <= MaxRating; rating++ {
+		bucket := strconv.Itoa(rating)
+
+		if a.Histogram[bucket] != b.Histogram[bucket] {
+			return false
+		}
+	}
+
+	return sameSubScoreStats(a.SubScores, b.SubScores)
+}
+
+// ComputeSimilarGames recomputes the similar games of every game that is not
+// deleted and returns how many games have recommendations.
+func (g *Service) ComputeSimilarGames(ctx context.Context) (int, error) {
+
+	games, err := g.repository.getGameFeatures(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	likes, err := g.repository.getUserLikes(ctx, highRating)
+
+	if err != nil {
+		return 0, err
+	}
+
+	computedAt := time.Now()
+	similar := computeSimilarGames(games, likes)
+
+	similarities := make([]GameSimilarity, 0, len(similar))
+	for gameId, similarGames := range similar {
+		similarities = append(similarities, GameSimilarity{GameId: gameId, Similar: similarGames, ComputedAt: computedAt})
+	}
+
+	if err := g.repository.saveGameSimilarities(ctx, similarities, computedAt); err != nil {
+		return 0, err
+	}
+
+	return len(similarities), nil
+}
+
+// runSimilarGamesJob periodically recomputes the similar games.
+func (g *Service) runSimilarGamesJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := g.ComputeSimilarGames(ctx); err != nil {
+			log.Println("Error computing similar games: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetSimilarGames returns up to limit games similar to the given one, best
+// first. It is empty until the similar games job has run for the game.
+func (g *Service) GetSimilarGames(ctx context.Context, id string, limit int) ([]SimilarGame, error) {
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil || game.IsDeleted {
+		return nil, ErrNotFound
+	}
+
+	if limit < 1 || limit > similarGamesStored {
+		limit = similarGamesStored
+	}
+
+	similarity, err := g.repository.getGameSimilarity(ctx, game.Id)
+
+	if err == ErrNotFound {
+		return []SimilarGame{}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(similarity.Similar) == 0 {
+		return []SimilarGame{}, nil
+	}
+
+	gameIds := make([]primitive.ObjectID, 0, len(similarity.Similar))
+	for _, similarGame := range similarity.Similar {
+		gameIds = append(gameIds, similarGame.GameId)
+	}
+
+	games, err := g.repository.getGamesByIds(ctx, gameIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := g.contentFilter(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gamesById := make(map[primitive.ObjectID]Game)
+	for _, similarGame := range games {
+		if content.apply(&similarGame) {
+			gamesById[similarGame.Id] = similarGame
+		}
+	}
+
+	// games deleted since the last run, or above the content settings of the user, are skipped
+	response := []SimilarGame{}
+
+	for _, similarGame := range similarity.Similar {
+		game, ok := gamesById[similarGame.GameId]
+		if !ok {
+			continue
+		}
+
+		similarGame.Game = &game
+		response = append(response, similarGame)
+
+		if len(response) == limit {
+			break
+		}
+	}
+
+	return response, nil
+}
+
+// ComputeUserRecommendations trains the collaborative filtering model on every
+// review and vote and stores the recommendations of each user who liked a game.
+// It returns how many users have recommendations.
+func (g *Service) ComputeUserRecommendations(ctx context.Context) (int, error) {
+
+	interactions, err := g.repository.getGameInteractions(ctx, "")
+
+	if err != nil {
+		return 0, err
+	}
+
+	computedAt := time.Now()
+	merged := mergeInteractions(interactions)
+
+	preferences := make(map[string]map[primitive.ObjectID]float64, len(merged))
+	for userId, games := range merged {
+		preferences[userId] = preferencesOf(games)
+	}
+
+	model := trainRecommendationModel(preferences)
+
+	recommendations := []UserRecommendations{}
+
+	for userId, userPreferences := range preferences {
+		recommended := recommendGames(model, userPreferences, userRecommendationsStored)
+
+		if len(recommended) == 0 {
+			continue
+		}
+
+		recommendations = append(recommendations, UserRecommendations{UserId: userId, Games: recommended, ComputedAt: computedAt})
+	}
+
+	if err := g.repository.saveUserRecommendations(ctx, recommendations, computedAt); err != nil {
+		return 0, err
+	}
+
+	return len(recommendations), nil
+}
+
+// runUserRecommendationsJob periodically recomputes the recommendations of users.
+func (g *Service) runUserRecommendationsJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := g.ComputeUserRecommendations(ctx); err != nil {
+			log.Println("Error computing user recommendations: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetRecommendedGames returns up to limit games the calling user has neither
+// reviewed nor voted on, best first. The precomputed recommendations are topped
+// up with the top rated games of the user's preferred genres, then of any genre,
+// so users with little or no history still get some.
+func (g *Service) GetRecommendedGames(ctx context.Context, limit int) ([]RecommendedGame, error) {
+
+	userId, _ := ctx.Value("userId").(string)
+
+	if userId == "" {
+		return nil, ErrUnauthorized
+	}
+
+	if limit < 1 || limit > userRecommendationsStored {
+		limit = userRecommendationsStored
+	}
+
+	interactions, err := g.repository.getGameInteractions(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	seen := mergeInteractions(interactions)[userId]
+
+	content, err := g.contentFilter(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := g.repository.getUserRecommendations(ctx, userId)
+
+	if err != nil && err != ErrNotFound {
+		return nil, err
+	}
+
+	response := []RecommendedGame{}
+
+	if stored != nil {
+		// games reviewed since the last run are no longer recommended
+		var gameIds []primitive.ObjectID
+		for _, recommended := range stored.Games {
+			if _, ok := seen[recommended.GameId]; !ok {
+				gameIds = append(gameIds, recommended.GameId)
+			}
+		}
+
+		if len(gameIds) > 0 {
+			games, err := g.repository.getGamesByIds(ctx, gameIds)
+
+			if err != nil {
+				return nil, err
+			}
+
+			gamesById := make(map[primitive.ObjectID]Game)
+			for _, game := range games {
+				if content.apply(&game) {
+					gamesById[game.Id] = game
+				}
+			}
+
+			for _, recommended := range stored.Games {
+				game, ok := gamesById[recommended.GameId]
+				if !ok {
+					continue
+				}
+
+				recommended.Game = &game
+				response = append(response, recommended)
+
+				if len(response) == limit {
+					return response, nil
+				}
+			}
+		}
+	}
+
+	exclude := make([]primitive.ObjectID, 0, len(seen)+len(response))
+	for gameId := range seen {
+		exclude = append(exclude, gameId)
+	}
+	for _, recommended := range response {
+		exclude = append(exclude, recommended.GameId)
+	}
+
+	genres, err := g.preferredGenres(ctx, seen)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fallbacks := []struct {
+		genres []string
+		reason string
+	}{
+		{genres: genres, reason: "top-rated-in-genre"},
+		{reason: "top-rated"},
+	}
+
+	for _, fallback := range fallbacks {
+		if len(response) == limit {
+			break
+		}
+
+		if fallback.reason == "top-rated-in-genre" && len(fallback.genres) == 0 {
+			continue
+		}
+
+		games, err := g.repository.getTopRatedGames(ctx, fallback.genres, exclude, content, limit-len(response))
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, game := range games {
+			game := game
+			content.apply(&game)
+			response = append(response, RecommendedGame{GameId: game.Id, Score: game.Rating.Weighted, Reason: fallback.reason, Game: &game})
+			exclude = append(exclude, game.Id)
+		}
+	}
+
+	return response, nil
+}
+
+// preferredGenres returns the slugs of the genres the user liked the most games of.
+func (g *Service) preferredGenres(ctx context.Context, interactions map[primitive.ObjectID]GameInteraction) ([]string, error) {
+
+	var liked []primitive.ObjectID
+	for gameId, interaction := range interactions {
+		if interaction.Preference > 0 {
+			liked = append(liked, gameId)
+		}
+	}
+
+	if len(liked) == 0 {
+		return nil, nil
+	}
+
+	games, err := g.repository.getGamesByIds(ctx, liked)
+
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, game := range games {
+		for _, genre := range game.Genres {
+			if genre != nil {
+				counts[genre.Slug]++
+			}
+		}
+	}
+
+	genres := make([]string, 0, len(counts))
+	for slug := range counts {
+		genres = append(genres, slug)
+	}
+
+	sort.Slice(genres, func(i, j int) bool {
+		if counts[genres[i]] != counts[genres[j]] {
+			return counts[genres[i]] > counts[genres[j]]
+		}
+
+		return genres[i] < genres[j]
+	})
+
+	if len(genres) > preferredGenres {
+		genres = genres[:preferredGenres]
+	}
+
+	return genres, nil
+}
+
+// EvaluateRecommendations reports the offline precision@k of the collaborative
+// filtering model on the current reviews and votes.
+func (g *Service) EvaluateRecommendations(ctx context.Context, k int) (*RecommendationEvaluation, error) {
+
+	if k < 1 || k > userRecommendationsStored {
+		return nil, ErrBadRequest
+	}
+
+	interactions, err := g.repository.getGameInteractions(ctx, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation := evaluateRecommendations(mergeInteractions(interactions), k)
+
+	return &evaluation, nil
+}
+
+// ComputeCharts materializes the top rated chart and, for every window, the
+// trending, most reviewed and rising charts.
+func (g *Service) ComputeCharts(ctx context.Context) error {
+
+	games, err := g.repository.getGameFeatures(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	topRated := rankChart(ChartTopRated, chartAllTime, games, func(game *Game) (float64, GameActivity) {
+		activity := GameActivity{Reviews: game.Rating.Count, RatingSum: game.Rating.Sum}
+
+		if game.Rating.Count == 0 {
+			return 0, activity
+		}
+
+		return game.Rating.Weighted, activity
+	}, now)
+
+	if err := g.repository.saveChart(ctx, ChartTopRated, chartAllTime, topRated); err != nil {
+		return err
+	}
+
+	for _, window := range timeWindows {
+		since := window.Since(now, 1)
+
+		current, err := g.repository.getGameActivity(ctx, since, now)
+
+		if err != nil {
+			return err
+		}
+
+		// rising compares with the window of the same length just before
+		previous, err := g.repository.getGameActivity(ctx, window.Since(since, 1), since)
+
+		if err != nil {
+			return err
+		}
+
+		charts := map[ChartKind]func(game *Game) (float64, GameActivity){
+			ChartTrending: func(game *Game) (float64, GameActivity) {
+				return current[game.Id].trendingScore(), current[game.Id]
+			},
+			ChartMostReviewed: func(game *Game) (float64, GameActivity) {
+				return float64(current[game.Id].Reviews), current[game.Id]
+			},
+			ChartRising: func(game *Game) (float64, GameActivity) {
+				return risingScore(current[game.Id], previous[game.Id]), current[game.Id]
+			},
+		}
+
+		for chart, score := range charts {
+			entries := rankChart(chart, window.String(), games, score, now)
+
+			if err := g.repository.saveChart(ctx, chart, window.String(), entries); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// runChartsJob periodically recomputes the charts.
+func (g *Service) runChartsJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := g.ComputeCharts(ctx); err != nil {
+			log.Println("Error computing charts: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetChart lists a materialized chart. window is "day", "week" or "month" and
+// defaults per chart, it is ignored by the top rated chart. Filtering by a genre
+// includes its subgenres.
+func (g *Service) GetChart(ctx context.Context, chart ChartKind, window string, genre string, platform string, pagination *Pagination) (*PaginatedResponse[ChartEntry], error) {
+
+	if !chart.isValid() {
+		return nil, ErrChartNotFound
+	}
+
+	windowName := chartAllTime
+
+	if chart.isWindowed() {
+		timeWindow := chartDefaultWindows[chart]
+
+		if window != "" {
+			parsed, ok := ParseTimeWindow(window)
+
+			if !ok {
+				return nil, ErrBadRequest
+			}
+
+			timeWindow = parsed
+		}
+
+		windowName = timeWindow.String()
+	}
+
+	var genres []string
+
+	if genre != "" {
+		if found, err := g.GetGameGenre(ctx, genre); err == nil {
+			genre = found.Slug
+		}
+
+		family, err := g.genreFamily(ctx, genre)
+
+		if err != nil {
+			return nil, err
+		}
+
+		genres = family
+	}
+
+	response, err := g.repository.getChart(ctx, chart, windowName, genres, strings.ToLower(strings.TrimSpace(platform)), pagination)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Data) == 0 {
+		return response, nil
+	}
+
+	gameIds := make([]primitive.ObjectID, 0, len(response.Data))
+	for _, entry := range response.Data {
+		gameIds = append(gameIds, entry.GameId)
+	}
+
+	games, err := g.repository.getGamesByIds(ctx, gameIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := g.contentFilter(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gamesById := make(map[primitive.ObjectID]Game)
+	for _, game := range games {
+		if content.apply(&game) {
+			gamesById[game.Id] = game
+		}
+	}
+
+	// games deleted since the charts were computed, or above the content settings
+	// of the user, are skipped and the page comes up short
+	entries := []ChartEntry{}
+
+	for _, entry := range response.Data {
+		game, ok := gamesById[entry.GameId]
+		if !ok {
+			continue
+		}
+
+		entry.Game = &game
+		entries = append(entries, entry)
+	}
+
+	response.Data = entries
+
+	return response, nil
+}
@@ -1,12 +1,350 @@
 package games
 
-import "time"
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
 
 type GameGenre struct {
+	Title string `json:"title" bson:"title" validate:"required"`
+	Slug  string `json:"slug" bson:"slug" validate:"required"`
+	Desc  string `json:"desc" bson:"desc" validate:"required"`
+	// slug of the parent genre, empty for top level genres
+	Parent    string    `json:"parent,omitempty" bson:"parent,omitempty"`
+	CreatedAt time.Time `json:"createdAt" bson:"dateAdded"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+	IsDeleted bool      `json:"isDeleted" bson:"isDeleted"`
+	DeletedAt time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	// description by locale, Locale is the one it is served in on localized reads
+	Translations map[string]GenreTranslation `json:"translations,omitempty" bson:"translations,omitempty"`
+	Locale       string                      `json:"locale,omitempty" bson:"-"`
+}
+
+// GenreTranslation is the description of a genre in another locale.
+type GenreTranslation struct {
+	Desc string `json:"desc" bson:"desc"`
+}
+
+// GameTranslation is the title and summary of a game in another locale, an
+// empty field falls back to the default locale.
+type GameTranslation struct {
+	Title   string `json:"title" bson:"title"`
+	Summary string `json:"summary" bson:"summary"`
+}
+
+// MissingTranslations is a game or genre lacking a complete translation to
+// some of the supported locales. Id is only set for games.
+type MissingTranslations struct {
+	Id      string   `json:"id,omitempty"`
+	Slug    string   `json:"slug"`
+	Title   string   `json:"title"`
+	Missing []string `json:"missing"`
+}
+
+// LocaleCoverage counts the games and genres not translated to a locale.
+type LocaleCoverage struct {
+	Locale        string `json:"locale"`
+	MissingGames  int    `json:"missingGames"`
+	MissingGenres int    `json:"missingGenres"`
+}
+
+type TranslationReport struct {
+	DefaultLocale string           `json:"defaultLocale"`
+	Locales       []LocaleCoverage `json:"locales"`
+}
+
+// GenreRedirect keeps an old genre slug resolving after a rename or merge.
+type GenreRedirect struct {
+	From      string    `json:"from" bson:"from"`
+	To        string    `json:"to" bson:"to"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// GameSlugRedirect keeps an old game slug resolving after a title change.
+type GameSlugRedirect struct {
+	From      string             `json:"from" bson:"from"`
+	GameId    primitive.ObjectID `json:"gameId" bson:"gameId"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+type Franchise struct {
 	Title     string    `json:"title" bson:"title" validate:"required"`
 	Slug      string    `json:"slug" bson:"slug" validate:"required"`
-	Desc      string    `json:"desc" bson:"desc" validate:"required"`
+	Desc      string    `json:"desc" bson:"desc"`
 	CreatedAt time.Time `json:"createdAt" bson:"dateAdded"`
 	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
 	IsDeleted bool      `json:"isDeleted" bson:"isDeleted"`
 }
+
+type RelationType string
+
+const (
+	RelationSequelOf        RelationType = "sequel-of"
+	RelationRemasterOf      RelationType = "remaster-of"
+	RelationDlcOf           RelationType = "dlc-of"
+	RelationPartOfFranchise RelationType = "part-of-franchise"
+)
+
+// GameRelation links GameId to either another game (RelatedGameId) or, for
+// part-of-franchise relations, to a franchise (FranchiseSlug).
+// e.g. {GameId: "Portal 2", Type: sequel-of, RelatedGameId: "Portal"}
+type GameRelation struct {
+	Id            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GameId        primitive.ObjectID `json:"gameId" bson:"gameId"`
+	Type          RelationType       `json:"type" bson:"type" validate:"required,oneof=sequel-of remaster-of dlc-of part-of-franchise"`
+	RelatedGameId primitive.ObjectID `json:"relatedGameId,omitempty" bson:"relatedGameId,omitempty"`
+	FranchiseSlug string             `json:"franchiseSlug,omitempty" bson:"franchiseSlug,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// RelatedGame is a game seen from the other side of a GameRelation.
+// IsInverse is true when the requested game is the target of the relation,
+// e.g. the original game listing its sequel.
+type RelatedGame struct {
+	RelationId string       `json:"relationId"`
+	Type       RelationType `json:"type"`
+	IsInverse  bool         `json:"isInverse"`
+	Game       Game         `json:"game"`
+}
+
+type GameRelations struct {
+	Related    []RelatedGame `json:"related"`
+	Franchises []Franchise   `json:"franchises"`
+}
+
+type FranchiseTimeline struct {
+	Franchise Franchise `json:"franchise"`
+	Games     []Game    `json:"games"`
+}
+
+type MediaKind string
+
+const (
+	MediaCover      MediaKind = "cover"
+	MediaScreenshot MediaKind = "screenshot"
+	MediaTrailer    MediaKind = "trailer"
+)
+
+type MediaVariant struct {
+	Name        string `json:"name" bson:"name"`
+	Url         string `json:"url" bson:"url"`
+	Key         string `json:"-" bson:"key"`
+	ContentType string `json:"contentType" bson:"contentType"`
+	Width       int    `json:"width" bson:"width"`
+	Height      int    `json:"height" bson:"height"`
+	Size        int64  `json:"size" bson:"size"`
+}
+
+// GameMedia is an item of a game's gallery. Images keep the uploaded file as
+// the "original" variant next to the generated thumbnails and WebP copies,
+// trailers are plain links with no variants.
+type GameMedia struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"`
+	GameId    primitive.ObjectID `json:"gameId" bson:"gameId"`
+	Kind      MediaKind          `json:"kind" bson:"kind"`
+	Url       string             `json:"url" bson:"url"`
+	Variants  []MediaVariant     `json:"variants" bson:"variants"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// GameContent holds the editable fields of a Game.
+type GameContent struct {
+	Title       string               `json:"title" bson:"title"`
+	Summary     string               `json:"summary" bson:"summary"`
+	ReleaseDate int                  `json:"releaseDate" bson:"releaseDate"`
+	Developer   string               `json:"developer" bson:"developer"`
+	Publisher   string               `json:"publisher" bson:"publisher"`
+	Genres      []*EmbeddedGameGenre `json:"genres" bson:"genres"`
+	Platforms   []string             `json:"platforms" bson:"platforms"`
+	Image       string               `json:"image" bson:"image"`
+	// nil keeps the current rating in a patch, an empty one clears it
+	AgeRating          *AgeRating          `json:"ageRating,omitempty" bson:"ageRating,omitempty"`
+	ContentDescriptors []ContentDescriptor `json:"contentDescriptors,omitempty" bson:"contentDescriptors,omitempty"`
+}
+
+// GameRevision records a single edit of a game, Changes lists the fields that
+// differ between Before and After. RevertOf is set when the edit reverted an
+// earlier revision.
+type GameRevision struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"`
+	GameId    primitive.ObjectID `json:"gameId" bson:"gameId"`
+	Revision  int                `json:"revision" bson:"revision"`
+	AuthorId  string             `json:"authorId" bson:"authorId"`
+	RevertOf  int                `json:"revertOf,omitempty" bson:"revertOf,omitempty"`
+	Changes   []string           `json:"changes" bson:"changes"`
+	Before    GameContent        `json:"before" bson:"before"`
+	After     GameContent        `json:"after" bson:"after"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// RatingDrift is a game whose stored rating stats did not match its reviews,
+// Before being the stored stats and After the recalculated ones.
+type RatingDrift struct {
+	GameId string      `json:"gameId"`
+	Before RatingStats `json:"before"`
+	After  RatingStats `json:"after"`
+}
+
+// SimilarGame is a game recommended next to another one. Reasons lists what the
+// two have in common: "genres", "developer", "publisher" and "co-reviewed" when
+// the same users rated both highly. Game is only set when served.
+type SimilarGame struct {
+	GameId  primitive.ObjectID `json:"gameId" bson:"gameId"`
+	Score   float64            `json:"score" bson:"score"`
+	Reasons []string           `json:"reasons" bson:"reasons"`
+	Game    *Game              `json:"game,omitempty" bson:"-"`
+}
+
+// GameSimilarity holds the precomputed similar games of a game, best first.
+type GameSimilarity struct {
+	GameId     primitive.ObjectID `json:"gameId" bson:"_id"`
+	Similar    []SimilarGame      `json:"similar" bson:"similar"`
+	ComputedAt time.Time          `json:"computedAt" bson:"computedAt"`
+}
+
+// GameInteraction is what a user did with a game, a review or a vote on one of
+// its reviews. Preference goes from -1 (disliked) to 1 (loved), Source is
+// "review" or "vote".
+type GameInteraction struct {
+	UserId     string             `json:"userId" bson:"userId"`
+	Source     string             `json:"source" bson:"source"`
+	GameId     primitive.ObjectID `json:"gameId" bson:"gameId"`
+	Preference float64            `json:"preference" bson:"preference"`
+	At         time.Time          `json:"at" bson:"at"`
+}
+
+// RecommendedGame is a game recommended to a user. Reason is "collaborative"
+// when it comes from the games liked by similar players, "top-rated-in-genre"
+// or "top-rated" when the user has too few reviews for that.
+type RecommendedGame struct {
+	GameId primitive.ObjectID `json:"gameId" bson:"gameId"`
+	Score  float64            `json:"score" bson:"score"`
+	Reason string             `json:"reason" bson:"reason"`
+	Game   *Game              `json:"game,omitempty" bson:"-"`
+}
+
+// UserRecommendations holds the precomputed recommendations of a user, best first.
+type UserRecommendations struct {
+	UserId     string            `json:"userId" bson:"_id"`
+	Games      []RecommendedGame `json:"games" bson:"games"`
+	ComputedAt time.Time         `json:"computedAt" bson:"computedAt"`
+}
+
+// RecommendationEvaluation is the offline accuracy of the recommendations. The
+// most recent games each user liked are held out, the model is trained on the
+// rest and scored on how many held out games it recommends in its top K.
+type RecommendationEvaluation struct {
+	K            int     `json:"k"`
+	Users        int     `json:"users"`
+	HeldOut      int     `json:"heldOut"`
+	Hits         int     `json:"hits"`
+	PrecisionAtK float64 `json:"precisionAtK"`
+	RecallAtK    float64 `json:"recallAtK"`
+}
+
+// ChartEntry is a game ranked in a chart. Window is "day", "week" or "month", or
+// "all" for the top rated chart. Genres and Platforms are copied from the game
+// so the charts can be filtered on them.
+type ChartEntry struct {
+	Id         primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	Chart      ChartKind          `json:"chart" bson:"chart"`
+	Window     string             `json:"window" bson:"window"`
+	Rank       int                `json:"rank" bson:"rank"`
+	GameId     primitive.ObjectID `json:"gameId" bson:"gameId"`
+	Score      float64            `json:"score" bson:"score"`
+	Activity   GameActivity       `json:"activity" bson:"activity"`
+	Genres     []string           `json:"genres" bson:"genres"`
+	Platforms  []string           `json:"platforms" bson:"platforms"`
+	ComputedAt time.Time          `json:"computedAt" bson:"computedAt"`
+	Game       *Game              `json:"game,omitempty" bson:"-"`
+}
+
+type DuplicateStatus string
+
+const (
+	DuplicateOpen      DuplicateStatus = "open"
+	DuplicateConfirmed DuplicateStatus = "confirmed"
+	DuplicateDismissed DuplicateStatus = "dismissed"
+)
+
+func (s DuplicateStatus) isValid() bool {
+	return s == DuplicateOpen || s == DuplicateConfirmed || s == DuplicateDismissed
+}
+
+// DuplicateFlag marks Candidate as likely the same game as Game, which was
+// added after it. Reasons lists what matched: "title", "developer" and
+// "release-year". An admin confirms or dismisses it, Game and Candidate are only
+// set when served.
+type DuplicateFlag struct {
+	Id          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	GameId      primitive.ObjectID `json:"gameId" bson:"gameId"`
+	CandidateId primitive.ObjectID `json:"candidateId" bson:"candidateId"`
+	Score       float64            `json:"score" bson:"score"`
+	Reasons     []string           `json:"reasons" bson:"reasons"`
+	Status      DuplicateStatus    `json:"status" bson:"status"`
+	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
+	ReviewerId  string             `json:"reviewerId,omitempty" bson:"reviewerId,omitempty"`
+	ReviewedAt  time.Time          `json:"reviewedAt,omitempty" bson:"reviewedAt,omitempty"`
+	Game        *Game              `json:"game,omitempty" bson:"-"`
+	Candidate   *Game              `json:"candidate,omitempty" bson:"-"`
+}
+
+// EsrbRating is an ESRB rating category, RP being a rating pending.
+type EsrbRating string
+
+const (
+	EsrbEarlyChildhood EsrbRating = "EC"
+	EsrbEveryone       EsrbRating = "E"
+	EsrbEveryone10     EsrbRating = "E10+"
+	EsrbTeen           EsrbRating = "T"
+	EsrbMature         EsrbRating = "M"
+	EsrbAdultsOnly     EsrbRating = "AO"
+	EsrbRatingPending  EsrbRating = "RP"
+)
+
+// AgeRating is the rating of a game by the ESRB and by PEGI, Pegi being the
+// age of the PEGI label: 3, 7, 12, 16 or 18. Either can be left empty.
+type AgeRating struct {
+	Esrb EsrbRating `json:"esrb,omitempty" bson:"esrb,omitempty"`
+	Pegi int        `json:"pegi,omitempty" bson:"pegi,omitempty"`
+}
+
+// ContentDescriptor is a kind of content a game shows, such as "violence". The
+// descriptors users can hide are the keys of contentDescriptors.
+type ContentDescriptor string
+
+type ContentFilterMode string
+
+const (
+	// games above the content settings of the user are left out of the listings
+	ContentHide ContentFilterMode = "hide"
+	// games above the content settings of the user are listed marked restricted,
+	// for clients to blur them
+	ContentBlur ContentFilterMode = "blur"
+)
+
+func (m ContentFilterMode) isValid() bool {
+	return m == ContentHide || m == ContentBlur
+}
+
+// ContentPreferences are the content settings of a user. Games rated above the
+// age of the user, from their birthdate, or above MaxAge are restricted, as are
+// the games showing one of HiddenDescriptors. A birthdate can't be changed once
+// set, and users under 18 always get the games above their age hidden.
+type ContentPreferences struct {
+	UserId            string              `json:"-" bson:"_id"`
+	Birthdate         *time.Time          `json:"birthdate,omitempty" bson:"birthdate,omitempty"`
+	MaxAge            *int                `json:"maxAge,omitempty" bson:"maxAge,omitempty"`
+	HiddenDescriptors []ContentDescriptor `json:"hiddenDescriptors" bson:"hiddenDescriptors"`
+	Mode              ContentFilterMode   `json:"mode" bson:"mode"`
+	UpdatedAt         time.Time           `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ContentAccess tells how a game is shown to a user given their content settings.
+type ContentAccess string
+
+const (
+	ContentVisible ContentAccess = "visible"
+	ContentBlurred ContentAccess = "blurred"
+	ContentHidden  ContentAccess = "hidden"
+)
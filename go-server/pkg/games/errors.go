@@ -17,3 +17,51 @@ var ErrGameGenreSlugRequired = errors.New("game-genre-slug-required")
 var ErrGameAlreadyExists = errors.New("game-already-exists")
 
 var ErrGameIdRequired = errors.New("game-id-required")
+
+var ErrFranchiseAlreadyExists = errors.New("franchise-already-exists")
+
+var ErrFranchiseNotFound = errors.New("franchise-not-found")
+
+var ErrGameRelationAlreadyExists = errors.New("game-relation-already-exists")
+
+var ErrInvalidGameRelation = errors.New("invalid-game-relation")
+
+var ErrUnsupportedMediaType = errors.New("unsupported-media-type")
+
+var ErrMediaTooLarge = errors.New("media-too-large")
+
+var ErrInvalidMediaKind = errors.New("invalid-media-kind")
+
+var ErrInvalidTrailerUrl = errors.New("invalid-trailer-url")
+
+var ErrMediaNotFound = errors.New("media-not-found")
+
+var ErrGameRevisionNotFound = errors.New("game-revision-not-found")
+
+var ErrGameEditConflict = errors.New("game-edit-conflict")
+
+var ErrNotInTrash = errors.New("not-in-trash")
+
+var ErrInvalidGenreParent = errors.New("invalid-genre-parent")
+
+var ErrInvalidGenreMerge = errors.New("invalid-genre-merge")
+
+var ErrChartNotFound = errors.New("chart-not-found")
+
+var ErrGameSlugTaken = errors.New("game-slug-taken")
+
+var ErrUnknownExternalIdProvider = errors.New("unknown-external-id-provider")
+
+var ErrExternalIdConflict = errors.New("external-id-conflict")
+
+var ErrDuplicateFlagNotFound = errors.New("duplicate-flag-not-found")
+
+var ErrUnsupportedLocale = errors.New("unsupported-locale")
+
+var ErrTranslationNotFound = errors.New("translation-not-found")
+
+var ErrInvalidAgeRating = errors.New("invalid-age-rating")
+
+var ErrBirthdateAlreadySet = errors.New("birthdate-already-set")
+
+var ErrContentRestricted = errors.New("content-restricted")
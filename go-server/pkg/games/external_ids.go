@@ -0,0 +1,418 @@
+package games
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// ExternalIdProviders is the comma separated list of catalog providers games
+	// can carry an id of, e.g. "igdb,steam".
+	ExternalIdProviders = "EXTERNAL_ID_PROVIDERS"
+
+	defaultExternalIdProviders = "igdb,steam,rawg,mobygames,giantbomb"
+
+	// games scoring at least this against a new game are flagged as likely duplicates
+	duplicateThreshold = 0.75
+	// and their titles must be at least this similar
+	duplicateTitleThreshold = 0.8
+	// title similarity of an edition of a game, e.g. "Portal 2 Complete Edition"
+	editionSimilarity = 0.9
+
+	duplicateTitleWeight     = 0.6
+	duplicateDeveloperWeight = 0.2
+	duplicateYearWeight      = 0.2
+)
+
+// words left out when comparing titles, "The Witcher 3" matches "Witcher 3"
+var titleStopWords = map[string]bool{
+	"the": true,
+	"a":   true,
+	"an":  true,
+}
+
+func getExternalIdProviders() []string {
+	value := os.Getenv(ExternalIdProviders)
+
+	if strings.TrimSpace(value) == "" {
+		value = defaultExternalIdProviders
+	}
+
+	var providers []string
+
+	for _, provider := range strings.Split(value, ",") {
+		provider = getGameSlug(provider)
+
+		if provider != "game" {
+			providers = append(providers, provider)
+		}
+	}
+
+	return providers
+}
+
+// normalizeExternalIds lowercases the providers and drops empty ids. Providers
+// that are not configured are rejected, they have no unique index.
+func (g *Service) normalizeExternalIds(externalIds map[string]string) (map[string]string, error) {
+	if len(externalIds) == 0 {
+		return nil, nil
+	}
+
+	normalized := make(map[string]string, len(externalIds))
+
+	for provider, id := range externalIds {
+		provider = strings.ToLower(strings.TrimSpace(provider))
+		id = strings.TrimSpace(id)
+
+		if !g.externalIdProviders[provider] {
+			return nil, ErrUnknownExternalIdProvider
+		}
+
+		if id != "" {
+			normalized[provider] = id
+		}
+	}
+
+	return normalized, nil
+}
+
+// findExistingGame returns the game the new one duplicates, one sharing an
+// external id with it or having the same title and release year.
+func (g *Service) findExistingGame(ctx context.Context, game *Game) (*Game, error) {
+	if len(game.ExternalIds) > 0 {
+		matches, err := g.repository.getGamesByExternalIds(ctx, game.ExternalIds)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(matches) > 1 {
+			return nil, ErrExternalIdConflict
+		}
+
+		if len(matches) == 1 {
+			return &matches[0], nil
+		}
+	}
+
+	return g.repository.getGameByTitle(ctx, game.Title, game.ReleaseDate)
+}
+
+func (g *Service) GetGameByExternalId(ctx context.Context, provider string, externalId string) (*Game, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+
+	if !g.externalIdProviders[provider] {
+		return nil, ErrUnknownExternalIdProvider
+	}
+
+	games, err := g.repository.getGamesByExternalIds(ctx, map[string]string{provider: strings.TrimSpace(externalId)})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(games) == 0 || games[0].IsDeleted {
+		return nil, ErrNotFound
+	}
+
+	content, err := g.contentFilter(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !content.apply(&games[0]) {
+		return nil, ErrContentRestricted
+	}
+
+	return &games[0], nil
+}
+
+// UpsertGameByExternalId adds the game a provider knows by externalId, or
+// updates the game already matching it. A game matching another of its external
+// ids, or its title and release year, is taken as the same game and gets the
+// missing ids attached, so ingesting the same data twice changes nothing.
+// created tells which of the two happened.
+func (g *Service) UpsertGameByExternalId(ctx context.Context, provider string, externalId string, game *Game) (created bool, err error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	externalId = strings.TrimSpace(externalId)
+
+	if externalId == "" {
+		return false, ErrBadRequest
+	}
+
+	if !game.AgeRating.isValid() || !validContentDescriptors(game.ContentDescriptors) {
+		return false, ErrInvalidAgeRating
+	}
+
+	if game.ExternalIds == nil {
+		game.ExternalIds = map[string]string{}
+	}
+
+	game.ExternalIds[provider] = externalId
+
+	if game.ExternalIds, err = g.normalizeExternalIds(game.ExternalIds); err != nil {
+		return false, err
+	}
+
+	existing, err := g.findExistingGame(ctx, game)
+
+	if err == ErrNotFound {
+		err = g.AddGame(ctx, game)
+
+		if err != ErrGameAlreadyExists {
+			return err == nil, err
+		}
+
+		// added concurrently by another ingestion
+		existing, err = g.findExistingGame(ctx, game)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if existing.IsDeleted {
+		return false, ErrGameAlreadyExists
+	}
+
+	missing := map[string]string{}
+
+	for provider, id := range game.ExternalIds {
+		current, ok := existing.ExternalIds[provider]
+
+		if ok && current != id {
+			return false, ErrExternalIdConflict
+		}
+
+		if !ok {
+			missing[provider] = id
+		}
+	}
+
+	if len(missing) > 0 {
+		if err = g.repository.setGameExternalIds(ctx, existing.Id, missing); err != nil {
+			return false, err
+		}
+
+		g.invalidateGames(ctx)
+	}
+
+	current := existing.content()
+	updated := mergeGameContent(current, game.content())
+
+	if err = g.saveGameRevision(ctx, existing, current, updated, 0); err != nil {
+		return false, err
+	}
+
+	game.Id = existing.Id
+	game.Slug = existing.Slug
+
+	return false, nil
+}
+
+// flagDuplicates records the games that look like the new one for an admin to
+// review, ingestion goes on either way.
+func (g *Service) flagDuplicates(ctx context.Context, game *Game) {
+
+	candidates, err := g.repository.getDuplicateCandidates(ctx, game)
+
+	if err != nil {
+		log.Println("could not look for duplicates of game", game.Id.Hex(), err)
+		return
+	}
+
+	var flags []DuplicateFlag
+
+	for i := range candidates {
+		if candidates[i].Id == game.Id {
+			continue
+		}
+
+		score, reasons := duplicateScore(game, &candidates[i])
+
+		if score < duplicateThreshold {
+			continue
+		}
+
+		flags = append(flags, DuplicateFlag{
+			GameId:      game.Id,
+			CandidateId: candidates[i].Id,
+			Score:       score,
+			Reasons:     reasons,
+			Status:      DuplicateOpen,
+			CreatedAt:   time.Now(),
+		})
+	}
+
+	if len(flags) == 0 {
+		return
+	}
+
+	if err = g.repository.saveDuplicateFlags(ctx, flags); err != nil {
+		log.Println("could not flag duplicates of game", game.Id.Hex(), err)
+	}
+}
+
+func (g *Service) GetDuplicateFlags(ctx context.Context, status DuplicateStatus, pagination *Pagination) (*PaginatedResponse[DuplicateFlag], error) {
+	if !status.isValid() {
+		return nil, ErrBadRequest
+	}
+
+	flags, err := g.repository.getDuplicateFlags(ctx, status, pagination)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, 2*len(flags.Data))
+
+	for _, flag := range flags.Data {
+		ids = append(ids, flag.GameId, flag.CandidateId)
+	}
+
+	games, err := g.repository.getGamesByIds(ctx, ids)
+
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[primitive.ObjectID]*Game, len(games))
+
+	for i := range games {
+		byId[games[i].Id] = &games[i]
+	}
+
+	for i := range flags.Data {
+		flags.Data[i].Game = byId[flags.Data[i].GameId]
+		flags.Data[i].Candidate = byId[flags.Data[i].CandidateId]
+	}
+
+	return flags, nil
+}
+
+// ReviewDuplicateFlag records the admin decision on a flag, confirmed when the
+// two games are the same and dismissed when they are not.
+func (g *Service) ReviewDuplicateFlag(ctx context.Context, flagId string, status DuplicateStatus) error {
+	if !status.isValid() {
+		return ErrBadRequest
+	}
+
+	id, err := primitive.ObjectIDFromHex(flagId)
+
+	if err != nil {
+		return ErrDuplicateFlagNotFound
+	}
+
+	reviewerId, _ := ctx.Value("userId").(string)
+
+	return g.repository.updateDuplicateFlag(ctx, id, status, reviewerId)
+}
+
+// duplicateScore tells how likely candidate is the same game as game, from the
+// similarity of their titles, their developer and their release years.
+func duplicateScore(game *Game, candidate *Game) (float64, []string) {
+	var reasons []string
+
+	title := titleSimilarity(game.Title, candidate.Title)
+
+	if title < duplicateTitleThreshold {
+		return 0, nil
+	}
+
+	reasons = append(reasons, "title")
+	score := duplicateTitleWeight * title
+
+	developer := strings.TrimSpace(game.Developer)
+
+	if developer != "" && strings.EqualFold(developer, strings.TrimSpace(candidate.Developer)) {
+		score += duplicateDeveloperWeight
+		reasons = append(reasons, "developer")
+	}
+
+	if game.ReleaseDate > 0 && candidate.ReleaseDate > 0 {
+		years := game.ReleaseDate - candidate.ReleaseDate
+
+		if years == 0 {
+			score += duplicateYearWeight
+			reasons = append(reasons, "release-year")
+		} else if years == 1 || years == -1 {
+			score += duplicateYearWeight / 2
+			reasons = append(reasons, "release-year")
+		}
+	}
+
+	return score, reasons
+}
+
+// titleSimilarity is 1 minus the edit distance between the normalized titles
+// over the length of the longest. A title starting with the whole other one,
+// like an edition of the game, is similar enough to be flagged.
+func titleSimilarity(a string, b string) float64 {
+	a, b = normalizeTitle(a), normalizeTitle(b)
+
+	longest := len([]rune(a))
+
+	if l := len([]rune(b)); l > longest {
+		longest = l
+	}
+
+	if longest == 0 {
+		return 0
+	}
+
+	similarity := 1 - float64(editDistance(a, b))/float64(longest)
+
+	if a != "" && b != "" && (strings.HasPrefix(a, b+" ") || strings.HasPrefix(b, a+" ")) {
+		similarity = math.Max(similarity, editionSimilarity)
+	}
+
+	return similarity
+}
+
+func normalizeTitle(title string) string {
+	var words []string
+
+	for _, word := range strings.Split(getGameSlug(title), "-") {
+		if !titleStopWords[word] {
+			words = append(words, word)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
@@ -4,16 +4,77 @@ import (
 	"context"
 	"github.com/gofiber/fiber/v2"
 	auth "go-server/pkg/authentication"
+	"go-server/pkg/storage"
 	"go.mongodb.org/mongo-driver/mongo"
+	"os"
+	"strconv"
+	"time"
 )
 
-func Register(mongoClient *mongo.Client, ctx context.Context, app fiber.Router, authNeeds *auth.AuthNeeds) error {
+// Register sets up the game routes and background jobs. The service is returned
+// for the other packages to enforce the content settings of users with.
+func Register(mongoClient *mongo.Client, ctx context.Context, app fiber.Router, authNeeds *auth.AuthNeeds) (*Service, error) {
 
 	gameRepo := NewGameRepositoryImpl(mongoClient)
 
-	gameService := NewGameService(gameRepo)
+	externalIdProviders := getExternalIdProviders()
+
+	if err := gameRepo.ensureIndexes(ctx, externalIdProviders); err != nil {
+		return nil, err
+	}
+
+	apiVersion := ctx.Value("apiVersion").(string)
+	mediaConfig := getMediaConfig("/api" + apiVersion + "/games/media/")
+	blobStorage := storage.NewFileSystemStorage(mediaConfig.StoragePath)
+
+	gameService := NewGameService(gameRepo, blobStorage, mediaConfig, getTrashRetention(), GetRatingPrior(), getResponseCache(), externalIdProviders, getLocaleConfig())
+
+	if _, err := gameService.BackfillGameSlugs(ctx); err != nil {
+		return nil, err
+	}
+
+	go gameService.runTrashPurger(ctx, time.Hour)
+
+	go gameService.runRatingReconciler(ctx, getRatingReconcileInterval())
+
+	go gameService.runSimilarGamesJob(ctx, getSimilarGamesInterval())
+
+	go gameService.runUserRecommendationsJob(ctx, getUserRecommendationsInterval())
+
+	go gameService.runChartsJob(ctx, getChartsInterval())
 
 	gameHandler := NewGameHandler(gameService)
 
-	return router(ctx, app, gameHandler, authNeeds.AuthMiddleware)
+	return gameService, router(ctx, app, gameHandler, authNeeds.AuthMiddleware)
+}
+
+const (
+	// TrashRetentionDays is how many days deleted games and genres are kept before being purged.
+	TrashRetentionDays = "TRASH_RETENTION_DAYS"
+
+	// RatingReconcileIntervalMinutes is how often the rating stats of games are checked against their reviews.
+	RatingReconcileIntervalMinutes = "RATING_RECONCILE_INTERVAL_MINUTES"
+
+	defaultTrashRetentionDays             = 30
+	defaultRatingReconcileIntervalMinutes = 60
+)
+
+func getTrashRetention() time.Duration {
+	days, err := strconv.Atoi(os.Getenv(TrashRetentionDays))
+
+	if err != nil || days < 1 {
+		days = defaultTrashRetentionDays
+	}
+
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func getRatingReconcileInterval() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(RatingReconcileIntervalMinutes))
+
+	if err != nil || minutes < 1 {
+		minutes = defaultRatingReconcileIntervalMinutes
+	}
+
+	return time.Duration(minutes) * time.Minute
 }
@@ -3,8 +3,12 @@ package games
 import (
 	"context"
 	"github.com/gofiber/fiber/v2"
+	"go-server/pkg/cache"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
 	"log"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -33,6 +37,7 @@ func (h *GameHandler) AddGenre(ctx context.Context, c *fiber.Ctx) error {
 		Title:     req.Title,
 		Slug:      getSlug(req.Title),
 		Desc:      req.Desc,
+		Parent:    strings.TrimSpace(req.Parent),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -51,6 +56,8 @@ type EditGenreRequest struct {
 	Title string `json:"title"`
 	Slug  string `json:"slug"`
 	Desc  string `json:"desc"`
+	// omit to keep the current parent, an empty string makes the genre top level
+	Parent *string `json:"parent,omitempty"`
 }
 
 func (h *GameHandler) EditGenre(ctx context.Context, c *fiber.Ctx) error {
@@ -68,7 +75,7 @@ func (h *GameHandler) EditGenre(ctx context.Context, c *fiber.Ctx) error {
 		Desc:  req.Desc,
 	}
 
-	err = h.service.EditGameGenre(ctx, gameGenre)
+	err = h.service.EditGameGenre(ctx, gameGenre, req.Parent)
 
 	if err != nil {
 		return EditGenreErrorResponse(c, err)
@@ -95,13 +102,21 @@ func (h *GameHandler) GetGenres(ctx context.Context, c *fiber.Ctx) error {
 		req.Limit = 100
 	}
 
-	genres, err := h.service.GetAllGenres(ctx, &req)
+	locale := h.resolveLocale(c)
 
-	if err != nil {
-		return GetGenresErrorResponse(c, err)
-	}
+	return h.sendCacheable(ctx, c, cacheGenres, locale, func() (*cache.Entry, error) {
+		genres, err := h.service.GetAllGenres(ctx, &req)
+
+		if err != nil {
+			return nil, err
+		}
 
-	return GetGenresSuccessResp(c, genres)
+		for i := range genres.Data {
+			genres.Data[i].localize(h.service.locales, locale)
+		}
+
+		return GetGenresCacheEntry(genres)
+	}, GetGenresErrorResponse)
 
 }
 
@@ -114,13 +129,19 @@ func (h *GameHandler) GetGenre(ctx context.Context, c *fiber.Ctx) error {
 		return GetGenreErrorResponse(c, ErrGameGenreSlugRequired)
 	}
 
-	gameGenre, err := h.service.GetGameGenre(ctx, slug)
+	locale := h.resolveLocale(c)
 
-	if err != nil {
-		return GetGenreErrorResponse(c, err)
-	}
+	return h.sendCacheable(ctx, c, cacheGenre, locale, func() (*cache.Entry, error) {
+		gameGenre, err := h.service.GetGameGenre(ctx, slug)
 
-	return GetGenreSuccessResp(c, gameGenre)
+		if err != nil {
+			return nil, err
+		}
+
+		gameGenre.localize(h.service.locales, locale)
+
+		return GetGenreCacheEntry(gameGenre)
+	}, GetGenreErrorResponse)
 }
 
 func (h *GameHandler) DeleteGenre(ctx context.Context, c *fiber.Ctx) error {
@@ -142,6 +163,54 @@ func (h *GameHandler) DeleteGenre(ctx context.Context, c *fiber.Ctx) error {
 
 }
 
+func (h *GameHandler) RenameGenre(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return RenameGenreErrorResponse(c, ErrGameGenreSlugRequired)
+	}
+
+	var req RenameGenreRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil || strings.TrimSpace(req.Title) == "" {
+		return RenameGenreErrorResponse(c, ErrBadRequest)
+	}
+
+	genre, err := h.service.RenameGameGenre(ctx, slug, strings.TrimSpace(req.Title))
+
+	if err != nil {
+		return RenameGenreErrorResponse(c, err)
+	}
+
+	return RenameGenreSuccessResp(c, genre)
+}
+
+func (h *GameHandler) MergeGenre(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return MergeGenreErrorResponse(c, ErrGameGenreSlugRequired)
+	}
+
+	var req MergeGenreRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil || strings.TrimSpace(req.Into) == "" {
+		return MergeGenreErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.service.MergeGameGenres(ctx, slug, strings.TrimSpace(req.Into))
+
+	if err != nil {
+		return MergeGenreErrorResponse(c, err)
+	}
+
+	return MergeGenreSuccessResp(c)
+}
+
 func (h *GameHandler) AddGame(ctx context.Context, c *fiber.Ctx) error {
 	var req AddGameRequest
 
@@ -158,8 +227,15 @@ func (h *GameHandler) AddGame(ctx context.Context, c *fiber.Ctx) error {
 		Developer:   req.Developer,
 		Publisher:   req.Publisher,
 		Genres:      req.Genres,
+		Platforms:   normalizePlatforms(req.Platforms),
+		Image:       req.Image,
+		ExternalIds: req.ExternalIds,
 		Rating:      RatingStats{},
 		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+
+		AgeRating:          req.AgeRating,
+		ContentDescriptors: req.ContentDescriptors,
 	}
 
 	err = h.service.AddGame(ctx, game)
@@ -168,7 +244,7 @@ func (h *GameHandler) AddGame(ctx context.Context, c *fiber.Ctx) error {
 		return AddGameErrorResponse(c, err)
 	}
 
-	return AddGameSuccessResp(c, game.Id.Hex())
+	return AddGameSuccessResp(c, game)
 
 }
 
@@ -179,13 +255,30 @@ func (h *GameHandler) GetGame(ctx context.Context, c *fiber.Ctx) error {
 		return GetGameErrorResponse(c, ErrGameIdRequired)
 	}
 
-	game, err := h.service.GetGame(ctx, id)
+	locale := h.resolveLocale(c)
 
-	if err != nil {
-		return GetGameErrorResponse(c, err)
-	}
+	return h.sendCacheable(ctx, c, cacheGame, locale, func() (*cache.Entry, error) {
+		game, err := h.service.GetGame(ctx, id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// an old slug, send the client to the current one
+		if id != game.Slug && id != game.Id.Hex() {
+			location := strings.TrimSuffix(c.Path(), id) + game.Slug
+
+			if query := string(c.Request().URI().QueryString()); query != "" {
+				location += "?" + query
+			}
+
+			return newRedirectEntry(location), nil
+		}
+
+		game.localize(h.service.locales, locale)
 
-	return GetGameSuccessResp(c, game)
+		return GetGameCacheEntry(game)
+	}, GetGameErrorResponse)
 
 }
 
@@ -196,6 +289,12 @@ type GetGamesQueries struct {
 	Developer    string `json:"developer,omitempty"`
 	Publisher    string `json:"publisher,omitempty"`
 	Genre        string `json:"genre,omitempty"`
+	Platform     string `json:"platform,omitempty"`
+	// newest, rating (weighted), average, reviews or a sub-score
+	SortBy string `json:"sortBy,omitempty"`
+	// games with reviews scoring the sub-score, averaging at least MinSubScore if set
+	SubScore    string  `json:"subScore,omitempty"`
+	MinSubScore float64 `json:"minSubScore,omitempty"`
 }
 
 func (h *GameHandler) GetGames(ctx context.Context, c *fiber.Ctx) error {
@@ -219,6 +318,7 @@ func (h *GameHandler) GetGames(ctx context.Context, c *fiber.Ctx) error {
 	pagination := Pagination{
 		Limit:  req.Limit,
 		Offset: req.Offset,
+		SortBy: strings.TrimSpace(req.SortBy),
 	}
 
 	log.Println("getting in query: ", req)
@@ -265,15 +365,39 @@ func (h *GameHandler) GetGames(ctx context.Context, c *fiber.Ctx) error {
 
 	log.Println("getting in query after g: ", filters)
 
-	pagination.QueryFilters = filters
+	if platform := strings.ToLower(strings.TrimSpace(req.Platform)); platform != "" {
+		filters["platforms"] = platform
+	}
 
-	games, err := h.service.GetAllGames(ctx, &pagination)
+	if subScore := strings.ToLower(strings.TrimSpace(req.SubScore)); subScore != "" {
+		if !IsSubScoreDimension(subScore) {
+			return GetGamesErrorResponse(c, ErrBadRequest)
+		}
 
-	if err != nil {
-		return GetGamesErrorResponse(c, err)
+		if req.MinSubScore > 0 {
+			filters["rating.subScores."+subScore+".average"] = bson.D{{"$gte", req.MinSubScore}}
+		} else {
+			filters["rating.subScores."+subScore+".count"] = bson.D{{"$gt", 0}}
+		}
 	}
 
-	return GetGamesSuccessResp(c, games)
+	pagination.QueryFilters = filters
+
+	locale := h.resolveLocale(c)
+
+	return h.sendCacheable(ctx, c, cacheGames, locale, func() (*cache.Entry, error) {
+		games, err := h.service.GetAllGames(ctx, &pagination)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range games.Data {
+			games.Data[i].localize(h.service.locales, locale)
+		}
+
+		return GetGamesCacheEntry(games)
+	}, GetGamesErrorResponse)
 }
 
 func (h *GameHandler) UpdateGame(ctx context.Context, c *fiber.Ctx) error {
@@ -291,22 +415,21 @@ func (h *GameHandler) UpdateGame(ctx context.Context, c *fiber.Ctx) error {
 		return UpdateGameErrorResp(c, ErrBadRequest)
 	}
 
-	id, err := primitive.ObjectIDFromHex(idString)
-	if err != nil {
-		return UpdateGameErrorResp(c, ErrBadRequest)
-	}
-
-	game := &Game{
-		Id:          id,
+	patch := &GameContent{
 		Title:       req.Title,
 		Summary:     req.Summary,
 		ReleaseDate: req.ReleaseDate,
 		Developer:   req.Developer,
 		Publisher:   req.Publisher,
 		Genres:      req.Genres,
+		Platforms:   req.Platforms,
+		Image:       req.Image,
+
+		AgeRating:          req.AgeRating,
+		ContentDescriptors: req.ContentDescriptors,
 	}
 
-	err = h.service.UpdateGame(ctx, game)
+	err = h.service.UpdateGame(ctx, idString, patch)
 
 	if err != nil {
 		return UpdateGameErrorResp(c, err)
@@ -316,6 +439,64 @@ func (h *GameHandler) UpdateGame(ctx context.Context, c *fiber.Ctx) error {
 
 }
 
+func (h *GameHandler) GetGameRevisions(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return GetGameRevisionsErrorResponse(c, ErrGameIdRequired)
+	}
+
+	var req Pagination
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return GetGameRevisionsErrorResponse(c, ErrBadRequest)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	revisions, err := h.service.GetGameRevisions(ctx, id, &req)
+
+	if err != nil {
+		return GetGameRevisionsErrorResponse(c, err)
+	}
+
+	return GetGameRevisionsSuccessResp(c, revisions)
+}
+
+func (h *GameHandler) RevertGameRevision(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return RevertGameRevisionErrorResponse(c, ErrGameIdRequired)
+	}
+
+	revision, err := strconv.Atoi(c.Params("revision"))
+
+	if err != nil || revision < 1 {
+		return RevertGameRevisionErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.service.RevertGameRevision(ctx, id, revision)
+
+	if err != nil {
+		return RevertGameRevisionErrorResponse(c, err)
+	}
+
+	return RevertGameRevisionSuccessResp(c)
+}
+
 func (h *GameHandler) DeleteGame(ctx context.Context, c *fiber.Ctx) error {
 	id := c.Params("id")
 
@@ -332,8 +513,811 @@ func (h *GameHandler) DeleteGame(ctx context.Context, c *fiber.Ctx) error {
 	return DeleteGameSuccessResp(c)
 }
 
+func (h *GameHandler) RecalculateRatings(ctx context.Context, c *fiber.Ctx) error {
+
+	drifts, err := h.service.RecalculateRatings(ctx)
+
+	if err != nil {
+		return RecalculateRatingsErrorResponse(c, err)
+	}
+
+	return RecalculateRatingsSuccessResp(c, drifts)
+}
+
 func getSlug(title string) string {
 	lowercase := strings.ToLower(title)
 
 	return strings.ReplaceAll(lowercase, " ", "-")
 }
+
+func (h *GameHandler) AddFranchise(ctx context.Context, c *fiber.Ctx) error {
+	var req AddFranchiseRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return AddFranchiseErrorResponse(c, ErrBadRequest)
+	}
+
+	franchise := &Franchise{
+		Title:     req.Title,
+		Slug:      getSlug(req.Title),
+		Desc:      req.Desc,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	err = h.service.AddFranchise(ctx, franchise)
+
+	if err != nil {
+		return AddFranchiseErrorResponse(c, err)
+	}
+
+	return AddFranchiseSuccessResp(c, franchise.Slug)
+}
+
+func (h *GameHandler) GetFranchiseTimeline(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return GetFranchiseTimelineErrorResponse(c, ErrBadRequest)
+	}
+
+	timeline, err := h.service.GetFranchiseTimeline(ctx, slug)
+
+	if err != nil {
+		return GetFranchiseTimelineErrorResponse(c, err)
+	}
+
+	locale := h.resolveLocale(c)
+
+	for i := range timeline.Games {
+		timeline.Games[i].localize(h.service.locales, locale)
+	}
+
+	return GetFranchiseTimelineSuccessResp(c, timeline)
+}
+
+func (h *GameHandler) DeleteFranchise(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return DeleteFranchiseErrorResponse(c, ErrBadRequest)
+	}
+
+	err := h.service.DeleteFranchise(ctx, slug)
+
+	if err != nil {
+		return DeleteFranchiseErrorResponse(c, err)
+	}
+
+	return DeleteFranchiseSuccessResp(c)
+}
+
+func (h *GameHandler) GetRelatedGames(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return GetRelatedGamesErrorResponse(c, ErrGameIdRequired)
+	}
+
+	relations, err := h.service.GetGameRelations(ctx, id)
+
+	if err != nil {
+		return GetRelatedGamesErrorResponse(c, err)
+	}
+
+	locale := h.resolveLocale(c)
+
+	for i := range relations.Related {
+		relations.Related[i].Game.localize(h.service.locales, locale)
+	}
+
+	return GetRelatedGamesSuccessResp(c, relations)
+}
+
+func (h *GameHandler) GetSimilarGames(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return GetSimilarGamesErrorResponse(c, ErrGameIdRequired)
+	}
+
+	similarGames, err := h.service.GetSimilarGames(ctx, id, c.QueryInt("limit", 10))
+
+	if err != nil {
+		return GetSimilarGamesErrorResponse(c, err)
+	}
+
+	locale := h.resolveLocale(c)
+
+	for i := range similarGames {
+		if similarGames[i].Game != nil {
+			similarGames[i].Game.localize(h.service.locales, locale)
+		}
+	}
+
+	return GetSimilarGamesSuccessResp(c, similarGames)
+}
+
+func (h *GameHandler) GetRecommendedGames(ctx context.Context, c *fiber.Ctx) error {
+	recommended, err := h.service.GetRecommendedGames(ctx, c.QueryInt("limit", 10))
+
+	if err != nil {
+		return GetRecommendedGamesErrorResponse(c, err)
+	}
+
+	locale := h.resolveLocale(c)
+
+	for i := range recommended {
+		if recommended[i].Game != nil {
+			recommended[i].Game.localize(h.service.locales, locale)
+		}
+	}
+
+	return GetRecommendedGamesSuccessResp(c, recommended)
+}
+
+func (h *GameHandler) EvaluateRecommendations(ctx context.Context, c *fiber.Ctx) error {
+	evaluation, err := h.service.EvaluateRecommendations(ctx, c.QueryInt("k", 10))
+
+	if err != nil {
+		return EvaluateRecommendationsErrorResponse(c, err)
+	}
+
+	return EvaluateRecommendationsSuccessResp(c, evaluation)
+}
+
+type GetChartQueries struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// day, week or month
+	Window   string `json:"window,omitempty"`
+	Genre    string `json:"genre,omitempty"`
+	Platform string `json:"platform,omitempty"`
+}
+
+func (h *GameHandler) GetChart(ctx context.Context, c *fiber.Ctx) error {
+	var req GetChartQueries
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return GetChartErrorResponse(c, ErrBadRequest)
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	if req.Limit > chartSize {
+		req.Limit = chartSize
+	}
+
+	pagination := Pagination{
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	chart, err := h.service.GetChart(ctx, ChartKind(c.Params("chart")), req.Window, req.Genre, req.Platform, &pagination)
+
+	if err != nil {
+		return GetChartErrorResponse(c, err)
+	}
+
+	locale := h.resolveLocale(c)
+
+	for i := range chart.Data {
+		if chart.Data[i].Game != nil {
+			chart.Data[i].Game.localize(h.service.locales, locale)
+		}
+	}
+
+	return GetChartSuccessResp(c, chart)
+}
+
+func (h *GameHandler) AddGameRelation(ctx context.Context, c *fiber.Ctx) error {
+	idString := c.Params("id")
+
+	if idString == "" {
+		return AddGameRelationErrorResponse(c, ErrGameIdRequired)
+	}
+
+	id, err := primitive.ObjectIDFromHex(idString)
+	if err != nil {
+		return AddGameRelationErrorResponse(c, ErrBadRequest)
+	}
+
+	var req AddGameRelationRequest
+
+	err = c.BodyParser(&req)
+
+	if err != nil {
+		return AddGameRelationErrorResponse(c, ErrBadRequest)
+	}
+
+	relation := &GameRelation{
+		GameId:        id,
+		Type:          req.Type,
+		FranchiseSlug: strings.TrimSpace(req.FranchiseSlug),
+	}
+
+	if req.RelatedGameId != "" {
+		relatedId, err := primitive.ObjectIDFromHex(req.RelatedGameId)
+		if err != nil {
+			return AddGameRelationErrorResponse(c, ErrBadRequest)
+		}
+		relation.RelatedGameId = relatedId
+	}
+
+	err = h.service.AddGameRelation(ctx, relation)
+
+	if err != nil {
+		return AddGameRelationErrorResponse(c, err)
+	}
+
+	return AddGameRelationSuccessResp(c, relation.Id.Hex())
+}
+
+func (h *GameHandler) DeleteGameRelation(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+	relationId := c.Params("relationId")
+
+	if id == "" {
+		return DeleteGameRelationErrorResponse(c, ErrGameIdRequired)
+	}
+
+	err := h.service.DeleteGameRelation(ctx, id, relationId)
+
+	if err != nil {
+		return DeleteGameRelationErrorResponse(c, err)
+	}
+
+	return DeleteGameRelationSuccessResp(c)
+}
+
+func (h *GameHandler) AddGameMedia(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return AddGameMediaErrorResponse(c, ErrGameIdRequired)
+	}
+
+	kind := MediaKind(strings.TrimSpace(c.FormValue("kind")))
+
+	if kind == MediaTrailer {
+		media, err := h.service.AddGameTrailer(ctx, id, c.FormValue("url"))
+
+		if err != nil {
+			return AddGameMediaErrorResponse(c, err)
+		}
+
+		return AddGameMediaSuccessResp(c, media)
+	}
+
+	fileHeader, err := c.FormFile("file")
+
+	if err != nil {
+		return AddGameMediaErrorResponse(c, ErrBadRequest)
+	}
+
+	if fileHeader.Size > h.service.media.MaxUploadBytes {
+		return AddGameMediaErrorResponse(c, ErrMediaTooLarge)
+	}
+
+	file, err := fileHeader.Open()
+
+	if err != nil {
+		return AddGameMediaErrorResponse(c, ErrBadRequest)
+	}
+
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.service.media.MaxUploadBytes+1))
+
+	if err != nil {
+		return AddGameMediaErrorResponse(c, ErrBadRequest)
+	}
+
+	media, err := h.service.AddGameImage(ctx, id, kind, data)
+
+	if err != nil {
+		return AddGameMediaErrorResponse(c, err)
+	}
+
+	return AddGameMediaSuccessResp(c, media)
+}
+
+func (h *GameHandler) GetGameMedia(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return GetGameMediaErrorResponse(c, ErrGameIdRequired)
+	}
+
+	media, err := h.service.GetGameMedia(ctx, id)
+
+	if err != nil {
+		return GetGameMediaErrorResponse(c, err)
+	}
+
+	return GetGameMediaSuccessResp(c, media)
+}
+
+func (h *GameHandler) DeleteGameMedia(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return DeleteGameMediaErrorResponse(c, ErrGameIdRequired)
+	}
+
+	err := h.service.DeleteGameMedia(ctx, id, c.Params("mediaId"))
+
+	if err != nil {
+		return DeleteGameMediaErrorResponse(c, err)
+	}
+
+	return DeleteGameMediaSuccessResp(c)
+}
+
+func (h *GameHandler) ServeMedia(ctx context.Context, c *fiber.Ctx) error {
+	key := c.Params("*")
+
+	reader, info, err := h.service.GetMediaBlob(ctx, key)
+
+	if err != nil {
+		return ServeMediaErrorResponse(c, err)
+	}
+
+	c.Set(fiber.HeaderCacheControl, mediaCacheControl)
+	c.Set(fiber.HeaderETag, info.ETag)
+	c.Set(fiber.HeaderLastModified, info.LastModified.UTC().Format(http.TimeFormat))
+
+	if c.Get(fiber.HeaderIfNoneMatch) == info.ETag {
+		_ = reader.Close()
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, info.ContentType)
+
+	// the stream is closed by fasthttp once it has been sent
+	return c.SendStream(reader, int(info.Size))
+}
+
+func (h *GameHandler) GetTrash(ctx context.Context, c *fiber.Ctx, genres bool) error {
+	var req Pagination
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return GetTrashErrorResponse(c, ErrBadRequest)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	if genres {
+		trashedGenres, err := h.service.GetTrashedGenres(ctx, &req)
+
+		if err != nil {
+			return GetTrashErrorResponse(c, err)
+		}
+
+		return GetTrashSuccessResp(c, trashedGenres)
+	}
+
+	trashedGames, err := h.service.GetTrashedGames(ctx, &req)
+
+	if err != nil {
+		return GetTrashErrorResponse(c, err)
+	}
+
+	return GetTrashSuccessResp(c, trashedGames)
+}
+
+func (h *GameHandler) RestoreGame(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return RestoreGameErrorResponse(c, ErrGameIdRequired)
+	}
+
+	err := h.service.RestoreGame(ctx, id)
+
+	if err != nil {
+		return RestoreGameErrorResponse(c, err)
+	}
+
+	return RestoreGameSuccessResp(c)
+}
+
+func (h *GameHandler) PurgeGame(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return PurgeGameErrorResponse(c, ErrGameIdRequired)
+	}
+
+	err := h.service.PurgeGame(ctx, id)
+
+	if err != nil {
+		return PurgeGameErrorResponse(c, err)
+	}
+
+	return PurgeGameSuccessResp(c)
+}
+
+func (h *GameHandler) RestoreGenre(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return RestoreGenreErrorResponse(c, ErrGameGenreSlugRequired)
+	}
+
+	err := h.service.RestoreGameGenre(ctx, slug)
+
+	if err != nil {
+		return RestoreGenreErrorResponse(c, err)
+	}
+
+	return RestoreGenreSuccessResp(c)
+}
+
+func (h *GameHandler) PurgeGenre(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return PurgeGenreErrorResponse(c, ErrGameGenreSlugRequired)
+	}
+
+	err := h.service.PurgeGameGenre(ctx, slug)
+
+	if err != nil {
+		return PurgeGenreErrorResponse(c, err)
+	}
+
+	return PurgeGenreSuccessResp(c)
+}
+
+func (h *GameHandler) GetGameByExternalId(ctx context.Context, c *fiber.Ctx) error {
+	provider := strings.TrimSpace(c.Params("provider"))
+	externalId := strings.TrimSpace(c.Params("externalId"))
+
+	if provider == "" || externalId == "" {
+		return GetGameByExternalIdErrorResponse(c, ErrBadRequest)
+	}
+
+	game, err := h.service.GetGameByExternalId(ctx, provider, externalId)
+
+	if err != nil {
+		return GetGameByExternalIdErrorResponse(c, err)
+	}
+
+	return GetGameByExternalIdSuccessResp(c, game)
+}
+
+func (h *GameHandler) UpsertGameByExternalId(ctx context.Context, c *fiber.Ctx) error {
+	provider := strings.TrimSpace(c.Params("provider"))
+	externalId := strings.TrimSpace(c.Params("externalId"))
+
+	if provider == "" || externalId == "" {
+		return UpsertGameErrorResponse(c, ErrBadRequest)
+	}
+
+	var req AddGameRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil || strings.TrimSpace(req.Title) == "" {
+		return UpsertGameErrorResponse(c, ErrBadRequest)
+	}
+
+	game := &Game{
+		Title:       strings.TrimSpace(req.Title),
+		Summary:     req.Summary,
+		ReleaseDate: req.ReleaseDate,
+		Developer:   req.Developer,
+		Publisher:   req.Publisher,
+		Genres:      req.Genres,
+		Platforms:   normalizePlatforms(req.Platforms),
+		Image:       req.Image,
+		ExternalIds: req.ExternalIds,
+		Rating:      RatingStats{},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+
+		AgeRating:          req.AgeRating,
+		ContentDescriptors: req.ContentDescriptors,
+	}
+
+	created, err := h.service.UpsertGameByExternalId(ctx, provider, externalId, game)
+
+	if err != nil {
+		return UpsertGameErrorResponse(c, err)
+	}
+
+	return UpsertGameSuccessResp(c, game, created)
+}
+
+type GetDuplicateFlagsQueries struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// open (default), confirmed or dismissed
+	Status string `json:"status,omitempty"`
+}
+
+func (h *GameHandler) GetDuplicateFlags(ctx context.Context, c *fiber.Ctx) error {
+	var req GetDuplicateFlagsQueries
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return GetDuplicateFlagsErrorResponse(c, ErrBadRequest)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	status := DuplicateOpen
+
+	if req.Status != "" {
+		status = DuplicateStatus(strings.TrimSpace(req.Status))
+	}
+
+	pagination := Pagination{
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	flags, err := h.service.GetDuplicateFlags(ctx, status, &pagination)
+
+	if err != nil {
+		return GetDuplicateFlagsErrorResponse(c, err)
+	}
+
+	return GetDuplicateFlagsSuccessResp(c, flags)
+}
+
+func (h *GameHandler) ReviewDuplicateFlag(ctx context.Context, c *fiber.Ctx) error {
+	flagId := c.Params("flagId")
+
+	var req ReviewDuplicateFlagRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return ReviewDuplicateFlagErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.service.ReviewDuplicateFlag(ctx, flagId, req.Status)
+
+	if err != nil {
+		return ReviewDuplicateFlagErrorResponse(c, err)
+	}
+
+	return ReviewDuplicateFlagSuccessResp(c)
+}
+
+func (h *GameHandler) GetGameTranslations(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return GameTranslationErrorResponse(c, ErrGameIdRequired)
+	}
+
+	translations, err := h.service.GetGameTranslations(ctx, id)
+
+	if err != nil {
+		return GameTranslationErrorResponse(c, err)
+	}
+
+	return GetTranslationsSuccessResp(c, translations)
+}
+
+func (h *GameHandler) SetGameTranslation(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return GameTranslationErrorResponse(c, ErrGameIdRequired)
+	}
+
+	var req GameTranslation
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return GameTranslationErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.service.SetGameTranslation(ctx, id, c.Params("locale"), &req)
+
+	if err != nil {
+		return GameTranslationErrorResponse(c, err)
+	}
+
+	return SetTranslationSuccessResp(c)
+}
+
+func (h *GameHandler) DeleteGameTranslation(ctx context.Context, c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	if id == "" {
+		return GameTranslationErrorResponse(c, ErrGameIdRequired)
+	}
+
+	err := h.service.DeleteGameTranslation(ctx, id, c.Params("locale"))
+
+	if err != nil {
+		return GameTranslationErrorResponse(c, err)
+	}
+
+	return DeleteTranslationSuccessResp(c)
+}
+
+func (h *GameHandler) GetGenreTranslations(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return GenreTranslationErrorResponse(c, ErrGameGenreSlugRequired)
+	}
+
+	translations, err := h.service.GetGenreTranslations(ctx, slug)
+
+	if err != nil {
+		return GenreTranslationErrorResponse(c, err)
+	}
+
+	return GetTranslationsSuccessResp(c, translations)
+}
+
+func (h *GameHandler) SetGenreTranslation(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return GenreTranslationErrorResponse(c, ErrGameGenreSlugRequired)
+	}
+
+	var req GenreTranslation
+
+	err := c.BodyParser(&req)
+
+	if err != nil {
+		return GenreTranslationErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.service.SetGenreTranslation(ctx, slug, c.Params("locale"), &req)
+
+	if err != nil {
+		return GenreTranslationErrorResponse(c, err)
+	}
+
+	return SetTranslationSuccessResp(c)
+}
+
+func (h *GameHandler) DeleteGenreTranslation(ctx context.Context, c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+
+	if slug == "" {
+		return GenreTranslationErrorResponse(c, ErrGameGenreSlugRequired)
+	}
+
+	err := h.service.DeleteGenreTranslation(ctx, slug, c.Params("locale"))
+
+	if err != nil {
+		return GenreTranslationErrorResponse(c, err)
+	}
+
+	return DeleteTranslationSuccessResp(c)
+}
+
+func (h *GameHandler) GetTranslationReport(ctx context.Context, c *fiber.Ctx) error {
+	report, err := h.service.GetTranslationReport(ctx)
+
+	if err != nil {
+		return GetTranslationReportErrorResponse(c, err)
+	}
+
+	return GetTranslationReportSuccessResp(c, report)
+}
+
+type GetMissingTranslationsQueries struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	// games (default) or genres
+	Type string `json:"type,omitempty"`
+	// any supported locale when empty
+	Locale string `json:"locale,omitempty"`
+}
+
+func (h *GameHandler) GetMissingTranslations(ctx context.Context, c *fiber.Ctx) error {
+	var req GetMissingTranslationsQueries
+
+	err := c.QueryParser(&req)
+
+	if err != nil || (req.Type != "" && req.Type != "games" && req.Type != "genres") {
+		return GetMissingTranslationsErrorResponse(c, ErrBadRequest)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	pagination := Pagination{
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	missing, err := h.service.GetMissingTranslations(ctx, req.Type == "genres", strings.TrimSpace(req.Locale), &pagination)
+
+	if err != nil {
+		return GetMissingTranslationsErrorResponse(c, err)
+	}
+
+	return GetMissingTranslationsSuccessResp(c, missing)
+}
+
+func (h *GameHandler) GetContentPreferences(ctx context.Context, c *fiber.Ctx) error {
+	preferences, err := h.service.GetContentPreferences(ctx)
+
+	if err != nil {
+		return ContentPreferencesErrorResponse(c, err)
+	}
+
+	return GetContentPreferencesSuccessResp(c, preferences)
+}
+
+func (h *GameHandler) SetContentPreferences(ctx context.Context, c *fiber.Ctx) error {
+	var req SetContentPreferencesRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return ContentPreferencesErrorResponse(c, ErrBadRequest)
+	}
+
+	preferences := &ContentPreferences{
+		MaxAge:            req.MaxAge,
+		HiddenDescriptors: req.HiddenDescriptors,
+		Mode:              req.Mode,
+	}
+
+	if birthdate := strings.TrimSpace(req.Birthdate); birthdate != "" {
+		parsed, err := time.Parse(time.DateOnly, birthdate)
+
+		if err != nil {
+			return ContentPreferencesErrorResponse(c, ErrBadRequest)
+		}
+
+		preferences.Birthdate = &parsed
+	}
+
+	if err := h.service.SetContentPreferences(ctx, preferences); err != nil {
+		return ContentPreferencesErrorResponse(c, err)
+	}
+
+	return GetContentPreferencesSuccessResp(c, preferences)
+}
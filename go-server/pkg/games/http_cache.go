@@ -0,0 +1,254 @@
+package games
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"go-server/pkg/cache"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ResponseCacheSize is how many catalog responses are kept in memory.
+	ResponseCacheSize = "RESPONSE_CACHE_SIZE"
+	// ResponseCacheTTLSeconds bounds how long a cached response is served. Rating
+	// stats are written by the reviews, outside of the invalidated write paths,
+	// so they can be that much behind.
+	ResponseCacheTTLSeconds = "RESPONSE_CACHE_TTL_SECONDS"
+
+	defaultResponseCacheSize       = 1000
+	defaultResponseCacheTTLSeconds = 60
+)
+
+// key prefixes of the cached catalog reads
+const (
+	cacheGame   = "game:"
+	cacheGames  = "games:"
+	cacheGenre  = "genre:"
+	cacheGenres = "genres:"
+)
+
+// catalogCachePolicies are the Cache-Control headers of the cached reads. Every
+// route needs a token so the responses are private, and clients revalidate
+// with the ETag once they are stale.
+var catalogCachePolicies = map[string]string{
+	cacheGame:   "private, max-age=60, must-revalidate",
+	cacheGames:  "private, max-age=30, must-revalidate",
+	cacheGenre:  "private, max-age=300, must-revalidate",
+	cacheGenres: "private, max-age=300, must-revalidate",
+}
+
+func getResponseCache() cache.Cache {
+	size, err := strconv.Atoi(os.Getenv(ResponseCacheSize))
+
+	if err != nil || size < 1 {
+		size = defaultResponseCacheSize
+	}
+
+	seconds, err := strconv.Atoi(os.Getenv(ResponseCacheTTLSeconds))
+
+	if err != nil || seconds < 0 {
+		seconds = defaultResponseCacheTTLSeconds
+	}
+
+	return cache.NewLRUCache(size, time.Duration(seconds)*time.Second)
+}
+
+// catalogCache wraps the cache backend so a read that started before a write
+// does not store its stale response after the write invalidated the cache.
+type catalogCache struct {
+	backend    cache.Cache
+	mu         sync.RWMutex
+	generation uint64
+}
+
+func newCatalogCache(backend cache.Cache) *catalogCache {
+	return &catalogCache{backend: backend}
+}
+
+func (c *catalogCache) get(ctx context.Context, key string) (*cache.Entry, bool) {
+	if c == nil || c.backend == nil {
+		return nil, false
+	}
+
+	return c.backend.Get(ctx, key)
+}
+
+func (c *catalogCache) currentGeneration() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.generation
+}
+
+// set stores the entry unless the cache was invalidated since generation was read.
+func (c *catalogCache) set(ctx context.Context, key string, entry *cache.Entry, generation uint64) {
+	if c == nil || c.backend == nil {
+		return
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.generation != generation {
+		return
+	}
+
+	c.backend.Set(ctx, key, entry)
+}
+
+func (c *catalogCache) invalidate(ctx context.Context, prefixes ...string) {
+	if c == nil || c.backend == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	for _, prefix := range prefixes {
+		c.backend.DeletePrefix(ctx, prefix)
+	}
+}
+
+// invalidateGames drops the cached game reads, after a game was written.
+func (g *Service) invalidateGames(ctx context.Context) {
+	g.cache.invalidate(ctx, cacheGame, cacheGames)
+}
+
+// invalidateGenres drops the cached genre reads and the game reads too, as games
+// embed their genres.
+func (g *Service) invalidateGenres(ctx context.Context) {
+	g.cache.invalidate(ctx, cacheGenre, cacheGenres, cacheGame, cacheGames)
+}
+
+// newCacheEntry renders a success response the way the presenters do, with a
+// strong ETag hashed from the body so it changes with any field of the documents.
+func newCacheEntry(message string, data interface{}, lastModified time.Time) (*cache.Entry, error) {
+	body, err := json.Marshal(fiber.Map{
+		"message": message,
+		"data":    data,
+	})
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	sum := sha256.Sum256(body)
+
+	return &cache.Entry{
+		Body:         body,
+		ContentType:  fiber.MIMEApplicationJSON,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: lastModified.UTC().Truncate(time.Second),
+	}, nil
+}
+
+// newRedirectEntry caches a permanent redirect, e.g. from an old game slug.
+func newRedirectEntry(location string) *cache.Entry {
+	return &cache.Entry{Location: location}
+}
+
+// sendCacheable serves a catalog read from the response cache, loading and
+// caching it on a miss, and answers 304 Not Modified when the client already
+// has it or 301 Moved Permanently for a cached redirect. Responses are cached
+// per locale and per content settings.
+func (h *GameHandler) sendCacheable(ctx context.Context, c *fiber.Ctx, route string, locale string, load func() (*cache.Entry, error), errorResponse func(*fiber.Ctx, error) error) error {
+	content, err := h.service.contentFilter(ctx)
+
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	key := route + locale + content.cacheKey() + ":" + c.OriginalURL()
+
+	entry, ok := h.service.cache.get(ctx, key)
+
+	if !ok {
+		generation := h.service.cache.currentGeneration()
+
+		loaded, err := load()
+
+		if err != nil {
+			return errorResponse(c, err)
+		}
+
+		h.service.cache.set(ctx, key, loaded, generation)
+		entry = loaded
+	}
+
+	c.Set(fiber.HeaderCacheControl, catalogCachePolicies[route])
+
+	if entry.Location != "" {
+		return c.Redirect(entry.Location, fiber.StatusMovedPermanently)
+	}
+
+	c.Set(fiber.HeaderETag, entry.ETag)
+
+	if !entry.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, entry.LastModified.Format(http.TimeFormat))
+	}
+
+	if notModified(c, entry) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, entry.ContentType)
+
+	return c.Status(fiber.StatusOK).Send(entry.Body)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when the client sent
+// no ETag.
+func notModified(c *fiber.Ctx, entry *cache.Entry) bool {
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+
+			if tag == "*" || tag == entry.ETag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if since := c.Get(fiber.HeaderIfModifiedSince); since != "" && !entry.LastModified.IsZero() {
+		modifiedSince, err := http.ParseTime(since)
+
+		return err == nil && !entry.LastModified.After(modifiedSince)
+	}
+
+	return false
+}
+
+// lastModified is when the game or its rating stats last changed.
+func (game *Game) lastModified() time.Time {
+	modified := game.UpdatedAt
+
+	if game.Rating.UpdatedAt.After(modified) {
+		modified = game.Rating.UpdatedAt
+	}
+
+	return modified
+}
+
+func (genre *GameGenre) lastModified() time.Time {
+	if genre.UpdatedAt.After(genre.CreatedAt) {
+		return genre.UpdatedAt
+	}
+
+	return genre.CreatedAt
+}
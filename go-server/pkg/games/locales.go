@@ -0,0 +1,423 @@
+package games
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/text/language"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultLocale is the language of the title, summary and description stored
+	// on games and genres, the fallback when no translation matches.
+	DefaultLocale = "DEFAULT_LOCALE"
+	// SupportedLocales is the comma separated list of locales games can be
+	// translated to, e.g. "fr,de,pt-BR".
+	SupportedLocales = "SUPPORTED_LOCALES"
+
+	defaultDefaultLocale    = "en"
+	defaultSupportedLocales = "en,fr,de,es,it,pt-BR,ja"
+)
+
+// LocaleConfig holds the locales content is served in, Default first.
+type LocaleConfig struct {
+	Default   string
+	Supported []string
+	matcher   language.Matcher
+}
+
+func getLocaleConfig() *LocaleConfig {
+	defaultLocale := normalizeLocale(os.Getenv(DefaultLocale))
+
+	if defaultLocale == "" {
+		defaultLocale = defaultDefaultLocale
+	}
+
+	supported := os.Getenv(SupportedLocales)
+
+	if strings.TrimSpace(supported) == "" {
+		supported = defaultSupportedLocales
+	}
+
+	return NewLocaleConfig(defaultLocale, strings.Split(supported, ","))
+}
+
+func NewLocaleConfig(defaultLocale string, supported []string) *LocaleConfig {
+	config := &LocaleConfig{Default: defaultLocale, Supported: []string{defaultLocale}}
+
+	for _, locale := range supported {
+		locale = normalizeLocale(locale)
+
+		if locale != "" && !config.isSupported(locale) {
+			config.Supported = append(config.Supported, locale)
+		}
+	}
+
+	tags := make([]language.Tag, len(config.Supported))
+
+	for i, locale := range config.Supported {
+		tags[i] = language.Make(locale)
+	}
+
+	config.matcher = language.NewMatcher(tags)
+
+	return config
+}
+
+// normalizeLocale returns the canonical form of a language tag, "pt-br" gives
+// "pt-BR", or "" when it is not one.
+func normalizeLocale(locale string) string {
+	tag, err := language.Parse(strings.TrimSpace(locale))
+
+	if err != nil || tag == language.Und {
+		return ""
+	}
+
+	return tag.String()
+}
+
+func (l *LocaleConfig) isSupported(locale string) bool {
+	for _, supported := range l.Supported {
+		if supported == locale {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTranslatable tells if content can be translated to the locale, the default
+// locale being the content itself.
+func (l *LocaleConfig) isTranslatable(locale string) bool {
+	return locale != l.Default && l.isSupported(locale)
+}
+
+// Resolve picks the supported locale best matching an Accept-Language header.
+// A regional variant falls back to its language and the other way around, e.g.
+// "pt-PT" is served "pt-BR", and anything else gets the default locale.
+func (l *LocaleConfig) Resolve(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+
+	if err != nil || len(tags) == 0 {
+		return l.Default
+	}
+
+	_, index, confidence := l.matcher.Match(tags...)
+
+	if confidence == language.No {
+		return l.Default
+	}
+
+	return l.Supported[index]
+}
+
+// resolveLocale picks the locale of the response from its Accept-Language header
+// and tells caches the response depends on it.
+func (h *GameHandler) resolveLocale(c *fiber.Ctx) string {
+	locale := h.service.locales.Resolve(c.Get(fiber.HeaderAcceptLanguage))
+
+	c.Vary(fiber.HeaderAcceptLanguage)
+	c.Set(fiber.HeaderContentLanguage, locale)
+
+	return locale
+}
+
+// localize swaps the title and summary for their translation to locale, fields
+// missing from the translation keep the default locale.
+func (game *Game) localize(locales *LocaleConfig, locale string) {
+	game.Locale = locales.Default
+
+	if translation, ok := game.Translations[locale]; ok && locale != locales.Default {
+		if translation.Title != "" {
+			game.Title = translation.Title
+			game.Locale = locale
+		}
+
+		if translation.Summary != "" {
+			game.Summary = translation.Summary
+			game.Locale = locale
+		}
+	}
+
+	game.Translations = nil
+}
+
+func (genre *GameGenre) localize(locales *LocaleConfig, locale string) {
+	genre.Locale = locales.Default
+
+	if translation, ok := genre.Translations[locale]; ok && locale != locales.Default && translation.Desc != "" {
+		genre.Desc = translation.Desc
+		genre.Locale = locale
+	}
+
+	genre.Translations = nil
+}
+
+// missingLocales lists the locales the game has no complete translation to.
+func (game *Game) missingLocales(locales *LocaleConfig) []string {
+	missing := []string{}
+
+	for _, locale := range locales.Supported {
+		translation, ok := game.Translations[locale]
+
+		if locale != locales.Default && (!ok || translation.Title == "" || translation.Summary == "") {
+			missing = append(missing, locale)
+		}
+	}
+
+	return missing
+}
+
+func (genre *GameGenre) missingLocales(locales *LocaleConfig) []string {
+	missing := []string{}
+
+	for _, locale := range locales.Supported {
+		translation, ok := genre.Translations[locale]
+
+		if locale != locales.Default && (!ok || translation.Desc == "") {
+			missing = append(missing, locale)
+		}
+	}
+
+	return missing
+}
+
+// translatableLocale normalizes locale and checks content can be translated to it.
+func (g *Service) translatableLocale(locale string) (string, error) {
+	locale = normalizeLocale(locale)
+
+	if !g.locales.isTranslatable(locale) {
+		return "", ErrUnsupportedLocale
+	}
+
+	return locale, nil
+}
+
+func (g *Service) GetGameTranslations(ctx context.Context, id string) (map[string]GameTranslation, error) {
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil || game.IsDeleted {
+		return nil, ErrNotFound
+	}
+
+	if game.Translations == nil {
+		return map[string]GameTranslation{}, nil
+	}
+
+	return game.Translations, nil
+}
+
+func (g *Service) SetGameTranslation(ctx context.Context, id string, locale string, translation *GameTranslation) error {
+
+	locale, err := g.translatableLocale(locale)
+
+	if err != nil {
+		return err
+	}
+
+	translation.Title = strings.TrimSpace(translation.Title)
+	translation.Summary = strings.TrimSpace(translation.Summary)
+
+	if translation.Title == "" && translation.Summary == "" {
+		return ErrBadRequest
+	}
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil || game.IsDeleted {
+		return ErrNotFound
+	}
+
+	if err = g.repository.setGameTranslation(ctx, game.Id, locale, translation); err != nil {
+		return err
+	}
+
+	g.invalidateGames(ctx)
+
+	return nil
+}
+
+func (g *Service) DeleteGameTranslation(ctx context.Context, id string, locale string) error {
+
+	locale, err := g.translatableLocale(locale)
+
+	if err != nil {
+		return err
+	}
+
+	game, err := g.findGame(ctx, id)
+
+	if err != nil || game.IsDeleted {
+		return ErrNotFound
+	}
+
+	if err = g.repository.deleteGameTranslation(ctx, game.Id, locale); err != nil {
+		return err
+	}
+
+	g.invalidateGames(ctx)
+
+	return nil
+}
+
+func (g *Service) GetGenreTranslations(ctx context.Context, slug string) (map[string]GenreTranslation, error) {
+
+	genre, err := g.GetGameGenre(ctx, slug)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if genre.Translations == nil {
+		return map[string]GenreTranslation{}, nil
+	}
+
+	return genre.Translations, nil
+}
+
+func (g *Service) SetGenreTranslation(ctx context.Context, slug string, locale string, translation *GenreTranslation) error {
+
+	locale, err := g.translatableLocale(locale)
+
+	if err != nil {
+		return err
+	}
+
+	translation.Desc = strings.TrimSpace(translation.Desc)
+
+	if translation.Desc == "" {
+		return ErrBadRequest
+	}
+
+	genre, err := g.GetGameGenre(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	if err = g.repository.setGenreTranslation(ctx, genre.Slug, locale, translation); err != nil {
+		return err
+	}
+
+	g.invalidateGenres(ctx)
+
+	return nil
+}
+
+func (g *Service) DeleteGenreTranslation(ctx context.Context, slug string, locale string) error {
+
+	locale, err := g.translatableLocale(locale)
+
+	if err != nil {
+		return err
+	}
+
+	genre, err := g.GetGameGenre(ctx, slug)
+
+	if err != nil {
+		return err
+	}
+
+	if err = g.repository.deleteGenreTranslation(ctx, genre.Slug, locale); err != nil {
+		return err
+	}
+
+	g.invalidateGenres(ctx)
+
+	return nil
+}
+
+// GetTranslationReport counts, for each supported locale, the games and genres
+// not fully translated to it.
+func (g *Service) GetTranslationReport(ctx context.Context) (*TranslationReport, error) {
+
+	report := &TranslationReport{DefaultLocale: g.locales.Default, Locales: []LocaleCoverage{}}
+
+	for _, locale := range g.locales.Supported {
+		if locale == g.locales.Default {
+			continue
+		}
+
+		games, genres, err := g.repository.countMissingTranslations(ctx, locale)
+
+		if err != nil {
+			return nil, err
+		}
+
+		report.Locales = append(report.Locales, LocaleCoverage{Locale: locale, MissingGames: games, MissingGenres: genres})
+	}
+
+	return report, nil
+}
+
+// GetMissingTranslations lists the games, or the genres when genres is true,
+// not fully translated to the locale, or to any supported locale when it is empty.
+func (g *Service) GetMissingTranslations(ctx context.Context, genres bool, locale string, pagination *Pagination) (*PaginatedResponse[MissingTranslations], error) {
+
+	var locales []string
+
+	if locale != "" {
+		locale, err := g.translatableLocale(locale)
+
+		if err != nil {
+			return nil, err
+		}
+
+		locales = []string{locale}
+	} else {
+		for _, supported := range g.locales.Supported {
+			if supported != g.locales.Default {
+				locales = append(locales, supported)
+			}
+		}
+	}
+
+	response := &PaginatedResponse[MissingTranslations]{Data: []MissingTranslations{}, ItemsPerPage: pagination.Limit}
+
+	if len(locales) == 0 {
+		return response, nil
+	}
+
+	only := &LocaleConfig{Default: g.locales.Default, Supported: locales}
+
+	if genres {
+		missing, err := g.repository.getGenresMissingTranslations(ctx, locales, pagination)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range missing.Data {
+			response.Data = append(response.Data, MissingTranslations{
+				Slug:    missing.Data[i].Slug,
+				Title:   missing.Data[i].Title,
+				Missing: missing.Data[i].missingLocales(only),
+			})
+		}
+
+		response.CurrentPage, response.TotalPages, response.TotalItems, response.HasMore = missing.CurrentPage, missing.TotalPages, missing.TotalItems, missing.HasMore
+
+		return response, nil
+	}
+
+	missing, err := g.repository.getGamesMissingTranslations(ctx, locales, pagination)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range missing.Data {
+		response.Data = append(response.Data, MissingTranslations{
+			Id:      missing.Data[i].Id.Hex(),
+			Slug:    missing.Data[i].Slug,
+			Title:   missing.Data[i].Title,
+			Missing: missing.Data[i].missingLocales(only),
+		})
+	}
+
+	response.CurrentPage, response.TotalPages, response.TotalItems, response.HasMore = missing.CurrentPage, missing.TotalPages, missing.TotalItems, missing.HasMore
+
+	return response, nil
+}
@@ -0,0 +1,178 @@
+package games
+
+import (
+	"bytes"
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const (
+	// MediaStoragePath is the directory the filesystem blob storage writes to.
+	MediaStoragePath = "MEDIA_STORAGE_PATH"
+	// MediaMaxUploadBytes is the largest image upload accepted, in bytes.
+	MediaMaxUploadBytes = "MEDIA_MAX_UPLOAD_BYTES"
+
+	defaultMediaStoragePath    = "resources/media"
+	defaultMediaMaxUploadBytes = 5 * 1024 * 1024
+	maxImagePixels             = 40_000_000
+	mediaCacheControl          = "public, max-age=31536000, immutable"
+)
+
+// the content types accepted for uploads, mapped to the extension they are stored with
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type thumbnailSize struct {
+	name  string
+	width int
+}
+
+var thumbnailSizes = []thumbnailSize{
+	{name: "thumb", width: 320},
+	{name: "medium", width: 1280},
+}
+
+type MediaConfig struct {
+	StoragePath    string
+	BaseUrl        string
+	MaxUploadBytes int64
+}
+
+func getMediaConfig(baseUrl string) *MediaConfig {
+	config := &MediaConfig{
+		StoragePath:    os.Getenv(MediaStoragePath),
+		BaseUrl:        baseUrl,
+		MaxUploadBytes: GetMediaMaxUploadBytes(),
+	}
+
+	if config.StoragePath == "" {
+		config.StoragePath = defaultMediaStoragePath
+	}
+
+	return config
+}
+
+// GetMediaMaxUploadBytes reads the largest image upload from the environment,
+// falling back to the default. The server body limit is derived from it.
+func GetMediaMaxUploadBytes() int64 {
+	if maxBytes, err := strconv.ParseInt(os.Getenv(MediaMaxUploadBytes), 10, 64); err == nil && maxBytes > 0 {
+		return maxBytes
+	}
+
+	return defaultMediaMaxUploadBytes
+}
+
+type encodedImage struct {
+	data        []byte
+	contentType string
+	ext         string
+	width       int
+	height      int
+}
+
+// detectImageType sniffs the content type from the data itself rather than
+// trusting the client supplied header.
+func detectImageType(data []byte) (string, string, error) {
+	contentType := http.DetectContentType(data)
+
+	ext, ok := allowedImageTypes[contentType]
+	if !ok {
+		return "", "", ErrUnsupportedMediaType
+	}
+
+	return contentType, ext, nil
+}
+
+func decodeImage(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	// refuse decompression bombs before allocating the full image
+	if config.Width*config.Height > maxImagePixels {
+		return nil, ErrMediaTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedMediaType
+	}
+
+	return img, nil
+}
+
+// resizeImage scales img down to width keeping the aspect ratio, images
+// that are already narrower are returned as they are.
+func resizeImage(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+
+	if bounds.Dx() <= width {
+		return img
+	}
+
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	return dst
+}
+
+// encodeThumbnail keeps png for png sources so transparency survives,
+// everything else becomes a jpeg.
+func encodeThumbnail(img image.Image, sourceType string) (*encodedImage, error) {
+	var buf bytes.Buffer
+	result := &encodedImage{
+		width:  img.Bounds().Dx(),
+		height: img.Bounds().Dy(),
+	}
+
+	if sourceType == "image/png" {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		result.contentType = "image/png"
+		result.ext = ".png"
+	} else {
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, err
+		}
+		result.contentType = "image/jpeg"
+		result.ext = ".jpg"
+	}
+
+	result.data = buf.Bytes()
+
+	return result, nil
+}
+
+func encodeWebP(img image.Image) (*encodedImage, error) {
+	var buf bytes.Buffer
+
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return nil, err
+	}
+
+	return &encodedImage{
+		data:        buf.Bytes(),
+		contentType: "image/webp",
+		ext:         ".webp",
+		width:       img.Bounds().Dx(),
+		height:      img.Bounds().Dy(),
+	}, nil
+}
@@ -2,6 +2,8 @@ package games
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"go-server/pkg/cache"
+	"time"
 )
 
 func AddGenreErrorResponse(c *fiber.Ctx, err error) error {
@@ -14,6 +16,9 @@ func AddGenreErrorResponse(c *fiber.Ctx, err error) error {
 	} else if err == ErrGameGenreAlreadyExists {
 		status = fiber.StatusConflict
 		message = "Game genre already existed"
+	} else if err == ErrInvalidGenreParent {
+		status = fiber.StatusBadRequest
+		message = "Parent genre not found"
 	} else {
 		status = 500
 		message = "Something went wrong"
@@ -50,6 +55,9 @@ func EditGenreErrorResponse(c *fiber.Ctx, err error) error {
 	} else if err == ErrNotFound {
 		status = fiber.StatusNotFound
 		message = "Game genre not found"
+	} else if err == ErrInvalidGenreParent {
+		status = fiber.StatusBadRequest
+		message = "Parent genre must exist and cannot be the genre itself or one of its subgenres"
 	} else {
 		status = 500
 		message = "Something went wrong"
@@ -87,11 +95,16 @@ func GetGenresErrorResponse(c *fiber.Ctx, err error) error {
 	})
 }
 
-func GetGenresSuccessResp(c *fiber.Ctx, genreResp *PaginatedResponse[GameGenre]) error {
-	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
-		"message": "Game genres",
-		"data":    genreResp,
-	})
+func GetGenresCacheEntry(genreResp *PaginatedResponse[GameGenre]) (*cache.Entry, error) {
+	lastModified := time.Time{}
+
+	for i := range genreResp.Data {
+		if modified := genreResp.Data[i].lastModified(); modified.After(lastModified) {
+			lastModified = modified
+		}
+	}
+
+	return newCacheEntry("Game genres", genreResp, lastModified)
 }
 
 func GetGenreErrorResponse(c *fiber.Ctx, err error) error {
@@ -119,11 +132,8 @@ func GetGenreErrorResponse(c *fiber.Ctx, err error) error {
 	})
 }
 
-func GetGenreSuccessResp(c *fiber.Ctx, genre *GameGenre) error {
-	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
-		"message": "Game genre",
-		"data":    genre,
-	})
+func GetGenreCacheEntry(genre *GameGenre) (*cache.Entry, error) {
+	return newCacheEntry("Game genre", genre, genre.lastModified())
 }
 
 func DeleteGenreErrorResponse(c *fiber.Ctx, err error) error {
@@ -167,6 +177,18 @@ func AddGameErrorResponse(c *fiber.Ctx, err error) error {
 	} else if err == ErrGameAlreadyExists {
 		status = fiber.StatusConflict
 		message = "Game already existed"
+	} else if err == ErrGameSlugTaken {
+		status = fiber.StatusConflict
+		message = "Game slug was just taken, try again"
+	} else if err == ErrUnknownExternalIdProvider {
+		status = fiber.StatusBadRequest
+		message = "Unknown external id provider"
+	} else if err == ErrExternalIdConflict {
+		status = fiber.StatusConflict
+		message = "External ids match different games"
+	} else if err == ErrInvalidAgeRating {
+		status = fiber.StatusBadRequest
+		message = "Invalid age rating or content descriptors"
 	} else {
 		status = 500
 		message = "Something went wrong"
@@ -179,13 +201,14 @@ func AddGameErrorResponse(c *fiber.Ctx, err error) error {
 }
 
 type AddGameRes struct {
-	gameId string `json:"gameId"`
+	GameId string `json:"gameId"`
+	Slug   string `json:"slug"`
 }
 
-func AddGameSuccessResp(c *fiber.Ctx, id string) error {
+func AddGameSuccessResp(c *fiber.Ctx, game *Game) error {
 	return c.Status(fiber.StatusCreated).JSON(&fiber.Map{
 		"message": "Game added",
-		"data":    AddGameRes{gameId: id},
+		"data":    AddGameRes{GameId: game.Id.Hex(), Slug: game.Slug},
 	})
 }
 
@@ -202,6 +225,9 @@ func GetGameErrorResponse(c *fiber.Ctx, err error) error {
 	} else if err == ErrNotFound {
 		status = fiber.StatusNotFound
 		message = "Game not found"
+	} else if err == ErrContentRestricted {
+		status = fiber.StatusForbidden
+		message = "Game is above your content settings"
 	} else {
 		status = 500
 		message = "Something went wrong"
@@ -213,11 +239,8 @@ func GetGameErrorResponse(c *fiber.Ctx, err error) error {
 	})
 }
 
-func GetGameSuccessResp(c *fiber.Ctx, game *Game) error {
-	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
-		"message": "Game",
-		"data":    game,
-	})
+func GetGameCacheEntry(game *Game) (*cache.Entry, error) {
+	return newCacheEntry("Game", game, game.lastModified())
 }
 
 func GetGamesErrorResponse(c *fiber.Ctx, err error) error {
@@ -238,10 +261,34 @@ func GetGamesErrorResponse(c *fiber.Ctx, err error) error {
 	})
 }
 
-func GetGamesSuccessResp(c *fiber.Ctx, gamesResponse *PaginatedResponse[Game]) error {
+func GetGamesCacheEntry(gamesResponse *PaginatedResponse[Game]) (*cache.Entry, error) {
+	lastModified := time.Time{}
+
+	for i := range gamesResponse.Data {
+		if modified := gamesResponse.Data[i].lastModified(); modified.After(lastModified) {
+			lastModified = modified
+		}
+	}
+
+	return newCacheEntry("Games", gamesResponse, lastModified)
+}
+
+func RecalculateRatingsErrorResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Something went wrong",
+		"error":   err.Error(),
+	})
+}
+
+type RecalculateRatingsRes struct {
+	Updated int           `json:"updated"`
+	Drifts  []RatingDrift `json:"drifts"`
+}
+
+func RecalculateRatingsSuccessResp(c *fiber.Ctx, drifts []RatingDrift) error {
 	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
-		"message": "Games",
-		"data":    gamesResponse,
+		"message": "Game ratings recalculated",
+		"data":    RecalculateRatingsRes{Updated: len(drifts), Drifts: drifts},
 	})
 }
 
@@ -258,6 +305,15 @@ func UpdateGameErrorResp(c *fiber.Ctx, err error) error {
 	} else if err == ErrNotFound {
 		status = fiber.StatusNotFound
 		message = "Game not found"
+	} else if err == ErrGameEditConflict {
+		status = fiber.StatusConflict
+		message = "Game was modified by someone else, try again"
+	} else if err == ErrGameSlugTaken {
+		status = fiber.StatusConflict
+		message = "Game slug was just taken, try again"
+	} else if err == ErrInvalidAgeRating {
+		status = fiber.StatusBadRequest
+		message = "Invalid age rating or content descriptors"
 	} else {
 		status = 500
 		message = "Something went wrong"
@@ -306,3 +362,1000 @@ func DeleteGameSuccessResp(c *fiber.Ctx) error {
 		"data":    "",
 	})
 }
+
+func AddFranchiseErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrFranchiseAlreadyExists {
+		status = fiber.StatusConflict
+		message = "Franchise already existed"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+type AddFranchiseRes struct {
+	Slug string `json:"slug"`
+}
+
+func AddFranchiseSuccessResp(c *fiber.Ctx, slug string) error {
+	return c.Status(fiber.StatusCreated).JSON(&fiber.Map{
+		"message": "Franchise added",
+		"data":    AddFranchiseRes{Slug: slug},
+	})
+}
+
+func GetFranchiseTimelineErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Franchise slug is required"
+	} else if err == ErrFranchiseNotFound {
+		status = fiber.StatusNotFound
+		message = "Franchise not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetFranchiseTimelineSuccessResp(c *fiber.Ctx, timeline *FranchiseTimeline) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Franchise timeline",
+		"data":    timeline,
+	})
+}
+
+func DeleteFranchiseErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Franchise slug is required"
+	} else if err == ErrFranchiseNotFound {
+		status = fiber.StatusNotFound
+		message = "Franchise not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func DeleteFranchiseSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Franchise deleted",
+		"data":    "",
+	})
+}
+
+func GetRelatedGamesErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetRelatedGamesSuccessResp(c *fiber.Ctx, relations *GameRelations) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Related games",
+		"data":    relations,
+	})
+}
+
+func GetSimilarGamesErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetSimilarGamesSuccessResp(c *fiber.Ctx, similarGames []SimilarGame) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Similar games",
+		"data":    similarGames,
+	})
+}
+
+func GetRecommendedGamesErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "You need to be logged in"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetRecommendedGamesSuccessResp(c *fiber.Ctx, recommended []RecommendedGame) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Recommended games",
+		"data":    recommended,
+	})
+}
+
+func EvaluateRecommendationsErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "k must be between 1 and 50"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func EvaluateRecommendationsSuccessResp(c *fiber.Ctx, evaluation *RecommendationEvaluation) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Recommendations evaluated",
+		"data":    evaluation,
+	})
+}
+
+func GetChartErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request, window must be day, week or month"
+	} else if err == ErrChartNotFound {
+		status = fiber.StatusNotFound
+		message = "Chart not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetChartSuccessResp(c *fiber.Ctx, chart *PaginatedResponse[ChartEntry]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Chart",
+		"data":    chart,
+	})
+}
+
+func AddGameRelationErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrInvalidGameRelation {
+		status = fiber.StatusBadRequest
+		message = "Invalid game relation"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrFranchiseNotFound {
+		status = fiber.StatusNotFound
+		message = "Franchise not found"
+	} else if err == ErrGameRelationAlreadyExists {
+		status = fiber.StatusConflict
+		message = "Game relation already existed"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+type AddGameRelationRes struct {
+	RelationId string `json:"relationId"`
+}
+
+func AddGameRelationSuccessResp(c *fiber.Ctx, id string) error {
+	return c.Status(fiber.StatusCreated).JSON(&fiber.Map{
+		"message": "Game relation added",
+		"data":    AddGameRelationRes{RelationId: id},
+	})
+}
+
+func DeleteGameRelationErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game relation not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func DeleteGameRelationSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Game relation deleted",
+		"data":    "",
+	})
+}
+
+func AddGameMediaErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrInvalidMediaKind {
+		status = fiber.StatusBadRequest
+		message = "Media kind must be cover, screenshot or trailer"
+	} else if err == ErrInvalidTrailerUrl {
+		status = fiber.StatusBadRequest
+		message = "Trailer url must be a valid http or https link"
+	} else if err == ErrUnsupportedMediaType {
+		status = fiber.StatusUnsupportedMediaType
+		message = "Only jpeg, png, gif and webp images are supported"
+	} else if err == ErrMediaTooLarge {
+		status = fiber.StatusRequestEntityTooLarge
+		message = "Image is too large"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func AddGameMediaSuccessResp(c *fiber.Ctx, media *GameMedia) error {
+	return c.Status(fiber.StatusCreated).JSON(&fiber.Map{
+		"message": "Game media added",
+		"data":    media,
+	})
+}
+
+func GetGameMediaErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetGameMediaSuccessResp(c *fiber.Ctx, media []GameMedia) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Game media",
+		"data":    media,
+	})
+}
+
+func DeleteGameMediaErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrMediaNotFound {
+		status = fiber.StatusNotFound
+		message = "Game media not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func DeleteGameMediaSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Game media deleted",
+		"data":    "",
+	})
+}
+
+func ServeMediaErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrMediaNotFound {
+		status = fiber.StatusNotFound
+		message = "Media not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetGameRevisionsErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetGameRevisionsSuccessResp(c *fiber.Ctx, revisions *PaginatedResponse[GameRevision]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Game revisions",
+		"data":    revisions,
+	})
+}
+
+func RevertGameRevisionErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Revision must be a positive number"
+	} else if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrGameRevisionNotFound {
+		status = fiber.StatusNotFound
+		message = "Game revision not found"
+	} else if err == ErrGameEditConflict {
+		status = fiber.StatusConflict
+		message = "Game was modified by someone else, try again"
+	} else if err == ErrGameSlugTaken {
+		status = fiber.StatusConflict
+		message = "Game slug was just taken, try again"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func RevertGameRevisionSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Game revision reverted",
+		"data":    "",
+	})
+}
+
+func GetTrashErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetTrashSuccessResp[V PaginatedResponseType](c *fiber.Ctx, trash *PaginatedResponse[V]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Trash",
+		"data":    trash,
+	})
+}
+
+func RestoreGameErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrNotInTrash {
+		status = fiber.StatusConflict
+		message = "Game is not in the trash"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func RestoreGameSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Game restored",
+		"data":    "",
+	})
+}
+
+func PurgeGameErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrNotInTrash {
+		status = fiber.StatusConflict
+		message = "Only games in the trash can be purged"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func PurgeGameSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Game purged",
+		"data":    "",
+	})
+}
+
+func RestoreGenreErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrGameGenreSlugRequired {
+		status = fiber.StatusBadRequest
+		message = "Game genre slug is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game genre not found in the trash"
+	} else if err == ErrGameGenreAlreadyExists {
+		status = fiber.StatusConflict
+		message = "A game genre with this slug already exists"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func RestoreGenreSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Game genre restored",
+		"data":    "",
+	})
+}
+
+func PurgeGenreErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrGameGenreSlugRequired {
+		status = fiber.StatusBadRequest
+		message = "Game genre slug is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game genre not found in the trash"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func PurgeGenreSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Game genre purged",
+		"data":    "",
+	})
+}
+
+func RenameGenreErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrGameGenreSlugRequired {
+		status = fiber.StatusBadRequest
+		message = "Game genre slug is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game genre not found"
+	} else if err == ErrGameGenreAlreadyExists {
+		status = fiber.StatusConflict
+		message = "A game genre with the new slug already exists"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func RenameGenreSuccessResp(c *fiber.Ctx, genre *GameGenre) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Game genre renamed",
+		"data":    genre,
+	})
+}
+
+func MergeGenreErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrGameGenreSlugRequired {
+		status = fiber.StatusBadRequest
+		message = "Game genre slug is required"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game genre not found"
+	} else if err == ErrInvalidGenreMerge {
+		status = fiber.StatusBadRequest
+		message = "A genre cannot be merged into itself or one of its subgenres"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func MergeGenreSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Game genres merged",
+		"data":    "",
+	})
+}
+
+func GetGameByExternalIdErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Provider and external id are required"
+	} else if err == ErrUnknownExternalIdProvider {
+		status = fiber.StatusBadRequest
+		message = "Unknown external id provider"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrContentRestricted {
+		status = fiber.StatusForbidden
+		message = "Game is above your content settings"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetGameByExternalIdSuccessResp(c *fiber.Ctx, game *Game) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Game",
+		"data":    game,
+	})
+}
+
+func UpsertGameErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrUnknownExternalIdProvider {
+		status = fiber.StatusBadRequest
+		message = "Unknown external id provider"
+	} else if err == ErrExternalIdConflict {
+		status = fiber.StatusConflict
+		message = "External ids match different games"
+	} else if err == ErrGameAlreadyExists {
+		status = fiber.StatusConflict
+		message = "Game is in the trash"
+	} else if err == ErrGameEditConflict || err == ErrGameSlugTaken {
+		status = fiber.StatusConflict
+		message = "Game was modified by someone else, try again"
+	} else if err == ErrInvalidAgeRating {
+		status = fiber.StatusBadRequest
+		message = "Invalid age rating or content descriptors"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+type UpsertGameRes struct {
+	GameId  string `json:"gameId"`
+	Slug    string `json:"slug"`
+	Created bool   `json:"created"`
+}
+
+func UpsertGameSuccessResp(c *fiber.Ctx, game *Game, created bool) error {
+	status := fiber.StatusOK
+	message := "Game updated"
+
+	if created {
+		status = fiber.StatusCreated
+		message = "Game added"
+	}
+
+	return c.Status(status).JSON(&fiber.Map{
+		"message": message,
+		"data":    UpsertGameRes{GameId: game.Id.Hex(), Slug: game.Slug, Created: created},
+	})
+}
+
+func GetDuplicateFlagsErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Status must be open, confirmed or dismissed"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetDuplicateFlagsSuccessResp(c *fiber.Ctx, flags *PaginatedResponse[DuplicateFlag]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Duplicate flags",
+		"data":    flags,
+	})
+}
+
+func ReviewDuplicateFlagErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Status must be open, confirmed or dismissed"
+	} else if err == ErrDuplicateFlagNotFound {
+		status = fiber.StatusNotFound
+		message = "Duplicate flag not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func ReviewDuplicateFlagSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Duplicate flag reviewed",
+		"data":    "",
+	})
+}
+
+func GameTranslationErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "A title or a summary is required"
+	} else if err == ErrGameIdRequired {
+		status = fiber.StatusBadRequest
+		message = "Game id is required"
+	} else if err == ErrUnsupportedLocale {
+		status = fiber.StatusBadRequest
+		message = "Locale is not supported or is the default one"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrTranslationNotFound {
+		status = fiber.StatusNotFound
+		message = "Translation not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GenreTranslationErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "A description is required"
+	} else if err == ErrGameGenreSlugRequired {
+		status = fiber.StatusBadRequest
+		message = "Game genre slug is required"
+	} else if err == ErrUnsupportedLocale {
+		status = fiber.StatusBadRequest
+		message = "Locale is not supported or is the default one"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game genre not found"
+	} else if err == ErrTranslationNotFound {
+		status = fiber.StatusNotFound
+		message = "Translation not found"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetTranslationsSuccessResp[T GameTranslation | GenreTranslation](c *fiber.Ctx, translations map[string]T) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Translations",
+		"data":    translations,
+	})
+}
+
+func SetTranslationSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Translation saved",
+		"data":    "",
+	})
+}
+
+func DeleteTranslationSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": "Translation deleted",
+		"data":    "",
+	})
+}
+
+func GetTranslationReportErrorResponse(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"message": "Something went wrong",
+		"error":   err.Error(),
+	})
+}
+
+func GetTranslationReportSuccessResp(c *fiber.Ctx, report *TranslationReport) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Translation report",
+		"data":    report,
+	})
+}
+
+func GetMissingTranslationsErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Type must be games or genres"
+	} else if err == ErrUnsupportedLocale {
+		status = fiber.StatusBadRequest
+		message = "Locale is not supported or is the default one"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetMissingTranslationsSuccessResp(c *fiber.Ctx, missing *PaginatedResponse[MissingTranslations]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Missing translations",
+		"data":    missing,
+	})
+}
+
+func ContentPreferencesErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid content preferences"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "Unauthorized"
+	} else if err == ErrBirthdateAlreadySet {
+		status = fiber.StatusConflict
+		message = "Birthdate can't be changed once set"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetContentPreferencesSuccessResp(c *fiber.Ctx, preferences *ContentPreferences) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Content preferences",
+		"data":    preferences,
+	})
+}
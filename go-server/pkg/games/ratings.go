@@ -0,0 +1,219 @@
+package games
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"math"
+	"os"
+	"strconv"
+)
+
+const (
+	// RatingPriorMean is the rating a game is assumed to have before it gets any review.
+	RatingPriorMean = "RATING_PRIOR_MEAN"
+	// RatingPriorWeight is how many reviews the prior mean counts as in the weighted rating.
+	RatingPriorWeight = "RATING_PRIOR_WEIGHT"
+
+	MinRating = 1
+	MaxRating = 5
+
+	defaultRatingPriorMean   = 3.0
+	defaultRatingPriorWeight = 10.0
+)
+
+// the aspects of a game reviews can score on their own, on the same scale as the
+// overall rating
+const (
+	SubScoreGameplay    = "gameplay"
+	SubScoreStory       = "story"
+	SubScoreGraphics    = "graphics"
+	SubScoreAudio       = "audio"
+	SubScorePerformance = "performance"
+)
+
+var SubScoreDimensions = []string{SubScoreGameplay, SubScoreStory, SubScoreGraphics, SubScoreAudio, SubScorePerformance}
+
+func IsSubScoreDimension(dimension string) bool {
+	for _, d := range SubScoreDimensions {
+		if d == dimension {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SubScoreStats are the totals of the reviews of a game scoring one of its
+// aspects, Average being derived from Sum and Count.
+type SubScoreStats struct {
+	Sum       int            `json:"sum" bson:"sum"`
+	Count     int            `json:"count" bson:"count"`
+	Histogram map[string]int `json:"histogram" bson:"histogram"`
+	Average   float64        `json:"average" bson:"average"`
+}
+
+// RatingPrior is the prior of the Bayesian weighted rating, the game's average
+// is pulled towards Mean as if it had Weight extra reviews of that value.
+// e.g. with {Mean: 3, Weight: 10} a single 5 star review gives a weighted rating
+// of 3.18, while 2000 reviews averaging 4.8 give 4.79.
+type RatingPrior struct {
+	Mean   float64 `json:"mean"`
+	Weight float64 `json:"weight"`
+}
+
+// GetRatingPrior reads the prior from the environment, falling back to the defaults.
+func GetRatingPrior() RatingPrior {
+	prior := RatingPrior{
+		Mean:   defaultRatingPriorMean,
+		Weight: defaultRatingPriorWeight,
+	}
+
+	if mean, err := strconv.ParseFloat(os.Getenv(RatingPriorMean), 64); err == nil && mean >= MinRating && mean <= MaxRating {
+		prior.Mean = mean
+	}
+
+	if weight, err := strconv.ParseFloat(os.Getenv(RatingPriorWeight), 64); err == nil && weight >= 0 {
+		prior.Weight = weight
+	}
+
+	return prior
+}
+
+// RatingBucket returns the histogram key a rating is counted under. Ratings
+// outside of the star range, like the 0 ratings saved before they were
+// validated, have no bucket and are left out of the stats altogether, so the
+// histogram always adds up to Count and Sum.
+func RatingBucket(rating int) (string, bool) {
+	if rating < MinRating || rating > MaxRating {
+		return "", false
+	}
+
+	return strconv.Itoa(rating), true
+}
+
+func (p RatingPrior) average(sum int, count int) float64 {
+	if count <= 0 {
+		return 0
+	}
+
+	return float64(sum) / float64(count)
+}
+
+func (p RatingPrior) weighted(sum int, count int) float64 {
+	if p.Weight+float64(count) <= 0 {
+		return 0
+	}
+
+	return (p.Weight*p.Mean + float64(sum)) / (p.Weight + float64(count))
+}
+
+// stats builds the rating stats of a game from the review totals.
+func (p RatingPrior) stats(sum int, count int, histogram map[string]int) RatingStats {
+	stats := RatingStats{
+		Sum:       sum,
+		Count:     count,
+		Histogram: map[string]int{},
+		Average:   p.average(sum, count),
+		Weighted:  p.weighted(sum, count),
+	}
+
+	for rating := MinRating; rating <= MaxRating; rating++ {
+		bucket := strconv.Itoa(rating)
+		stats.Histogram[bucket] = histogram[bucket]
+	}
+
+	return stats
+}
+
+// subScoreStats derives the averages of the sub-score totals and fills their
+// histograms, leaving out the aspects no review scored.
+func subScoreStats(totals map[string]SubScoreStats) map[string]SubScoreStats {
+	stats := map[string]SubScoreStats{}
+
+	for _, dimension := range SubScoreDimensions {
+		total := totals[dimension]
+
+		if total.Count <= 0 {
+			continue
+		}
+
+		dimensionStats := SubScoreStats{
+			Sum:       total.Sum,
+			Count:     total.Count,
+			Histogram: map[string]int{},
+			Average:   float64(total.Sum) / float64(total.Count),
+		}
+
+		for rating := MinRating; rating <= MaxRating; rating++ {
+			bucket := strconv.Itoa(rating)
+			dimensionStats.Histogram[bucket] = total.Histogram[bucket]
+		}
+
+		stats[dimension] = dimensionStats
+	}
+
+	return stats
+}
+
+func sameSubScoreStats(a map[string]SubScoreStats, b map[string]SubScoreStats) bool {
+	for _, dimension := range SubScoreDimensions {
+		x, y := a[dimension], b[dimension]
+
+		if x.Sum != y.Sum || x.Count != y.Count || math.Abs(x.Average-y.Average) > 1e-9 {
+			return false
+		}
+
+		for rating := MinRating; rating <= MaxRating; rating++ {
+			bucket := strconv.Itoa(rating)
+
+			if x.Histogram[bucket] != y.Histogram[bucket] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// RatingAveragesUpdate is an update pipeline recomputing the average and the
+// weighted rating of a game from its stored sum and count, as well as the
+// averages of the given sub-scores, and stamps when they changed. It is applied
+// after the totals are incremented so the stored values never depend on stale reads.
+func RatingAveragesUpdate(prior RatingPrior, subScores ...string) bson.A {
+	sum := bson.D{{"$ifNull", bson.A{"$rating.sum", 0}}}
+	count := bson.D{{"$ifNull", bson.A{"$rating.count", 0}}}
+
+	set := bson.D{
+		{"rating.average", averageExpression(sum, count)},
+		{"rating.weighted", bson.D{{"$cond", bson.A{
+			bson.D{{"$gt", bson.A{bson.D{{"$add", bson.A{prior.Weight, count}}}, 0}}},
+			bson.D{{"$divide", bson.A{
+				bson.D{{"$add", bson.A{prior.Weight * prior.Mean, sum}}},
+				bson.D{{"$add", bson.A{prior.Weight, count}}},
+			}}},
+			0,
+		}}}},
+	}
+
+	for _, dimension := range subScores {
+		path := "rating.subScores." + dimension
+
+		set = append(set, bson.E{Key: path + ".average", Value: averageExpression(
+			bson.D{{"$ifNull", bson.A{"$" + path + ".sum", 0}}},
+			bson.D{{"$ifNull", bson.A{"$" + path + ".count", 0}}},
+		)})
+	}
+
+	set = append(set, bson.E{Key: "rating.updatedAt", Value: "$$NOW"})
+
+	return bson.A{
+		bson.D{{"$set", set}},
+	}
+}
+
+func averageExpression(sum bson.D, count bson.D) bson.D {
+	return bson.D{{"$cond", bson.A{
+		bson.D{{"$gt", bson.A{count, 0}}},
+		bson.D{{"$divide", bson.A{sum, count}}},
+		0,
+	}}}
+}
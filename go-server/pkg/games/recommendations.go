@@ -0,0 +1,402 @@
+package games
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SimilarGamesIntervalMinutes is how often the similar games are recomputed.
+	SimilarGamesIntervalMinutes = "SIMILAR_GAMES_INTERVAL_MINUTES"
+
+	// UserRecommendationsIntervalMinutes is how often the recommendations of users are recomputed.
+	UserRecommendationsIntervalMinutes = "USER_RECOMMENDATIONS_INTERVAL_MINUTES"
+
+	defaultSimilarGamesIntervalMinutes        = 360
+	defaultUserRecommendationsIntervalMinutes = 360
+
+	// a review at or above this rating counts as the user liking the game
+	highRating = 4
+	// how many similar games are stored per game
+	similarGamesStored = 20
+	// users liking more games than this are left out of the co-review counts,
+	// they would add a pair for almost every game
+	maxLikesPerUser = 500
+
+	// how many recommendations are stored per user
+	userRecommendationsStored = 50
+	// how many of its most similar games are kept per game by the collaborative model
+	neighboursPerGame = 50
+	// how many of their favourite genres the top rated games of a new user are picked from
+	preferredGenres = 3
+	// preference for a game the user upvoted a review of without reviewing it
+	upVotePreference = 0.5
+	// share of the games each user liked that the evaluation holds out
+	evaluationHoldOut = 0.2
+
+	contentWeight   = 0.5
+	coReviewWeight  = 0.5
+	genresWeight    = 0.6
+	developerWeight = 0.2
+	publisherWeight = 0.2
+)
+
+func getSimilarGamesInterval() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(SimilarGamesIntervalMinutes))
+
+	if err != nil || minutes < 1 {
+		minutes = defaultSimilarGamesIntervalMinutes
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+func getUserRecommendationsInterval() time.Duration {
+	minutes, err := strconv.Atoi(os.Getenv(UserRecommendationsIntervalMinutes))
+
+	if err != nil || minutes < 1 {
+		minutes = defaultUserRecommendationsIntervalMinutes
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+type gamePair struct {
+	a primitive.ObjectID
+	b primitive.ObjectID
+}
+
+func newGamePair(a primitive.ObjectID, b primitive.ObjectID) gamePair {
+	if a.Hex() > b.Hex() {
+		a, b = b, a
+	}
+
+	return gamePair{a: a, b: b}
+}
+
+// coReviewCounts counts, for every pair of games, the users who liked both, and
+// for every game the users who liked it.
+func coReviewCounts(likes map[string][]primitive.ObjectID) (map[gamePair]int, map[primitive.ObjectID]int) {
+	pairs := make(map[gamePair]int)
+	liked := make(map[primitive.ObjectID]int)
+
+	for _, gameIds := range likes {
+		if len(gameIds) > maxLikesPerUser {
+			continue
+		}
+
+		for i, a := range gameIds {
+			liked[a]++
+
+			for _, b := range gameIds[i+1:] {
+				pairs[newGamePair(a, b)]++
+			}
+		}
+	}
+
+	return pairs, liked
+}
+
+// computeSimilarGames scores every pair of games on their shared genres,
+// developer and publisher and on how many users liked both, the co-review count
+// being normalised like a cosine similarity. likes maps a user to the distinct
+// games they rated highly. Games are compared pairwise, which is fine for the
+// size of the catalogue but would need blocking by genre if it grows a lot.
+func computeSimilarGames(games []Game, likes map[string][]primitive.ObjectID) map[primitive.ObjectID][]SimilarGame {
+	pairs, liked := coReviewCounts(likes)
+
+	similar := make(map[primitive.ObjectID][]SimilarGame, len(games))
+
+	for i := range games {
+		for j := i + 1; j < len(games); j++ {
+			a, b := &games[i], &games[j]
+
+			var reasons []string
+			content := 0.0
+
+			if genres := genresJaccard(a.Genres, b.Genres); genres > 0 {
+				content += genresWeight * genres
+				reasons = append(reasons, "genres")
+			}
+
+			if a.Developer != "" && strings.EqualFold(a.Developer, b.Developer) {
+				content += developerWeight
+				reasons = append(reasons, "developer")
+			}
+
+			if a.Publisher != "" && strings.EqualFold(a.Publisher, b.Publisher) {
+				content += publisherWeight
+				reasons = append(reasons, "publisher")
+			}
+
+			coReview := 0.0
+
+			if count := pairs[newGamePair(a.Id, b.Id)]; count > 0 {
+				coReview = float64(count) / math.Sqrt(float64(liked[a.Id]*liked[b.Id]))
+				reasons = append(reasons, "co-reviewed")
+			}
+
+			score := contentWeight*content + coReviewWeight*coReview
+
+			if score <= 0 {
+				continue
+			}
+
+			similar[a.Id] = append(similar[a.Id], SimilarGame{GameId: b.Id, Score: score, Reasons: reasons})
+			similar[b.Id] = append(similar[b.Id], SimilarGame{GameId: a.Id, Score: score, Reasons: reasons})
+		}
+	}
+
+	for id, games := range similar {
+		sort.SliceStable(games, func(i, j int) bool {
+			return games[i].Score > games[j].Score
+		})
+
+		if len(games) > similarGamesStored {
+			games = games[:similarGamesStored]
+		}
+
+		similar[id] = games
+	}
+
+	return similar
+}
+
+func genresJaccard(a []*EmbeddedGameGenre, b []*EmbeddedGameGenre) float64 {
+	slugs := make(map[string]bool)
+
+	for _, genre := range a {
+		if genre != nil {
+			slugs[genre.Slug] = true
+		}
+	}
+
+	shared := 0
+	union := len(slugs)
+
+	for _, genre := range b {
+		if genre == nil {
+			continue
+		}
+
+		if slugs[genre.Slug] {
+			shared++
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(union)
+}
+
+// reviewPreference maps a star rating to a preference, 3 stars being neutral.
+func reviewPreference(rating int) float64 {
+	if _, ok := RatingBucket(rating); !ok {
+		// no stars to go by
+		return 0
+	}
+
+	neutral := float64(MinRating+MaxRating) / 2
+
+	return (float64(rating) - neutral) / (float64(MaxRating) - neutral)
+}
+
+// mergeInteractions keeps one interaction per user and game. A review says more
+// than votes on the reviews of others, so it wins over them.
+func mergeInteractions(interactions []GameInteraction) map[string]map[primitive.ObjectID]GameInteraction {
+	merged := make(map[string]map[primitive.ObjectID]GameInteraction)
+
+	for _, interaction := range interactions {
+		games, ok := merged[interaction.UserId]
+		if !ok {
+			games = make(map[primitive.ObjectID]GameInteraction)
+			merged[interaction.UserId] = games
+		}
+
+		current, ok := games[interaction.GameId]
+
+		if ok && current.Source == "review" && interaction.Source != "review" {
+			continue
+		}
+
+		if ok && current.Source == interaction.Source && current.Preference >= interaction.Preference {
+			continue
+		}
+
+		games[interaction.GameId] = interaction
+	}
+
+	return merged
+}
+
+func preferencesOf(games map[primitive.ObjectID]GameInteraction) map[primitive.ObjectID]float64 {
+	preferences := make(map[primitive.ObjectID]float64, len(games))
+
+	for gameId, interaction := range games {
+		preferences[gameId] = interaction.Preference
+	}
+
+	return preferences
+}
+
+type neighbour struct {
+	gameId     primitive.ObjectID
+	similarity float64
+}
+
+// trainRecommendationModel builds an item to item collaborative filtering model,
+// two games being similar when the same users liked both. Only the closest
+// neighbours of each game are kept.
+func trainRecommendationModel(preferences map[string]map[primitive.ObjectID]float64) map[primitive.ObjectID][]neighbour {
+	likes := make(map[string][]primitive.ObjectID, len(preferences))
+
+	for userId, games := range preferences {
+		for gameId, preference := range games {
+			if preference > 0 {
+				likes[userId] = append(likes[userId], gameId)
+			}
+		}
+	}
+
+	pairs, liked := coReviewCounts(likes)
+
+	model := make(map[primitive.ObjectID][]neighbour)
+
+	for pair, count := range pairs {
+		similarity := float64(count) / math.Sqrt(float64(liked[pair.a]*liked[pair.b]))
+
+		model[pair.a] = append(model[pair.a], neighbour{gameId: pair.b, similarity: similarity})
+		model[pair.b] = append(model[pair.b], neighbour{gameId: pair.a, similarity: similarity})
+	}
+
+	for gameId, neighbours := range model {
+		sort.Slice(neighbours, func(i, j int) bool {
+			if neighbours[i].similarity != neighbours[j].similarity {
+				return neighbours[i].similarity > neighbours[j].similarity
+			}
+
+			return neighbours[i].gameId.Hex() < neighbours[j].gameId.Hex()
+		})
+
+		if len(neighbours) > neighboursPerGame {
+			model[gameId] = neighbours[:neighboursPerGame]
+		}
+	}
+
+	return model
+}
+
+// recommendGames ranks the games the user has not interacted with by their
+// similarity to the games they did, weighted by how much they liked them.
+func recommendGames(model map[primitive.ObjectID][]neighbour, preferences map[primitive.ObjectID]float64, limit int) []RecommendedGame {
+	scores := make(map[primitive.ObjectID]float64)
+
+	for gameId, preference := range preferences {
+		for _, neighbour := range model[gameId] {
+			if _, seen := preferences[neighbour.gameId]; seen {
+				continue
+			}
+
+			scores[neighbour.gameId] += neighbour.similarity * preference
+		}
+	}
+
+	recommended := []RecommendedGame{}
+
+	for gameId, score := range scores {
+		if score > 0 {
+			recommended = append(recommended, RecommendedGame{GameId: gameId, Score: score, Reason: "collaborative"})
+		}
+	}
+
+	sort.Slice(recommended, func(i, j int) bool {
+		if recommended[i].Score != recommended[j].Score {
+			return recommended[i].Score > recommended[j].Score
+		}
+
+		return recommended[i].GameId.Hex() < recommended[j].GameId.Hex()
+	})
+
+	if len(recommended) > limit {
+		recommended = recommended[:limit]
+	}
+
+	return recommended
+}
+
+// evaluateRecommendations measures precision@k and recall@k by holding out the
+// most recently liked games of every user with at least two liked games and
+// training the model without them.
+func evaluateRecommendations(interactions map[string]map[primitive.ObjectID]GameInteraction, k int) RecommendationEvaluation {
+	train := make(map[string]map[primitive.ObjectID]float64, len(interactions))
+	heldOut := make(map[string]map[primitive.ObjectID]bool)
+
+	for userId, games := range interactions {
+		train[userId] = preferencesOf(games)
+
+		var liked []GameInteraction
+		for _, interaction := range games {
+			if interaction.Preference > 0 {
+				liked = append(liked, interaction)
+			}
+		}
+
+		if len(liked) < 2 {
+			continue
+		}
+
+		sort.Slice(liked, func(i, j int) bool {
+			if !liked[i].At.Equal(liked[j].At) {
+				return liked[i].At.Before(liked[j].At)
+			}
+
+			return liked[i].GameId.Hex() < liked[j].GameId.Hex()
+		})
+
+		count := int(math.Ceil(float64(len(liked)) * evaluationHoldOut))
+
+		heldOut[userId] = make(map[primitive.ObjectID]bool, count)
+		for _, interaction := range liked[len(liked)-count:] {
+			heldOut[userId][interaction.GameId] = true
+			delete(train[userId], interaction.GameId)
+		}
+	}
+
+	model := trainRecommendationModel(train)
+
+	evaluation := RecommendationEvaluation{K: k}
+	precision, recall := 0.0, 0.0
+
+	for userId, games := range heldOut {
+		hits := 0
+
+		for _, recommended := range recommendGames(model, train[userId], k) {
+			if games[recommended.GameId] {
+				hits++
+			}
+		}
+
+		evaluation.Users++
+		evaluation.HeldOut += len(games)
+		evaluation.Hits += hits
+
+		precision += float64(hits) / float64(k)
+		recall += float64(hits) / float64(len(games))
+	}
+
+	if evaluation.Users > 0 {
+		evaluation.PrecisionAtK = precision / float64(evaluation.Users)
+		evaluation.RecallAtK = recall / float64(evaluation.Users)
+	}
+
+	return evaluation
+}
@@ -4,16 +4,60 @@ import (
 	"context"
 	"github.com/go-playground/validator/v10"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"log"
+	"math"
+	"regexp"
+	"strings"
+	"time"
 )
 
 const (
-	gameGenreCollection = "genres"
-	gamesCollection     = "games"
+	gameGenreCollection           = "genres"
+	gamesCollection               = "games"
+	franchisesCollection          = "franchises"
+	gameRelationsCollection       = "gameRelations"
+	gameMediaCollection           = "gameMedia"
+	gameRevisionsCollection       = "gameRevisions"
+	genreRedirectsCollection      = "genreRedirects"
+	gameSlugRedirectsCollection   = "gameSlugRedirects"
+	gameDuplicateFlagsCollection  = "gameDuplicateFlags"
+	reviewsCollection             = "reviews"
+	votesCollection               = "votes"
+	gameSimilarityCollection      = "gameRecommendations"
+	userRecommendationsCollection = "userRecommendations"
+	gameChartsCollection          = "gameCharts"
+	contentPreferencesCollection  = "contentPreferences"
+	commentsCollection            = "comments"
+	commentVotesCollection        = "commentVotes"
+	gameDevelopersCollection      = "gameDevelopers"
+	reviewRevisionsCollection     = "reviewRevisions"
+	reviewReportsCollection       = "reviewReports"
+	moderationCasesCollection     = "moderationCases"
+	collectionsCollection         = "collections"
+	collectionItemsCollection     = "collectionItems"
 )
 
+// gameSortKeys are the orders games can be listed in, the value of Pagination.SortBy.
+var gameSortKeys = map[string]bson.D{
+	"newest":  {{"createdAt", -1}, {"updatedAt", -1}},
+	"rating":  {{"rating.weighted", -1}, {"rating.count", -1}, {"createdAt", -1}},
+	"average": {{"rating.average", -1}, {"rating.count", -1}, {"createdAt", -1}},
+	"reviews": {{"rating.count", -1}, {"rating.weighted", -1}, {"createdAt", -1}},
+	// by the average of a sub-score
+	SubScoreGameplay:    subScoreSort(SubScoreGameplay),
+	SubScoreStory:       subScoreSort(SubScoreStory),
+	SubScoreGraphics:    subScoreSort(SubScoreGraphics),
+	SubScoreAudio:       subScoreSort(SubScoreAudio),
+	SubScorePerformance: subScoreSort(SubScorePerformance),
+}
+
+func subScoreSort(dimension string) bson.D {
+	return bson.D{{"rating.subScores." + dimension + ".average", -1}, {"rating.subScores." + dimension + ".count", -1}, {"createdAt", -1}}
+}
+
 type GameRepositoryImpl struct {
 	mongoDbClient *mongo.Client
 	validate      *validator.Validate
@@ -26,6 +70,60 @@ func NewGameRepositoryImpl(mongoDbClient *mongo.Client) *GameRepositoryImpl {
 	}
 }
 
+// ensureIndexes makes game slugs and the ids of each external provider unique.
+// Games added before slugs existed have none and are left out until they are
+// backfilled, as are games unknown to a provider.
+func (g *GameRepositoryImpl) ensureIndexes(ctx context.Context, externalIdProviders []string) error {
+	db := g.mongoDbClient.Database("test")
+
+	gameIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{"slug", 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.D{{"slug", bson.D{{"$type", "string"}}}}),
+		},
+	}
+
+	for _, provider := range externalIdProviders {
+		field := "externalIds." + provider
+
+		gameIndexes = append(gameIndexes, mongo.IndexModel{
+			Keys: bson.D{{field, 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.D{{field, bson.D{{"$type", "string"}}}}),
+		})
+	}
+
+	_, err := db.Collection(gamesCollection).Indexes().CreateMany(ctx, gameIndexes)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(gameSlugRedirectsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"from", 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{"gameId", 1}}},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(gameDuplicateFlagsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"gameId", 1}, {"candidateId", 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{"status", 1}, {"score", -1}, {"createdAt", -1}}},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
 func (g *GameRepositoryImpl) saveGameGenre(ctx context.Context, genre *GameGenre) error {
 	_, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).InsertOne(ctx, genre)
 
@@ -37,20 +135,63 @@ func (g *GameRepositoryImpl) saveGameGenre(ctx context.Context, genre *GameGenre
 	return nil
 }
 
+// updateGameGenre saves the genre and keeps the title embedded in games in sync.
 func (g *GameRepositoryImpl) updateGameGenre(ctx context.Context, genre *GameGenre) error {
-	filter := bson.D{{"slug", genre.Slug}}
-	opts := options.Update().SetUpsert(true)
-	update := bson.D{{"$set", genre}}
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := g.mongoDbClient.Database("test")
+
+		filter := bson.D{{"slug", genre.Slug}, {"isDeleted", false}}
+		update := bson.D{{"$set", bson.D{
+			{"title", genre.Title},
+			{"desc", genre.Desc},
+			{"parent", genre.Parent},
+			{"updatedAt", genre.UpdatedAt},
+		}}}
+
+		res, err := db.Collection(gameGenreCollection).UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.MatchedCount == 0 {
+			return nil, ErrNotFound
+		}
+
+		return nil, g.setEmbeddedGenre(sessCtx, genre.Slug, genre)
+	})
 
-	_, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).UpdateOne(ctx, filter, update, opts)
+	if err == ErrNotFound {
+		return ErrNotFound
+	}
 
 	if err != nil {
+		log.Println(err)
 		return UnknownError
 	}
 
 	return nil
 }
 
+// setEmbeddedGenre replaces the genre embedded under slug in every game with genre.
+func (g *GameRepositoryImpl) setEmbeddedGenre(ctx context.Context, slug string, genre *GameGenre) error {
+	filter := bson.D{{"genres.slug", slug}}
+	update := bson.D{{"$set", bson.D{{"genres.$[genre]", EmbeddedGameGenre{Title: genre.Title, Slug: genre.Slug}}}}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.D{{"genre.slug", slug}}},
+	})
+
+	_, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateMany(ctx, filter, update, opts)
+
+	return err
+}
+
 func (g *GameRepositoryImpl) getGameGenre(ctx context.Context, slug string) (*GameGenre, error) {
 	var gameGenre GameGenre
 	filter := bson.D{{"slug", slug}, {"isDeleted", false}}
@@ -99,7 +240,7 @@ func (g *GameRepositoryImpl) getAllGameGenres(ctx context.Context, pagination *P
 
 	response.Data = gameGenres
 
-	count, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).CountDocuments(ctx, bson.D{}, options.Count())
+	count, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).CountDocuments(ctx, filter, options.Count())
 
 	if err != nil {
 		return nil, UnknownError
@@ -115,25 +256,32 @@ func (g *GameRepositoryImpl) getAllGameGenres(ctx context.Context, pagination *P
 }
 
 func (g *GameRepositoryImpl) deleteGameGenre(ctx context.Context, slug string) error {
-	filter := bson.D{{"slug", slug}}
-
-	update := bson.D{{"$set", bson.D{{"isDeleted", true}}}}
+	filter := bson.D{{"slug", slug}, {"isDeleted", false}}
 
-	opts := options.Update().SetUpsert(true)
+	update := bson.D{{"$set", bson.D{{"isDeleted", true}, {"deletedAt", time.Now()}}}}
 
-	_, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).UpdateOne(ctx, filter, update, opts)
+	res, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return UnknownError
 	}
 
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
 	return nil
 }
 
 func (g *GameRepositoryImpl) getGame(ctx context.Context, id string) (*Game, error) {
 	var game Game
 
-	err := g.mongoDbClient.Database("test").Collection(gamesCollection).FindOne(ctx, bson.D{{"_id", id}}).Decode(&game)
+	rawId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	err = g.mongoDbClient.Database("test").Collection(gamesCollection).FindOne(ctx, bson.D{{"_id", rawId}}).Decode(&game)
 	if err != nil {
 		return nil, ErrNotFound
 	}
@@ -141,103 +289,2444 @@ func (g *GameRepositoryImpl) getGame(ctx context.Context, id string) (*Game, err
 	return &game, nil
 }
 
-func (g *GameRepositoryImpl) getAllGames(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Game], error) {
+func (g *GameRepositoryImpl) getGameBySlug(ctx context.Context, slug string) (*Game, error) {
+	var game Game
 
-	var games []Game
+	err := g.mongoDbClient.Database("test").Collection(gamesCollection).FindOne(ctx, bson.D{{"slug", slug}}).Decode(&game)
+	if err != nil {
+		return nil, ErrNotFound
+	}
 
-	response := &PaginatedResponse[Game]{}
+	return &game, nil
+}
 
-	limit := int64(pagination.Limit)
-	skip := int64(pagination.Offset)
+func (g *GameRepositoryImpl) getGameSlugRedirect(ctx context.Context, slug string) (*GameSlugRedirect, error) {
+	var redirect GameSlugRedirect
 
-	opts := options.Find().SetSort(bson.D{{"createdAt", -1}, {"updatedAt", -1}}).SetLimit(limit).SetSkip(skip)
+	err := g.mongoDbClient.Database("test").Collection(gameSlugRedirectsCollection).FindOne(ctx, bson.D{{"from", slug}}).Decode(&redirect)
+	if err != nil {
+		return nil, ErrNotFound
+	}
 
-	filter := bson.D{{"isDeleted", false}}
+	return &redirect, nil
+}
 
-	if pagination.QueryFilters != nil {
+// getGameSlugOwner returns the id of the game using the slug, as its current
+// slug or as an old one that redirects to it. Games in the trash keep theirs.
+func (g *GameRepositoryImpl) getGameSlugOwner(ctx context.Context, slug string) (primitive.ObjectID, error) {
+	db := g.mongoDbClient.Database("test")
 
-		for key, value := range pagination.QueryFilters {
-			log.Println("Key: ", key, " Value: ", value)
-			filter = append(filter, bson.E{Key: key, Value: value})
-		}
+	var game struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+
+	opts := options.FindOne().SetProjection(bson.D{{"_id", 1}})
+
+	err := db.Collection(gamesCollection).FindOne(ctx, bson.D{{"slug", slug}}, opts).Decode(&game)
+
+	if err == nil {
+		return game.Id, nil
+	}
+
+	if err != mongo.ErrNoDocuments {
+		log.Println(err)
+		return primitive.NilObjectID, UnknownError
+	}
+
+	var redirect GameSlugRedirect
+
+	err = db.Collection(gameSlugRedirectsCollection).FindOne(ctx, bson.D{{"from", slug}}).Decode(&redirect)
+
+	if err == mongo.ErrNoDocuments {
+		return primitive.NilObjectID, ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return primitive.NilObjectID, UnknownError
 	}
 
+	return redirect.GameId, nil
+}
+
+func (g *GameRepositoryImpl) getGamesWithoutSlug(ctx context.Context) ([]Game, error) {
+	filter := bson.D{{"slug", bson.D{{"$not", bson.D{{"$type", "string"}}}}}}
+	opts := options.Find().
+		SetProjection(bson.D{{"_id", 1}, {"title", 1}, {"releaseDate", 1}}).
+		SetSort(bson.D{{"createdAt", 1}})
+
 	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, filter, opts)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var games []Game
+
+	if err = cursor.All(ctx, &games); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return games, nil
+}
+
+// setGameSlug gives a slug to a game that has none.
+func (g *GameRepositoryImpl) setGameSlug(ctx context.Context, id primitive.ObjectID, slug string) error {
+	filter := bson.D{{"_id", id}, {"slug", bson.D{{"$not", bson.D{{"$type", "string"}}}}}}
+	update := bson.D{{"$set", bson.D{{"slug", slug}}}}
+
+	_, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(ctx, filter, update)
+
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrGameSlugTaken
+	}
 
 	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// getGamesByExternalIds returns the games, trashed ones included, having any of
+// the external ids.
+func (g *GameRepositoryImpl) getGamesByExternalIds(ctx context.Context, externalIds map[string]string) ([]Game, error) {
+	ids := bson.A{}
 
+	for provider, id := range externalIds {
+		ids = append(ids, bson.D{{"externalIds." + provider, id}})
+	}
+
+	if len(ids) == 0 {
+		return []Game{}, nil
+	}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, bson.D{{"$or", ids}})
+	if err != nil {
+		log.Println(err)
 		return nil, UnknownError
 	}
 
-	err = cursor.All(ctx, &games)
+	var games []Game
+
+	if err = cursor.All(ctx, &games); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return games, nil
+}
+
+// getGameByTitle returns the game with the title, whatever its case, released
+// the same year.
+func (g *GameRepositoryImpl) getGameByTitle(ctx context.Context, title string, releaseDate int) (*Game, error) {
+	var game Game
+
+	filter := bson.D{
+		{"title", bson.D{{"$regex", "^" + regexp.QuoteMeta(strings.TrimSpace(title)) + "$"}, {"$options", "i"}}},
+		{"releaseDate", releaseDate},
+		{"isDeleted", false},
+	}
+
+	err := g.mongoDbClient.Database("test").Collection(gamesCollection).FindOne(ctx, filter).Decode(&game)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &game, nil
+}
+
+func (g *GameRepositoryImpl) setGameExternalIds(ctx context.Context, id primitive.ObjectID, externalIds map[string]string) error {
+	set := bson.D{}
+
+	for provider, externalId := range externalIds {
+		set = append(set, bson.E{Key: "externalIds." + provider, Value: externalId})
+	}
+
+	_, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$set", set}})
+
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrExternalIdConflict
+	}
 
 	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// getDuplicateCandidates returns the games that could be the same as game: the
+// ones released around the same year, from the same developer or whose title
+// starts with the same word.
+func (g *GameRepositoryImpl) getDuplicateCandidates(ctx context.Context, game *Game) ([]Game, error) {
+	candidates := bson.A{}
+
+	if game.ReleaseDate > 0 {
+		candidates = append(candidates, bson.D{{"releaseDate", bson.D{
+			{"$gte", game.ReleaseDate - 1},
+			{"$lte", game.ReleaseDate + 1},
+		}}})
+	}
 
+	if developer := strings.TrimSpace(game.Developer); developer != "" {
+		candidates = append(candidates, bson.D{{"developer", bson.D{
+			{"$regex", "^" + regexp.QuoteMeta(developer) + "$"},
+			{"$options", "i"},
+		}}})
+	}
+
+	if words := strings.Fields(normalizeTitle(game.Title)); len(words) > 0 {
+		candidates = append(candidates, bson.D{{"title", bson.D{
+			{"$regex", "^(the |a |an )?" + regexp.QuoteMeta(words[0])},
+			{"$options", "i"},
+		}}})
+	}
+
+	if len(candidates) == 0 {
+		return []Game{}, nil
+	}
+
+	filter := bson.D{{"_id", bson.D{{"$ne", game.Id}}}, {"isDeleted", false}, {"$or", candidates}}
+	opts := options.Find().
+		SetProjection(bson.D{{"_id", 1}, {"title", 1}, {"developer", 1}, {"releaseDate", 1}}).
+		SetLimit(1000)
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, filter, opts)
+	if err != nil {
+		log.Println(err)
 		return nil, UnknownError
 	}
 
-	if len(games) == 0 {
-		return &PaginatedResponse[Game]{
-			TotalItems:   0,
-			TotalPages:   0,
-			CurrentPage:  0,
-			ItemsPerPage: 0,
-			HasMore:      false,
-			Data:         []Game{},
-		}, nil
+	var games []Game
+
+	if err = cursor.All(ctx, &games); err != nil {
+		log.Println(err)
+		return nil, UnknownError
 	}
 
-	response.Data = games
+	return games, nil
+}
 
-	count, err := g.mongoDbClient.Database("test").Collection(gamesCollection).CountDocuments(ctx, filter, options.Count())
+// saveDuplicateFlags adds the flags, a pair flagged before keeps its status so
+// a dismissed flag does not come back.
+func (g *GameRepositoryImpl) saveDuplicateFlags(ctx context.Context, flags []DuplicateFlag) error {
+	models := make([]mongo.WriteModel, 0, len(flags))
+
+	for _, flag := range flags {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{"gameId", flag.GameId}, {"candidateId", flag.CandidateId}}).
+			SetUpdate(bson.D{{"$setOnInsert", flag}}).
+			SetUpsert(true))
+	}
+
+	_, err := g.mongoDbClient.Database("test").Collection(gameDuplicateFlagsCollection).BulkWrite(ctx, models)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getDuplicateFlags(ctx context.Context, status DuplicateStatus, pagination *Pagination) (*PaginatedResponse[DuplicateFlag], error) {
+	var flags []DuplicateFlag
+
+	collection := g.mongoDbClient.Database("test").Collection(gameDuplicateFlagsCollection)
+
+	filter := bson.D{{"status", status}}
+	opts := options.Find().
+		SetSort(bson.D{{"score", -1}, {"createdAt", -1}}).
+		SetLimit(int64(pagination.Limit)).
+		SetSkip(int64(pagination.Offset))
+
+	cursor, err := collection.Find(ctx, filter, opts)
 
 	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
 
+	if err = cursor.All(ctx, &flags); err != nil {
+		log.Println(err)
 		return nil, UnknownError
 	}
 
-	response.TotalItems = int(count)
-	response.TotalPages = int(count) / pagination.Limit
-	response.CurrentPage = pagination.Offset / pagination.Limit
-	response.ItemsPerPage = pagination.Limit
-	response.HasMore = response.TotalPages > response.CurrentPage
+	if flags == nil {
+		flags = []DuplicateFlag{}
+	}
 
-	return response, nil
+	count, err := collection.CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[DuplicateFlag]{
+		Data:         flags,
+		TotalItems:   int(count),
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		ItemsPerPage: pagination.Limit,
+		HasMore:      int(count) > pagination.Offset+pagination.Limit,
+	}, nil
 }
 
-func (g *GameRepositoryImpl) saveGame(ctx context.Context, game *Game) error {
-	_, err := g.mongoDbClient.Database("test").Collection(gamesCollection).InsertOne(ctx, game)
+func (g *GameRepositoryImpl) updateDuplicateFlag(ctx context.Context, id primitive.ObjectID, status DuplicateStatus, reviewerId string) error {
+	update := bson.D{{"$set", bson.D{
+		{"status", status},
+		{"reviewerId", reviewerId},
+		{"reviewedAt", time.Now()},
+	}}}
+
+	res, err := g.mongoDbClient.Database("test").Collection(gameDuplicateFlagsCollection).UpdateByID(ctx, id, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrDuplicateFlagNotFound
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) setGameTranslation(ctx context.Context, id primitive.ObjectID, locale string, translation *GameTranslation) error {
+	filter := bson.D{{"_id", id}, {"isDeleted", false}}
+	update := bson.D{{"$set", bson.D{
+		{"translations." + locale, translation},
+		{"updatedAt", time.Now()},
+	}}}
+
+	res, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(ctx, filter, update)
 
 	if err != nil {
+		log.Println(err)
 		return UnknownError
 	}
 
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
 	return nil
 }
 
-func (g *GameRepositoryImpl) updateGame(ctx context.Context, game *Game) error {
+func (g *GameRepositoryImpl) deleteGameTranslation(ctx context.Context, id primitive.ObjectID, locale string) error {
+	field := "translations." + locale
 
-	filter := bson.D{{"_id", game.Id}}
-	opts := options.Update().SetUpsert(true)
-	update := bson.D{{"$set", game}}
+	filter := bson.D{{"_id", id}, {field, bson.D{{"$exists", true}}}}
+	update := bson.D{
+		{"$unset", bson.D{{field, ""}}},
+		{"$set", bson.D{{"updatedAt", time.Now()}}},
+	}
 
-	_, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(ctx, filter, update, opts)
+	res, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(ctx, filter, update)
 
 	if err != nil {
+		log.Println(err)
 		return UnknownError
 	}
 
+	if res.MatchedCount == 0 {
+		return ErrTranslationNotFound
+	}
+
 	return nil
 }
 
-func (g *GameRepositoryImpl) deleteGame(ctx context.Context, id string) error {
-	filter := bson.D{{"_id", id}}
-	opts := options.Update().SetUpsert(true)
-	update := bson.D{{"$set", bson.D{{"IsDeleted", true}}}}
+func (g *GameRepositoryImpl) setGenreTranslation(ctx context.Context, slug string, locale string, translation *GenreTranslation) error {
+	filter := bson.D{{"slug", slug}, {"isDeleted", false}}
+	update := bson.D{{"$set", bson.D{
+		{"translations." + locale, translation},
+		{"updatedAt", time.Now()},
+	}}}
+
+	res, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) deleteGenreTranslation(ctx context.Context, slug string, locale string) error {
+	field := "translations." + locale
+
+	filter := bson.D{{"slug", slug}, {"isDeleted", false}, {field, bson.D{{"$exists", true}}}}
+	update := bson.D{
+		{"$unset", bson.D{{field, ""}}},
+		{"$set", bson.D{{"updatedAt", time.Now()}}},
+	}
+
+	res, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrTranslationNotFound
+	}
+
+	return nil
+}
+
+// missingTranslationFilter matches the documents lacking any of the fields in
+// any of the locales, an empty field counts as missing.
+func missingTranslationFilter(locales []string, fields ...string) bson.D {
+	missing := bson.A{}
+
+	for _, locale := range locales {
+		for _, field := range fields {
+			missing = append(missing, bson.D{{"translations." + locale + "." + field, bson.D{{"$in", bson.A{nil, ""}}}}})
+		}
+	}
+
+	return bson.D{{"isDeleted", false}, {"$or", missing}}
+}
+
+// countMissingTranslations counts the games and the genres not fully translated to the locale.
+func (g *GameRepositoryImpl) countMissingTranslations(ctx context.Context, locale string) (int, int, error) {
+	db := g.mongoDbClient.Database("test")
+
+	games, err := db.Collection(gamesCollection).CountDocuments(ctx, missingTranslationFilter([]string{locale}, "title", "summary"))
+	if err != nil {
+		log.Println(err)
+		return 0, 0, UnknownError
+	}
+
+	genres, err := db.Collection(gameGenreCollection).CountDocuments(ctx, missingTranslationFilter([]string{locale}, "desc"))
+	if err != nil {
+		log.Println(err)
+		return 0, 0, UnknownError
+	}
+
+	return int(games), int(genres), nil
+}
+
+func (g *GameRepositoryImpl) getGamesMissingTranslations(ctx context.Context, locales []string, pagination *Pagination) (*PaginatedResponse[Game], error) {
+	var games []Game
+
+	collection := g.mongoDbClient.Database("test").Collection(gamesCollection)
+
+	filter := missingTranslationFilter(locales, "title", "summary")
+	opts := options.Find().
+		SetProjection(bson.D{{"_id", 1}, {"slug", 1}, {"title", 1}, {"translations", 1}}).
+		SetSort(bson.D{{"rating.count", -1}, {"createdAt", -1}}).
+		SetLimit(int64(pagination.Limit)).
+		SetSkip(int64(pagination.Offset))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &games); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if games == nil {
+		games = []Game{}
+	}
+
+	count, err := collection.CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[Game]{
+		Data:         games,
+		TotalItems:   int(count),
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		ItemsPerPage: pagination.Limit,
+		HasMore:      int(count) > pagination.Offset+pagination.Limit,
+	}, nil
+}
+
+func (g *GameRepositoryImpl) getGenresMissingTranslations(ctx context.Context, locales []string, pagination *Pagination) (*PaginatedResponse[GameGenre], error) {
+	var genres []GameGenre
+
+	collection := g.mongoDbClient.Database("test").Collection(gameGenreCollection)
+
+	filter := missingTranslationFilter(locales, "desc")
+	opts := options.Find().
+		SetSort(bson.D{{"slug", 1}}).
+		SetLimit(int64(pagination.Limit)).
+		SetSkip(int64(pagination.Offset))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &genres); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if genres == nil {
+		genres = []GameGenre{}
+	}
+
+	count, err := collection.CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[GameGenre]{
+		Data:         genres,
+		TotalItems:   int(count),
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		ItemsPerPage: pagination.Limit,
+		HasMore:      int(count) > pagination.Offset+pagination.Limit,
+	}, nil
+}
+
+func (g *GameRepositoryImpl) getAllGames(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Game], error) {
+
+	var games []Game
+
+	response := &PaginatedResponse[Game]{}
+
+	limit := int64(pagination.Limit)
+	skip := int64(pagination.Offset)
+
+	sort, ok := gameSortKeys[pagination.SortBy]
+	if !ok {
+		sort = gameSortKeys["newest"]
+	}
+
+	opts := options.Find().SetSort(sort).SetLimit(limit).SetSkip(skip)
+
+	filter := bson.D{{"isDeleted", false}}
+
+	filter = append(filter, contentFilterQuery(pagination.Content)...)
+
+	if pagination.QueryFilters != nil {
+
+		for key, value := range pagination.QueryFilters {
+			log.Println("Key: ", key, " Value: ", value)
+			if values, ok := value.([]string); ok {
+				filter = append(filter, bson.E{Key: key, Value: bson.D{{"$in", values}}})
+				continue
+			}
+			filter = append(filter, bson.E{Key: key, Value: value})
+		}
+	}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &games)
+
+	if err != nil {
+
+		return nil, UnknownError
+	}
+
+	if len(games) == 0 {
+		return &PaginatedResponse[Game]{
+			TotalItems:   0,
+			TotalPages:   0,
+			CurrentPage:  0,
+			ItemsPerPage: 0,
+			HasMore:      false,
+			Data:         []Game{},
+		}, nil
+	}
+
+	response.Data = games
+
+	count, err := g.mongoDbClient.Database("test").Collection(gamesCollection).CountDocuments(ctx, filter, options.Count())
+
+	if err != nil {
+
+		return nil, UnknownError
+	}
+
+	response.TotalItems = int(count)
+	response.TotalPages = int(count) / pagination.Limit
+	response.CurrentPage = pagination.Offset / pagination.Limit
+	response.ItemsPerPage = pagination.Limit
+	response.HasMore = response.TotalPages > response.CurrentPage
+
+	return response, nil
+}
+
+func (g *GameRepositoryImpl) saveGame(ctx context.Context, game *Game) error {
+	_, err := g.mongoDbClient.Database("test").Collection(gamesCollection).InsertOne(ctx, game)
+
+	if mongo.IsDuplicateKeyError(err) && strings.Contains(err.Error(), "externalIds.") {
+		return ErrGameAlreadyExists
+	}
+
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrGameSlugTaken
+	}
+
+	if err != nil {
+		return UnknownError
+	}
+
+	return nil
+}
+
+// updateGame saves the game content together with its revision. The update only
+// applies if nobody else saved a revision since the game was read. When the slug
+// changed, previousSlug keeps redirecting to the game.
+func (g *GameRepositoryImpl) updateGame(ctx context.Context, game *Game, revision *GameRevision, previousSlug string) error {
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		filter := bson.D{{"_id", game.Id}, {"revision", bson.D{{"$in", previousRevisionValues(game.Revision - 1)}}}}
+		update := bson.D{{"$set", bson.D{
+			{"title", game.Title},
+			{"slug", game.Slug},
+			{"summary", game.Summary},
+			{"releaseDate", game.ReleaseDate},
+			{"developer", game.Developer},
+			{"publisher", game.Publisher},
+			{"genres", game.Genres},
+			{"platforms", game.Platforms},
+			{"image", game.Image},
+			{"ageRating", game.AgeRating},
+			{"contentDescriptors", game.ContentDescriptors},
+			{"minimumAge", game.MinimumAge},
+			{"updatedAt", game.UpdatedAt},
+			{"revision", game.Revision},
+		}}}
+
+		res, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.MatchedCount == 0 {
+			return nil, ErrGameEditConflict
+		}
+
+		_, err = g.mongoDbClient.Database("test").Collection(gameRevisionsCollection).InsertOne(sessCtx, revision)
+		if err != nil {
+			return nil, err
+		}
+
+		if previousSlug == "" || previousSlug == game.Slug {
+			return nil, nil
+		}
+
+		return nil, g.addGameSlugRedirect(sessCtx, previousSlug, game)
+	})
+
+	if err == ErrGameEditConflict {
+		return ErrGameEditConflict
+	}
+
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrGameSlugTaken
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// addGameSlugRedirect points the old slug of a game at it. The new slug may have
+// been one of its old ones, it must not redirect anymore.
+func (g *GameRepositoryImpl) addGameSlugRedirect(ctx context.Context, from string, game *Game) error {
+	collection := g.mongoDbClient.Database("test").Collection(gameSlugRedirectsCollection)
+
+	if _, err := collection.DeleteMany(ctx, bson.D{{"from", game.Slug}, {"gameId", game.Id}}); err != nil {
+		return err
+	}
+
+	filter := bson.D{{"from", from}}
+	update := bson.D{{"$set", GameSlugRedirect{From: from, GameId: game.Id, CreatedAt: time.Now()}}}
+
+	_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+	return err
+}
+
+// games created before revisions existed have no revision field at all
+func previousRevisionValues(revision int) bson.A {
+	if revision == 0 {
+		return bson.A{0, nil}
+	}
+
+	return bson.A{revision}
+}
+
+func (g *GameRepositoryImpl) getGameRevisions(ctx context.Context, gameId primitive.ObjectID, pagination *Pagination) (*PaginatedResponse[GameRevision], error) {
+	var revisions []GameRevision
+
+	filter := bson.D{{"gameId", gameId}}
+	opts := options.Find().SetSort(bson.D{{"revision", -1}}).SetLimit(int64(pagination.Limit)).SetSkip(int64(pagination.Offset))
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gameRevisionsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &revisions)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if len(revisions) == 0 {
+		return &PaginatedResponse[GameRevision]{
+			TotalItems:   0,
+			TotalPages:   0,
+			CurrentPage:  0,
+			ItemsPerPage: 0,
+			HasMore:      false,
+			Data:         []GameRevision{},
+		}, nil
+	}
+
+	count, err := g.mongoDbClient.Database("test").Collection(gameRevisionsCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[GameRevision]{
+		Data:         revisions,
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		TotalItems:   int(count),
+		HasMore:      int(count) > (pagination.Offset + pagination.Limit),
+		ItemsPerPage: pagination.Limit,
+	}, nil
+}
+
+func (g *GameRepositoryImpl) getGameRevision(ctx context.Context, gameId primitive.ObjectID, revision int) (*GameRevision, error) {
+	var gameRevision GameRevision
+
+	filter := bson.D{{"gameId", gameId}, {"revision", revision}}
+
+	err := g.mongoDbClient.Database("test").Collection(gameRevisionsCollection).FindOne(ctx, filter).Decode(&gameRevision)
+	if err != nil {
+		return nil, ErrGameRevisionNotFound
+	}
+
+	return &gameRevision, nil
+}
+
+func (g *GameRepositoryImpl) deleteGame(ctx context.Context, id string) error {
+	rawId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	// the game moves to the trash and its reviews are hidden with it. Relations
+	// are kept so a restore brings them back, they are removed on purge.
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		filter := bson.D{{"_id", rawId}, {"isDeleted", false}}
+		update := bson.D{{"$set", bson.D{{"isDeleted", true}, {"deletedAt", time.Now()}}}}
+
+		res, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.MatchedCount == 0 {
+			return nil, ErrNotFound
+		}
+
+		reviewFilter := bson.D{{"gameId", rawId.Hex()}}
+		reviewUpdate := bson.D{{"$set", bson.D{{"gameDeleted", true}}}}
+
+		_, err = g.mongoDbClient.Database("test").Collection(reviewsCollection).UpdateMany(sessCtx, reviewFilter, reviewUpdate)
+
+		return nil, err
+	})
+
+	if err == ErrNotFound {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getGamesByIds(ctx context.Context, ids []primitive.ObjectID) ([]Game, error) {
+	var games []Game
+
+	filter := bson.D{{"_id", bson.D{{"$in", ids}}}, {"isDeleted", false}}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &games)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return games, nil
+}
+
+func (g *GameRepositoryImpl) saveFranchise(ctx context.Context, franchise *Franchise) error {
+	_, err := g.mongoDbClient.Database("test").Collection(franchisesCollection).InsertOne(ctx, franchise)
+
+	if err != nil {
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getFranchise(ctx context.Context, slug string) (*Franchise, error) {
+	var franchise Franchise
+	filter := bson.D{{"slug", slug}, {"isDeleted", false}}
+
+	err := g.mongoDbClient.Database("test").Collection(franchisesCollection).FindOne(ctx, filter).Decode(&franchise)
+	if err != nil {
+		return nil, ErrFranchiseNotFound
+	}
+
+	return &franchise, nil
+}
+
+func (g *GameRepositoryImpl) getFranchises(ctx context.Context, slugs []string) ([]Franchise, error) {
+	franchises := []Franchise{}
+
+	filter := bson.D{{"slug", bson.D{{"$in", slugs}}}, {"isDeleted", false}}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(franchisesCollection).Find(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &franchises)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return franchises, nil
+}
+
+func (g *GameRepositoryImpl) deleteFranchise(ctx context.Context, slug string) error {
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		filter := bson.D{{"slug", slug}}
+		update := bson.D{{"$set", bson.D{{"isDeleted", true}, {"updatedAt", time.Now()}}}}
+
+		_, err := g.mongoDbClient.Database("test").Collection(franchisesCollection).UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+
+		relationFilter := bson.D{{"type", RelationPartOfFranchise}, {"franchiseSlug", slug}}
+		_, err = g.mongoDbClient.Database("test").Collection(gameRelationsCollection).DeleteMany(sessCtx, relationFilter)
+
+		return nil, err
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getFranchiseGames(ctx context.Context, slug string) ([]Game, error) {
+	var relations []GameRelation
+
+	filter := bson.D{{"type", RelationPartOfFranchise}, {"franchiseSlug", slug}}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gameRelationsCollection).Find(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &relations)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	games := []Game{}
+
+	if len(relations) == 0 {
+		return games, nil
+	}
+
+	var ids []primitive.ObjectID
+	for _, relation := range relations {
+		ids = append(ids, relation.GameId)
+	}
+
+	gameFilter := bson.D{{"_id", bson.D{{"$in", ids}}}, {"isDeleted", false}}
+	opts := options.Find().SetSort(bson.D{{"releaseDate", 1}, {"createdAt", 1}})
+
+	cursor, err = g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, gameFilter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &games)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return games, nil
+}
+
+func (g *GameRepositoryImpl) saveGameRelation(ctx context.Context, relation *GameRelation) error {
+	_, err := g.mongoDbClient.Database("test").Collection(gameRelationsCollection).InsertOne(ctx, relation)
+
+	if err != nil {
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) gameRelationExists(ctx context.Context, relation *GameRelation) (bool, error) {
+	filter := bson.D{{"gameId", relation.GameId}, {"type", relation.Type}}
+
+	if relation.Type == RelationPartOfFranchise {
+		filter = append(filter, bson.E{Key: "franchiseSlug", Value: relation.FranchiseSlug})
+	} else {
+		filter = append(filter, bson.E{Key: "relatedGameId", Value: relation.RelatedGameId})
+	}
+
+	count, err := g.mongoDbClient.Database("test").Collection(gameRelationsCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		return false, UnknownError
+	}
+
+	return count > 0, nil
+}
+
+func (g *GameRepositoryImpl) getGameRelations(ctx context.Context, gameId primitive.ObjectID) ([]GameRelation, error) {
+	var relations []GameRelation
+
+	filter := bson.D{{"$or", bson.A{
+		bson.D{{"gameId", gameId}},
+		bson.D{{"relatedGameId", gameId}},
+	}}}
+	opts := options.Find().SetSort(bson.D{{"createdAt", 1}})
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gameRelationsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &relations)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return relations, nil
+}
+
+func (g *GameRepositoryImpl) deleteGameRelation(ctx context.Context, gameId primitive.ObjectID, relationId string) error {
+	id, err := primitive.ObjectIDFromHex(relationId)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	filter := bson.D{{"_id", id}, {"$or", bson.A{
+		bson.D{{"gameId", gameId}},
+		bson.D{{"relatedGameId", gameId}},
+	}}}
+
+	res, err := g.mongoDbClient.Database("test").Collection(gameRelationsCollection).DeleteOne(ctx, filter)
+
+	if err != nil {
+		return UnknownError
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) saveGameMedia(ctx context.Context, media *GameMedia) error {
+	_, err := g.mongoDbClient.Database("test").Collection(gameMediaCollection).InsertOne(ctx, media)
+
+	if err != nil {
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getGameMedia(ctx context.Context, gameId primitive.ObjectID) ([]GameMedia, error) {
+	media := []GameMedia{}
+
+	filter := bson.D{{"gameId", gameId}}
+	opts := options.Find().SetSort(bson.D{{"createdAt", 1}})
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gameMediaCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &media)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return media, nil
+}
+
+func (g *GameRepositoryImpl) getGameMediaItem(ctx context.Context, gameId primitive.ObjectID, mediaId string) (*GameMedia, error) {
+	var media GameMedia
+
+	id, err := primitive.ObjectIDFromHex(mediaId)
+	if err != nil {
+		return nil, ErrMediaNotFound
+	}
+
+	filter := bson.D{{"_id", id}, {"gameId", gameId}}
+
+	err = g.mongoDbClient.Database("test").Collection(gameMediaCollection).FindOne(ctx, filter).Decode(&media)
+	if err != nil {
+		return nil, ErrMediaNotFound
+	}
+
+	return &media, nil
+}
+
+func (g *GameRepositoryImpl) deleteGameMedia(ctx context.Context, id primitive.ObjectID) error {
+	_, err := g.mongoDbClient.Database("test").Collection(gameMediaCollection).DeleteOne(ctx, bson.D{{"_id", id}})
+
+	if err != nil {
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) updateGameImage(ctx context.Context, gameId primitive.ObjectID, image string) error {
+	filter := bson.D{{"_id", gameId}}
+	update := bson.D{{"$set", bson.D{{"image", image}}}}
+
+	_, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getDeletedGames(ctx context.Context, pagination *Pagination) (*PaginatedResponse[Game], error) {
+	var games []Game
+
+	limit := int64(pagination.Limit)
+	skip := int64(pagination.Offset)
+
+	filter := bson.D{{"isDeleted", true}}
+	opts := options.Find().SetSort(bson.D{{"deletedAt", -1}}).SetLimit(limit).SetSkip(skip)
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &games)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if games == nil {
+		games = []Game{}
+	}
+
+	count, err := g.mongoDbClient.Database("test").Collection(gamesCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[Game]{
+		Data:         games,
+		TotalItems:   int(count),
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		ItemsPerPage: pagination.Limit,
+		HasMore:      int(count) > pagination.Offset+pagination.Limit,
+	}, nil
+}
+
+func (g *GameRepositoryImpl) getDeletedGameGenres(ctx context.Context, pagination *Pagination) (*PaginatedResponse[GameGenre], error) {
+	var gameGenres []GameGenre
+
+	limit := int64(pagination.Limit)
+	skip := int64(pagination.Offset)
+
+	filter := bson.D{{"isDeleted", true}}
+	opts := options.Find().SetSort(bson.D{{"deletedAt", -1}}).SetLimit(limit).SetSkip(skip)
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &gameGenres)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if gameGenres == nil {
+		gameGenres = []GameGenre{}
+	}
+
+	count, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[GameGenre]{
+		Data:         gameGenres,
+		TotalItems:   int(count),
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		ItemsPerPage: pagination.Limit,
+		HasMore:      int(count) > pagination.Offset+pagination.Limit,
+	}, nil
+}
+
+// getDeletedGameGenre returns the most recently deleted genre with the slug.
+func (g *GameRepositoryImpl) getDeletedGameGenre(ctx context.Context, slug string) (*GameGenre, error) {
+	var gameGenre GameGenre
+
+	filter := bson.D{{"slug", slug}, {"isDeleted", true}}
+	opts := options.FindOne().SetSort(bson.D{{"deletedAt", -1}})
+
+	err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).FindOne(ctx, filter, opts).Decode(&gameGenre)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return &gameGenre, nil
+}
+
+func (g *GameRepositoryImpl) restoreGame(ctx context.Context, id primitive.ObjectID) error {
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		filter := bson.D{{"_id", id}, {"isDeleted", true}}
+		update := bson.D{
+			{"$set", bson.D{{"isDeleted", false}}},
+			{"$unset", bson.D{{"deletedAt", ""}}},
+		}
+
+		res, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.MatchedCount == 0 {
+			return nil, ErrNotInTrash
+		}
+
+		reviewFilter := bson.D{{"gameId", id.Hex()}}
+		reviewUpdate := bson.D{{"$unset", bson.D{{"gameDeleted", ""}}}}
+
+		_, err = g.mongoDbClient.Database("test").Collection(reviewsCollection).UpdateMany(sessCtx, reviewFilter, reviewUpdate)
+
+		return nil, err
+	})
+
+	if err == ErrNotInTrash {
+		return ErrNotInTrash
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// restoreGameGenre restores the most recently deleted genre with the slug.
+func (g *GameRepositoryImpl) restoreGameGenre(ctx context.Context, slug string) error {
+	filter := bson.D{{"slug", slug}, {"isDeleted", true}}
+	update := bson.D{
+		{"$set", bson.D{{"isDeleted", false}}},
+		{"$unset", bson.D{{"deletedAt", ""}}},
+	}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{"deletedAt", -1}})
+
+	err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).FindOneAndUpdate(ctx, filter, update, opts).Err()
+
+	if err == mongo.ErrNoDocuments {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// purgeGame permanently removes a deleted game with its reviews, their votes,
+// comments, revisions, reports and moderation cases, its developers, relations,
+// revisions and media records. It is taken out of the collections, the
+// recommendations of users and the charts too, the jobs would only drop it on
+// their next run.
+func (g *GameRepositoryImpl) purgeGame(ctx context.Context, id primitive.ObjectID) error {
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := g.mongoDbClient.Database("test")
+
+		res, err := db.Collection(gamesCollection).DeleteOne(sessCtx, bson.D{{"_id", id}, {"isDeleted", true}})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.DeletedCount == 0 {
+			return nil, ErrNotInTrash
+		}
+
+		reviewFilter := bson.D{{"gameId", id.Hex()}}
+		opts := options.Find().SetProjection(bson.D{{"_id", 1}})
+
+		cursor, err := db.Collection(reviewsCollection).Find(sessCtx, reviewFilter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var reviews []struct {
+			Id primitive.ObjectID `bson:"_id"`
+		}
+
+		if err = cursor.All(sessCtx, &reviews); err != nil {
+			return nil, err
+		}
+
+		reviewIds := make(bson.A, 0, len(reviews))
+		for _, review := range reviews {
+			reviewIds = append(reviewIds, review.Id.Hex())
+		}
+
+		if len(reviewIds) > 0 {
+			_, err = db.Collection(votesCollection).DeleteMany(sessCtx, bson.D{{"reviewId", bson.D{{"$in", reviewIds}}}})
+			if err != nil {
+				return nil, err
+			}
+
+			if err = purgeReviewComments(sessCtx, db, reviewIds); err != nil {
+				return nil, err
+			}
+
+			_, err = db.Collection(reviewRevisionsCollection).DeleteMany(sessCtx, bson.D{{"reviewId", bson.D{{"$in", reviewIds}}}})
+			if err != nil {
+				return nil, err
+			}
+
+			// or they would still count towards the record of their reporters
+			_, err = db.Collection(reviewReportsCollection).DeleteMany(sessCtx, bson.D{{"reviewId", bson.D{{"$in", reviewIds}}}})
+			if err != nil {
+				return nil, err
+			}
+
+			// or the active ones would stay in the moderation queue
+			_, err = db.Collection(moderationCasesCollection).DeleteMany(sessCtx, bson.D{{"reviewId", bson.D{{"$in", reviewIds}}}})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err = db.Collection(gameDevelopersCollection).DeleteMany(sessCtx, bson.D{{"gameId", id.Hex()}}); err != nil {
+			return nil, err
+		}
+
+		if _, err = db.Collection(reviewsCollection).DeleteMany(sessCtx, reviewFilter); err != nil {
+			return nil, err
+		}
+
+		relationFilter := bson.D{{"$or", bson.A{
+			bson.D{{"gameId", id}},
+			bson.D{{"relatedGameId", id}},
+		}}}
+
+		if _, err = db.Collection(gameRelationsCollection).DeleteMany(sessCtx, relationFilter); err != nil {
+			return nil, err
+		}
+
+		if _, err = db.Collection(gameRevisionsCollection).DeleteMany(sessCtx, bson.D{{"gameId", id}}); err != nil {
+			return nil, err
+		}
+
+		if _, err = db.Collection(gameSlugRedirectsCollection).DeleteMany(sessCtx, bson.D{{"gameId", id}}); err != nil {
+			return nil, err
+		}
+
+		flagFilter := bson.D{{"$or", bson.A{
+			bson.D{{"gameId", id}},
+			bson.D{{"candidateId", id}},
+		}}}
+
+		if _, err = db.Collection(gameDuplicateFlagsCollection).DeleteMany(sessCtx, flagFilter); err != nil {
+			return nil, err
+		}
+
+		if _, err = db.Collection(gameMediaCollection).DeleteMany(sessCtx, bson.D{{"gameId", id}}); err != nil {
+			return nil, err
+		}
+
+		if err = purgeCollectionItems(sessCtx, db, id); err != nil {
+			return nil, err
+		}
+
+		_, err = db.Collection(userRecommendationsCollection).UpdateMany(sessCtx,
+			bson.D{{"games.gameId", id}},
+			bson.D{{"$pull", bson.D{{"games", bson.D{{"gameId", id}}}}}})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = db.Collection(gameChartsCollection).DeleteMany(sessCtx, bson.D{{"gameId", id}})
+
+		return nil, err
+	})
+
+	if err == ErrNotInTrash {
+		return ErrNotInTrash
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// purgeCollectionItems takes the game out of the collections listing it.
+func purgeCollectionItems(ctx context.Context, db *mongo.Database, gameId primitive.ObjectID) error {
+	itemFilter := bson.D{{"gameId", gameId}}
+	opts := options.Find().SetProjection(bson.D{{"collectionId", 1}})
+
+	cursor, err := db.Collection(collectionItemsCollection).Find(ctx, itemFilter, opts)
+	if err != nil {
+		return err
+	}
+
+	var items []struct {
+		CollectionId primitive.ObjectID `bson:"collectionId"`
+	}
+
+	if err = cursor.All(ctx, &items); err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	// a collection lists a game once
+	collectionIds := make(bson.A, 0, len(items))
+	for _, item := range items {
+		collectionIds = append(collectionIds, item.CollectionId)
+	}
+
+	if _, err = db.Collection(collectionItemsCollection).DeleteMany(ctx, itemFilter); err != nil {
+		return err
+	}
+
+	update := bson.D{{"$inc", bson.D{{"itemCount", -1}}}, {"$set", bson.D{{"updatedAt", time.Now()}}}}
+
+	_, err = db.Collection(collectionsCollection).UpdateMany(ctx, bson.D{{"_id", bson.D{{"$in", collectionIds}}}}, update)
+
+	return err
+}
+
+// purgeReviewComments removes the comments of the reviews and their votes.
+func purgeReviewComments(ctx context.Context, db *mongo.Database, reviewIds bson.A) error {
+	commentFilter := bson.D{{"reviewId", bson.D{{"$in", reviewIds}}}}
+	opts := options.Find().SetProjection(bson.D{{"_id", 1}})
+
+	cursor, err := db.Collection(commentsCollection).Find(ctx, commentFilter, opts)
+	if err != nil {
+		return err
+	}
+
+	var comments []struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+
+	if err = cursor.All(ctx, &comments); err != nil {
+		return err
+	}
+
+	if len(comments) == 0 {
+		return nil
+	}
+
+	commentIds := make(bson.A, 0, len(comments))
+	for _, comment := range comments {
+		commentIds = append(commentIds, comment.Id.Hex())
+	}
+
+	if _, err = db.Collection(commentVotesCollection).DeleteMany(ctx, bson.D{{"commentId", bson.D{{"$in", commentIds}}}}); err != nil {
+		return err
+	}
+
+	_, err = db.Collection(commentsCollection).DeleteMany(ctx, commentFilter)
+
+	return err
+}
+
+// purgeGameGenre permanently removes every deleted genre with the slug and
+// drops the genre from the games still carrying it.
+func (g *GameRepositoryImpl) purgeGameGenre(ctx context.Context, slug string) error {
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := g.mongoDbClient.Database("test")
+
+		res, err := db.Collection(gameGenreCollection).DeleteMany(sessCtx, bson.D{{"slug", slug}, {"isDeleted", true}})
+		if err != nil {
+			return nil, err
+		}
+
+		if res.DeletedCount == 0 {
+			return nil, ErrNotFound
+		}
+
+		// a genre with the same slug may have been added since, its games and subgenres keep it
+		if err = db.Collection(gameGenreCollection).FindOne(sessCtx, bson.D{{"slug", slug}}).Err(); err == nil {
+			return nil, nil
+		}
+
+		update := bson.D{{"$pull", bson.D{{"genres", bson.D{{"slug", slug}}}}}}
+
+		if _, err = db.Collection(gamesCollection).UpdateMany(sessCtx, bson.D{{"genres.slug", slug}}, update); err != nil {
+			return nil, err
+		}
+
+		_, err = db.Collection(gameGenreCollection).UpdateMany(sessCtx, bson.D{{"parent", slug}}, bson.D{{"$unset", bson.D{{"parent", ""}}}})
+
+		return nil, err
+	})
+
+	if err == ErrNotFound {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getExpiredTrash(ctx context.Context, deletedBefore time.Time) ([]primitive.ObjectID, []string, error) {
+	filter := bson.D{{"isDeleted", true}, {"deletedAt", bson.D{{"$lt", deletedBefore}}}}
+
+	var games []struct {
+		Id primitive.ObjectID `bson:"_id"`
+	}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, filter, options.Find().SetProjection(bson.D{{"_id", 1}}))
+	if err != nil {
+		return nil, nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &games); err != nil {
+		return nil, nil, UnknownError
+	}
+
+	slugs, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).Distinct(ctx, "slug", filter)
+	if err != nil {
+		return nil, nil, UnknownError
+	}
+
+	gameIds := make([]primitive.ObjectID, 0, len(games))
+	for _, game := range games {
+		gameIds = append(gameIds, game.Id)
+	}
+
+	genreSlugs := make([]string, 0, len(slugs))
+	for _, slug := range slugs {
+		if s, ok := slug.(string); ok {
+			genreSlugs = append(genreSlugs, s)
+		}
+	}
+
+	return gameIds, genreSlugs, nil
+}
+
+func (g *GameRepositoryImpl) getChildGenres(ctx context.Context, parents []string) ([]GameGenre, error) {
+	var genres []GameGenre
+
+	filter := bson.D{{"parent", bson.D{{"$in", parents}}}, {"isDeleted", false}}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gameGenreCollection).Find(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &genres)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return genres, nil
+}
+
+func (g *GameRepositoryImpl) getGenreRedirect(ctx context.Context, slug string) (*GenreRedirect, error) {
+	var redirect GenreRedirect
+
+	err := g.mongoDbClient.Database("test").Collection(genreRedirectsCollection).FindOne(ctx, bson.D{{"from", slug}}).Decode(&redirect)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return &redirect, nil
+}
+
+// addGenreRedirect points from at to, and repoints the redirects that led to from.
+func (g *GameRepositoryImpl) addGenreRedirect(ctx context.Context, from string, to string) error {
+	db := g.mongoDbClient.Database("test")
+
+	// the target slug is live again, it must not redirect anywhere
+	if _, err := db.Collection(genreRedirectsCollection).DeleteMany(ctx, bson.D{{"from", to}}); err != nil {
+		return err
+	}
+
+	_, err := db.Collection(genreRedirectsCollection).UpdateMany(ctx, bson.D{{"to", from}}, bson.D{{"$set", bson.D{{"to", to}}}})
+	if err != nil {
+		return err
+	}
+
+	filter := bson.D{{"from", from}}
+	update := bson.D{{"$set", GenreRedirect{From: from, To: to, CreatedAt: time.Now()}}}
+
+	_, err = db.Collection(genreRedirectsCollection).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+
+	return err
+}
+
+func (g *GameRepositoryImpl) renameGameGenre(ctx context.Context, oldSlug string, genre *GameGenre) error {
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := g.mongoDbClient.Database("test")
+
+		filter := bson.D{{"slug", oldSlug}, {"isDeleted", false}}
+		update := bson.D{{"$set", bson.D{
+			{"title", genre.Title},
+			{"slug", genre.Slug},
+			{"updatedAt", genre.UpdatedAt},
+		}}}
+
+		res, err := db.Collection(gameGenreCollection).UpdateOne(sessCtx, filter, update)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.MatchedCount == 0 {
+			return nil, ErrNotFound
+		}
+
+		if err = g.setEmbeddedGenre(sessCtx, oldSlug, genre); err != nil {
+			return nil, err
+		}
+
+		if oldSlug == genre.Slug {
+			return nil, nil
+		}
+
+		_, err = db.Collection(gameGenreCollection).UpdateMany(sessCtx, bson.D{{"parent", oldSlug}}, bson.D{{"$set", bson.D{{"parent", genre.Slug}}}})
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, g.addGenreRedirect(sessCtx, oldSlug, genre.Slug)
+	})
+
+	if err == ErrNotFound {
+		return ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) mergeGameGenres(ctx context.Context, source *GameGenre, target *GameGenre) error {
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := g.mongoDbClient.Database("test")
+
+		// games already carrying the target only lose the source
+		bothFilter := bson.D{{"$and", bson.A{
+			bson.D{{"genres.slug", source.Slug}},
+			bson.D{{"genres.slug", target.Slug}},
+		}}}
+		pull := bson.D{{"$pull", bson.D{{"genres", bson.D{{"slug", source.Slug}}}}}}
+
+		if _, err := db.Collection(gamesCollection).UpdateMany(sessCtx, bothFilter, pull); err != nil {
+			return nil, err
+		}
+
+		if err := g.setEmbeddedGenre(sessCtx, source.Slug, target); err != nil {
+			return nil, err
+		}
+
+		_, err := db.Collection(gameGenreCollection).UpdateMany(sessCtx, bson.D{{"parent", source.Slug}}, bson.D{{"$set", bson.D{{"parent", target.Slug}}}})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = db.Collection(gameGenreCollection).DeleteOne(sessCtx, bson.D{{"slug", source.Slug}, {"isDeleted", false}}); err != nil {
+			return nil, err
+		}
+
+		return nil, g.addGenreRedirect(sessCtx, source.Slug, target.Slug)
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// getGameRatings returns the stored rating stats of every game, including the ones in the trash.
+func (g *GameRepositoryImpl) getGameRatings(ctx context.Context) (map[primitive.ObjectID]RatingStats, error) {
+	var games []struct {
+		Id     primitive.ObjectID `bson:"_id"`
+		Rating RatingStats        `bson:"rating"`
+	}
+
+	opts := options.Find().SetProjection(bson.D{{"_id", 1}, {"rating", 1}})
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &games); err != nil {
+		return nil, UnknownError
+	}
+
+	ratings := make(map[primitive.ObjectID]RatingStats, len(games))
+	for _, game := range games {
+		ratings[game.Id] = game.Rating
+	}
+
+	return ratings, nil
+}
+
+// getReviewRatingTotals sums the ratings of the reviews that are not deleted,
+// per game. Only Sum, Count and Histogram are set.
+func (g *GameRepositoryImpl) getReviewRatingTotals(ctx context.Context) (map[primitive.ObjectID]RatingStats, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"isDeleted", false}}}},
+		{{"$group", bson.D{
+			{"_id", bson.D{{"gameId", "$gameId"}, {"rating", "$rating"}}},
+			{"count", bson.D{{"$sum", 1}}},
+		}}},
+	}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(reviewsCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var groups []struct {
+		Id struct {
+			GameId string `bson:"gameId"`
+			Rating int    `bson:"rating"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+
+	if err = cursor.All(ctx, &groups); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	totals := make(map[primitive.ObjectID]RatingStats)
+
+	for _, group := range groups {
+		gameId, err := primitive.ObjectIDFromHex(group.Id.GameId)
+		if err != nil {
+			continue
+		}
+
+		total, ok := totals[gameId]
+		if !ok {
+			total.Histogram = map[string]int{}
+		}
+
+		bucket, ok := RatingBucket(group.Id.Rating)
+		if !ok {
+			continue
+		}
+
+		total.Sum += group.Id.Rating * group.Count
+		total.Count += group.Count
+		total.Histogram[bucket] += group.Count
+
+		totals[gameId] = total
+	}
+
+	subScores, err := g.getReviewSubScoreTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for gameId, gameSubScores := range subScores {
+		total := totals[gameId]
+		total.SubScores = gameSubScores
+		totals[gameId] = total
+	}
+
+	return totals, nil
+}
+
+// getReviewSubScoreTotals sums the sub-scores of the reviews that are not
+// deleted, per game and aspect. Only Sum, Count and Histogram are set.
+func (g *GameRepositoryImpl) getReviewSubScoreTotals(ctx context.Context) (map[primitive.ObjectID]map[string]SubScoreStats, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"isDeleted", false}, {"subScores", bson.D{{"$type", "object"}}}}}},
+		{{"$project", bson.D{{"gameId", 1}, {"subScores", bson.D{{"$objectToArray", "$subScores"}}}}}},
+		{{"$unwind", "$subScores"}},
+		{{"$group", bson.D{
+			{"_id", bson.D{{"gameId", "$gameId"}, {"dimension", "$subScores.k"}, {"score", "$subScores.v"}}},
+			{"count", bson.D{{"$sum", 1}}},
+		}}},
+	}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(reviewsCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var groups []struct {
+		Id struct {
+			GameId    string `bson:"gameId"`
+			Dimension string `bson:"dimension"`
+			Score     int    `bson:"score"`
+		} `bson:"_id"`
+		Count int `bson:"count"`
+	}
+
+	if err = cursor.All(ctx, &groups); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	totals := make(map[primitive.ObjectID]map[string]SubScoreStats)
+
+	for _, group := range groups {
+		gameId, err := primitive.ObjectIDFromHex(group.Id.GameId)
+		if err != nil || !IsSubScoreDimension(group.Id.Dimension) {
+			continue
+		}
+
+		if totals[gameId] == nil {
+			totals[gameId] = map[string]SubScoreStats{}
+		}
+
+		total, ok := totals[gameId][group.Id.Dimension]
+		if !ok {
+			total.Histogram = map[string]int{}
+		}
+
+		bucket, ok := RatingBucket(group.Id.Score)
+		if !ok {
+			continue
+		}
+
+		total.Sum += group.Id.Score * group.Count
+		total.Count += group.Count
+		total.Histogram[bucket] += group.Count
+
+		totals[gameId][group.Id.Dimension] = total
+	}
+
+	return totals, nil
+}
+
+// setGameRating replaces the rating stats of the game, provided its totals are
+// still the ones they were read as.
+func (g *GameRepositoryImpl) setGameRating(ctx context.Context, id primitive.ObjectID, before RatingStats, after RatingStats) (bool, error) {
+	filter := bson.D{{"_id", id}, {"rating.sum", before.Sum}, {"rating.count", before.Count}}
+
+	// games that never had a review may have no totals stored at all
+	if before.Sum == 0 && before.Count == 0 {
+		filter = bson.D{{"_id", id}, {"rating.sum", bson.D{{"$in", bson.A{0, nil}}}}, {"rating.count", bson.D{{"$in", bson.A{0, nil}}}}}
+	}
+
+	res, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(ctx, filter, bson.D{{"$set", bson.D{{"rating", after}}}})
+
+	if err != nil {
+		log.Println(err)
+		return false, UnknownError
+	}
+
+	return res.MatchedCount > 0, nil
+}
+
+// getGameFeatures returns the games that are not deleted, with only the fields
+// similar games and charts are computed from.
+func (g *GameRepositoryImpl) getGameFeatures(ctx context.Context) ([]Game, error) {
+	var games []Game
+
+	opts := options.Find().SetProjection(bson.D{{"_id", 1}, {"genres", 1}, {"platforms", 1}, {"developer", 1}, {"publisher", 1}, {"rating", 1}})
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, bson.D{{"isDeleted", false}}, opts)
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &games); err != nil {
+		return nil, UnknownError
+	}
+
+	return games, nil
+}
+
+// getUserLikes returns, per user, the games they left a review of at least
+// minRating on.
+func (g *GameRepositoryImpl) getUserLikes(ctx context.Context, minRating int) (map[string][]primitive.ObjectID, error) {
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"isDeleted", false}, {"rating", bson.D{{"$gte", minRating}}}}}},
+		{{"$group", bson.D{
+			{"_id", "$userId"},
+			{"gameIds", bson.D{{"$addToSet", "$gameId"}}},
+		}}},
+	}
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(reviewsCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var groups []struct {
+		UserId  string   `bson:"_id"`
+		GameIds []string `bson:"gameIds"`
+	}
+
+	if err = cursor.All(ctx, &groups); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	likes := make(map[string][]primitive.ObjectID, len(groups))
+
+	for _, group := range groups {
+		for _, hex := range group.GameIds {
+			gameId, err := primitive.ObjectIDFromHex(hex)
+			if err != nil {
+				continue
+			}
+
+			likes[group.UserId] = append(likes[group.UserId], gameId)
+		}
+	}
+
+	return likes, nil
+}
+
+// saveGameSimilarities replaces the similar games of every game, games left out
+// of the run have their stale entry removed.
+func (g *GameRepositoryImpl) saveGameSimilarities(ctx context.Context, similarities []GameSimilarity, computedAt time.Time) error {
+	collection := g.mongoDbClient.Database("test").Collection(gameSimilarityCollection)
+
+	if len(similarities) > 0 {
+		models := make([]mongo.WriteModel, 0, len(similarities))
+
+		for _, similarity := range similarities {
+			models = append(models, mongo.NewReplaceOneModel().
+				SetFilter(bson.D{{"_id", similarity.GameId}}).
+				SetReplacement(similarity).
+				SetUpsert(true))
+		}
+
+		if _, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			log.Println(err)
+			return UnknownError
+		}
+	}
+
+	if _, err := collection.DeleteMany(ctx, bson.D{{"computedAt", bson.D{{"$lt", computedAt}}}}); err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getGameSimilarity(ctx context.Context, gameId primitive.ObjectID) (*GameSimilarity, error) {
+	var similarity GameSimilarity
+
+	err := g.mongoDbClient.Database("test").Collection(gameSimilarityCollection).FindOne(ctx, bson.D{{"_id", gameId}}).Decode(&similarity)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &similarity, nil
+}
+
+// getGameInteractions returns the reviews and votes of the user, or of every
+// user when userId is empty, as interactions with games that are not deleted.
+// Votes count for the game of the review voted on.
+func (g *GameRepositoryImpl) getGameInteractions(ctx context.Context, userId string) ([]GameInteraction, error) {
+	db := g.mongoDbClient.Database("test")
+
+	reviewFilter := bson.D{{"isDeleted", false}}
+	voteFilter := bson.D{}
+
+	if userId != "" {
+		reviewFilter = append(reviewFilter, bson.E{"userId", userId})
+		voteFilter = append(voteFilter, bson.E{"userId", userId})
+	}
+
+	opts := options.Find().SetProjection(bson.D{{"userId", 1}, {"gameId", 1}, {"rating", 1}, {"createdAt", 1}})
+
+	cursor, err := db.Collection(reviewsCollection).Find(ctx, reviewFilter, opts)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var reviews []struct {
+		UserId    string    `bson:"userId"`
+		GameId    string    `bson:"gameId"`
+		Rating    int       `bson:"rating"`
+		CreatedAt time.Time `bson:"createdAt"`
+	}
+
+	if err = cursor.All(ctx, &reviews); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", voteFilter}},
+		{{"$lookup", bson.D{
+			{"from", reviewsCollection},
+			{"let", bson.D{{"reviewId", bson.D{{"$convert", bson.D{{"input", "$reviewId"}, {"to", "objectId"}, {"onError", nil}}}}}}},
+			{"pipeline", bson.A{
+				bson.D{{"$match", bson.D{{"$expr", bson.D{{"$eq", bson.A{"$_id", "$$reviewId"}}}}}}},
+				bson.D{{"$project", bson.D{{"gameId", 1}, {"isDeleted", 1}}}},
+			}},
+			{"as", "review"},
+		}}},
+		{{"$unwind", "$review"}},
+		{{"$match", bson.D{{"review.isDeleted", false}}}},
+		{{"$project", bson.D{{"userId", 1}, {"isUpVote", 1}, {"gameId", "$review.gameId"}}}},
+	}
+
+	cursor, err = db.Collection(votesCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var votes []struct {
+		UserId   string `bson:"userId"`
+		GameId   string `bson:"gameId"`
+		IsUpVote bool   `bson:"isUpVote"`
+	}
+
+	if err = cursor.All(ctx, &votes); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	interactions := make([]GameInteraction, 0, len(reviews)+len(votes))
+
+	for _, review := range reviews {
+		gameId, err := primitive.ObjectIDFromHex(review.GameId)
+		if err != nil {
+			continue
+		}
+
+		interactions = append(interactions, GameInteraction{
+			UserId:     review.UserId,
+			Source:     "review",
+			GameId:     gameId,
+			Preference: reviewPreference(review.Rating),
+			At:         review.CreatedAt,
+		})
+	}
+
+	for _, vote := range votes {
+		gameId, err := primitive.ObjectIDFromHex(vote.GameId)
+		if err != nil {
+			continue
+		}
+
+		// a downvote only tells the user has seen the game
+		preference := 0.0
+		if vote.IsUpVote {
+			preference = upVotePreference
+		}
+
+		interactions = append(interactions, GameInteraction{
+			UserId:     vote.UserId,
+			Source:     "vote",
+			GameId:     gameId,
+			Preference: preference,
+		})
+	}
+
+	return interactions, nil
+}
+
+// saveUserRecommendations replaces the recommendations of every user, users left
+// out of the run have their stale entry removed.
+func (g *GameRepositoryImpl) saveUserRecommendations(ctx context.Context, recommendations []UserRecommendations, computedAt time.Time) error {
+	collection := g.mongoDbClient.Database("test").Collection(userRecommendationsCollection)
+
+	if len(recommendations) > 0 {
+		models := make([]mongo.WriteModel, 0, len(recommendations))
+
+		for _, userRecommendations := range recommendations {
+			models = append(models, mongo.NewReplaceOneModel().
+				SetFilter(bson.D{{"_id", userRecommendations.UserId}}).
+				SetReplacement(userRecommendations).
+				SetUpsert(true))
+		}
+
+		if _, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+			log.Println(err)
+			return UnknownError
+		}
+	}
+
+	if _, err := collection.DeleteMany(ctx, bson.D{{"computedAt", bson.D{{"$lt", computedAt}}}}); err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (g *GameRepositoryImpl) getUserRecommendations(ctx context.Context, userId string) (*UserRecommendations, error) {
+	var recommendations UserRecommendations
+
+	err := g.mongoDbClient.Database("test").Collection(userRecommendationsCollection).FindOne(ctx, bson.D{{"_id", userId}}).Decode(&recommendations)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &recommendations, nil
+}
+
+// getTopRatedGames returns the games with the best weighted rating, in any of
+// the genres when some are given, leaving out the excluded ones.
+func (g *GameRepositoryImpl) getTopRatedGames(ctx context.Context, genres []string, exclude []primitive.ObjectID, content *ContentFilter, limit int) ([]Game, error) {
+	var games []Game
+
+	filter := bson.D{{"isDeleted", false}}
+
+	filter = append(filter, contentFilterQuery(content)...)
+
+	if len(genres) > 0 {
+		filter = append(filter, bson.E{"genres.slug", bson.D{{"$in", genres}}})
+	}
+
+	if len(exclude) > 0 {
+		filter = append(filter, bson.E{"_id", bson.D{{"$nin", exclude}}})
+	}
+
+	opts := options.Find().SetSort(gameSortKeys["rating"]).SetLimit(int64(limit))
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gamesCollection).Find(ctx, filter, opts)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &games); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return games, nil
+}
+
+// getGameActivity sums, per game, the reviews written and the votes cast on
+// reviews between since and until. Reviews and votes of deleted reviews are left out.
+func (g *GameRepositoryImpl) getGameActivity(ctx context.Context, since time.Time, until time.Time) (map[primitive.ObjectID]GameActivity, error) {
+	db := g.mongoDbClient.Database("test")
+	window := bson.D{{"$gte", since}, {"$lt", until}}
+
+	reviewsPipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"isDeleted", false}, {"createdAt", window}}}},
+		{{"$group", bson.D{
+			{"_id", "$gameId"},
+			{"reviews", bson.D{{"$sum", 1}}},
+			{"ratingSum", bson.D{{"$sum", "$rating"}}},
+		}}},
+	}
+
+	cursor, err := db.Collection(reviewsCollection).Aggregate(ctx, reviewsPipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var reviews []struct {
+		GameId    string `bson:"_id"`
+		Reviews   int    `bson:"reviews"`
+		RatingSum int    `bson:"ratingSum"`
+	}
+
+	if err = cursor.All(ctx, &reviews); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	votesPipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"votedAt", window}}}},
+		{{"$lookup", bson.D{
+			{"from", reviewsCollection},
+			{"let", bson.D{{"reviewId", bson.D{{"$convert", bson.D{{"input", "$reviewId"}, {"to", "objectId"}, {"onError", nil}}}}}}},
+			{"pipeline", bson.A{
+				bson.D{{"$match", bson.D{{"$expr", bson.D{{"$eq", bson.A{"$_id", "$$reviewId"}}}}}}},
+				bson.D{{"$project", bson.D{{"gameId", 1}, {"isDeleted", 1}}}},
+			}},
+			{"as", "review"},
+		}}},
+		{{"$unwind", "$review"}},
+		{{"$match", bson.D{{"review.isDeleted", false}}}},
+		{{"$group", bson.D{
+			{"_id", "$review.gameId"},
+			{"upVotes", bson.D{{"$sum", bson.D{{"$cond", bson.A{"$isUpVote", 1, 0}}}}}},
+			{"downVotes", bson.D{{"$sum", bson.D{{"$cond", bson.A{"$isDownVote", 1, 0}}}}}},
+		}}},
+	}
+
+	cursor, err = db.Collection(votesCollection).Aggregate(ctx, votesPipeline)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var votes []struct {
+		GameId    string `bson:"_id"`
+		UpVotes   int    `bson:"upVotes"`
+		DownVotes int    `bson:"downVotes"`
+	}
+
+	if err = cursor.All(ctx, &votes); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	activity := make(map[primitive.ObjectID]GameActivity)
+
+	for _, review := range reviews {
+		gameId, err := primitive.ObjectIDFromHex(review.GameId)
+		if err != nil {
+			continue
+		}
+
+		total := activity[gameId]
+		total.Reviews += review.Reviews
+		total.RatingSum += review.RatingSum
+		activity[gameId] = total
+	}
+
+	for _, vote := range votes {
+		gameId, err := primitive.ObjectIDFromHex(vote.GameId)
+		if err != nil {
+			continue
+		}
+
+		total := activity[gameId]
+		total.UpVotes += vote.UpVotes
+		total.DownVotes += vote.DownVotes
+		activity[gameId] = total
+	}
+
+	return activity, nil
+}
+
+// saveChart replaces the entries of the chart for the window.
+func (g *GameRepositoryImpl) saveChart(ctx context.Context, chart ChartKind, window string, entries []ChartEntry) error {
+	session, err := g.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		collection := g.mongoDbClient.Database("test").Collection(gameChartsCollection)
+
+		if _, err := collection.DeleteMany(sessCtx, bson.D{{"chart", chart}, {"window", window}}); err != nil {
+			return nil, err
+		}
+
+		if len(entries) == 0 {
+			return nil, nil
+		}
+
+		documents := make([]interface{}, 0, len(entries))
+		for _, entry := range entries {
+			documents = append(documents, entry)
+		}
+
+		_, err := collection.InsertMany(sessCtx, documents)
+
+		return nil, err
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// getChart lists the entries of the chart for the window by rank. When given,
+// only the games in one of the genres and on the platform are listed.
+func (g *GameRepositoryImpl) getChart(ctx context.Context, chart ChartKind, window string, genres []string, platform string, pagination *Pagination) (*PaginatedResponse[ChartEntry], error) {
+	var entries []ChartEntry
+
+	filter := bson.D{{"chart", chart}, {"window", window}}
+
+	if len(genres) > 0 {
+		filter = append(filter, bson.E{"genres", bson.D{{"$in", genres}}})
+	}
+
+	if platform != "" {
+		filter = append(filter, bson.E{"platforms", platform})
+	}
+
+	opts := options.Find().SetSort(bson.D{{"rank", 1}}).SetLimit(int64(pagination.Limit)).SetSkip(int64(pagination.Offset))
+
+	cursor, err := g.mongoDbClient.Database("test").Collection(gameChartsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &entries)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	if len(entries) == 0 {
+		return &PaginatedResponse[ChartEntry]{
+			TotalItems:   0,
+			TotalPages:   0,
+			CurrentPage:  0,
+			ItemsPerPage: 0,
+			HasMore:      false,
+			Data:         []ChartEntry{},
+		}, nil
+	}
+
+	count, err := g.mongoDbClient.Database("test").Collection(gameChartsCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[ChartEntry]{
+		Data:         entries,
+		TotalPages:   int(math.Ceil(float64(count) / float64(pagination.Limit))),
+		CurrentPage:  pagination.Offset / pagination.Limit,
+		TotalItems:   int(count),
+		HasMore:      int(count) > (pagination.Offset + pagination.Limit),
+		ItemsPerPage: pagination.Limit,
+	}, nil
+}
+
+// contentFilterQuery matches the games within the content filter. Games rated
+// before games had an age rating have no minimumAge and are not restricted. A
+// filter that blurs lets every game through.
+func contentFilterQuery(content *ContentFilter) bson.D {
+	query := bson.D{}
+
+	if content == nil || content.Blur {
+		return query
+	}
+
+	if content.MaxAge < adultAge {
+		query = append(query, bson.E{"minimumAge", bson.D{{"$not", bson.D{{"$gt", content.MaxAge}}}}})
+	}
+
+	if len(content.Descriptors) > 0 {
+		query = append(query, bson.E{"contentDescriptors", bson.D{{"$nin", content.Descriptors}}})
+	}
+
+	return query
+}
+
+func (g *GameRepositoryImpl) getContentPreferences(ctx context.Context, userId string) (*ContentPreferences, error) {
+	var preferences ContentPreferences
+
+	err := g.mongoDbClient.Database("test").Collection(contentPreferencesCollection).FindOne(ctx, bson.D{{"_id", userId}}).Decode(&preferences)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if preferences.HiddenDescriptors == nil {
+		preferences.HiddenDescriptors = []ContentDescriptor{}
+	}
+
+	return &preferences, nil
+}
+
+func (g *GameRepositoryImpl) saveContentPreferences(ctx context.Context, preferences *ContentPreferences) error {
+	opts := options.Replace().SetUpsert(true)
 
-	_, err := g.mongoDbClient.Database("test").Collection(gamesCollection).UpdateOne(ctx, filter, update, opts)
+	_, err := g.mongoDbClient.Database("test").Collection(contentPreferencesCollection).ReplaceOne(ctx, bson.D{{"_id", preferences.UserId}}, preferences, opts)
 
 	if err != nil {
+		log.Println(err)
 		return UnknownError
 	}
 
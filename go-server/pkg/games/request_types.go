@@ -1,8 +1,17 @@
 package games
 
 type AddGenreRequest struct {
+	Title  string `json:"title" validate:"required"`
+	Desc   string `json:"desc" validate:"required"`
+	Parent string `json:"parent,omitempty"`
+}
+
+type RenameGenreRequest struct {
 	Title string `json:"title" validate:"required"`
-	Desc  string `json:"desc" validate:"required"`
+}
+
+type MergeGenreRequest struct {
+	Into string `json:"into" validate:"required"`
 }
 
 type AddGameRequest struct {
@@ -12,7 +21,12 @@ type AddGameRequest struct {
 	Developer   string               `json:"developer" validate:"required"`
 	Publisher   string               `json:"publisher" validate:"required"`
 	Genres      []*EmbeddedGameGenre `json:"genres" validate:"required"`
+	Platforms   []string             `json:"platforms" validate:"omitempty"`
 	Image       string               `json:"image" validate:"required"`
+	// ids of the game at catalog providers, e.g. {"igdb": "1942"}
+	ExternalIds        map[string]string   `json:"externalIds,omitempty" validate:"omitempty"`
+	AgeRating          *AgeRating          `json:"ageRating,omitempty" validate:"omitempty"`
+	ContentDescriptors []ContentDescriptor `json:"contentDescriptors,omitempty" validate:"omitempty"`
 }
 
 type UpdateGameRequest struct {
@@ -22,5 +36,32 @@ type UpdateGameRequest struct {
 	Developer   string               `json:"developer" validate:"omitempty"`
 	Publisher   string               `json:"publisher" validate:"omitempty"`
 	Genres      []*EmbeddedGameGenre `json:"genres" validate:"omitempty"`
-	Image       string               `json:"image" validate:"required"`
+	Platforms   []string             `json:"platforms" validate:"omitempty"`
+	Image       string               `json:"image" validate:"omitempty"`
+	// left out keeps the current rating, an empty one clears it
+	AgeRating          *AgeRating          `json:"ageRating,omitempty" validate:"omitempty"`
+	ContentDescriptors []ContentDescriptor `json:"contentDescriptors,omitempty" validate:"omitempty"`
+}
+
+type AddFranchiseRequest struct {
+	Title string `json:"title" validate:"required"`
+	Desc  string `json:"desc"`
+}
+
+type AddGameRelationRequest struct {
+	Type          RelationType `json:"type" validate:"required"`
+	RelatedGameId string       `json:"relatedGameId,omitempty"`
+	FranchiseSlug string       `json:"franchiseSlug,omitempty"`
+}
+
+type ReviewDuplicateFlagRequest struct {
+	Status DuplicateStatus `json:"status" validate:"required,oneof=open confirmed dismissed"`
+}
+
+type SetContentPreferencesRequest struct {
+	// yyyy-mm-dd, can't be changed once set
+	Birthdate         string              `json:"birthdate,omitempty" validate:"omitempty"`
+	MaxAge            *int                `json:"maxAge,omitempty" validate:"omitempty,gte=0,lte=18"`
+	HiddenDescriptors []ContentDescriptor `json:"hiddenDescriptors" validate:"omitempty"`
+	Mode              ContentFilterMode   `json:"mode,omitempty" validate:"omitempty,oneof=hide blur"`
 }
@@ -11,12 +11,33 @@ func router(ctx context.Context, app fiber.Router, handler *GameHandler, middlew
 	apiVersion := ctx.Value("apiVersion").(string)
 	app = app.Group(apiVersion + "/games")
 
+	// images are loaded by browsers without the bearer token, so they are served before the auth middleware
+	app.Get("/media/*", HandleServeMedia(handler, ctx))
+
 	app.Use(middleware.AuthMiddleware(allowAllAuthenticated))
 
+	app.Get("/content/preferences", HandleGetContentPreferences(handler, ctx))
+
+	app.Put("/content/preferences", HandleSetContentPreferences(handler, ctx))
+
 	app.Get("/genres", HandleGetGenres(handler, ctx))
 
 	app.Get("/genres/:slug", HandleGetGenre(handler, ctx))
 
+	app.Get("/franchises/:slug/timeline", HandleGetFranchiseTimeline(handler, ctx))
+
+	app.Get("/recommended", HandleGetRecommendedGames(handler, ctx))
+
+	app.Get("/charts/:chart", HandleGetChart(handler, ctx))
+
+	app.Get("/external/:provider/:externalId", HandleGetGameByExternalId(handler, ctx))
+
+	app.Get("/:id/related", HandleGetRelatedGames(handler, ctx))
+
+	app.Get("/:id/similar", HandleGetSimilarGames(handler, ctx))
+
+	app.Get("/:id/media", HandleGetGameMedia(handler, ctx))
+
 	app.Get("/:id", HandleGetGame(handler, ctx))
 
 	app.Get("/", HandleGetGames(handler, ctx))
@@ -27,16 +48,74 @@ func router(ctx context.Context, app fiber.Router, handler *GameHandler, middlew
 
 	app.Put("/genres/update", HandleUpdateGenre(handler, ctx))
 
+	app.Post("/franchises/add", HandleAddFranchise(handler, ctx))
+
+	app.Post("/:id/relations", HandleAddGameRelation(handler, ctx))
+
+	app.Delete("/:id/relations/:relationId", HandleDeleteGameRelation(handler, ctx))
+
+	app.Post("/:id/media", HandleAddGameMedia(handler, ctx))
+
+	app.Get("/:id/revisions", HandleGetGameRevisions(handler, ctx))
+
 	app.Use(middleware.AuthMiddleware(adminOnlyPermission))
 
 	app.Delete("/genres/:slug", HandleDeleteGenre(handler, ctx))
 
+	app.Post("/genres/:slug/rename", HandleRenameGenre(handler, ctx))
+
+	app.Post("/genres/:slug/merge", HandleMergeGenre(handler, ctx))
+
+	app.Delete("/franchises/:slug", HandleDeleteFranchise(handler, ctx))
+
 	app.Post("/add", HandleAddGame(handler, ctx))
 
+	app.Post("/ratings/recalculate", HandleRecalculateRatings(handler, ctx))
+
+	app.Get("/recommendations/evaluate", HandleEvaluateRecommendations(handler, ctx))
+
+	app.Put("/external/:provider/:externalId", HandleUpsertGameByExternalId(handler, ctx))
+
+	app.Get("/duplicates/flags", HandleGetDuplicateFlags(handler, ctx))
+
+	app.Put("/duplicates/flags/:flagId", HandleReviewDuplicateFlag(handler, ctx))
+
+	app.Get("/translations/report", HandleGetTranslationReport(handler, ctx))
+
+	app.Get("/translations/missing", HandleGetMissingTranslations(handler, ctx))
+
+	app.Get("/genres/:slug/translations", HandleGetGenreTranslations(handler, ctx))
+
+	app.Put("/genres/:slug/translations/:locale", HandleSetGenreTranslation(handler, ctx))
+
+	app.Delete("/genres/:slug/translations/:locale", HandleDeleteGenreTranslation(handler, ctx))
+
+	app.Get("/:id/translations", HandleGetGameTranslations(handler, ctx))
+
+	app.Put("/:id/translations/:locale", HandleSetGameTranslation(handler, ctx))
+
+	app.Delete("/:id/translations/:locale", HandleDeleteGameTranslation(handler, ctx))
+
 	app.Put("/:id", HandleUpdateGame(handler, ctx))
 
+	app.Post("/:id/revisions/:revision/revert", HandleRevertGameRevision(handler, ctx))
+
 	app.Delete("/:id", HandleDeleteGame(handler, ctx))
 
+	app.Delete("/:id/media/:mediaId", HandleDeleteGameMedia(handler, ctx))
+
+	app.Get("/trash/games", HandleGetTrashedGames(handler, ctx))
+
+	app.Get("/trash/genres", HandleGetTrashedGenres(handler, ctx))
+
+	app.Post("/:id/restore", HandleRestoreGame(handler, ctx))
+
+	app.Delete("/:id/purge", HandlePurgeGame(handler, ctx))
+
+	app.Post("/genres/:slug/restore", HandleRestoreGenre(handler, ctx))
+
+	app.Delete("/genres/:slug/purge", HandlePurgeGenre(handler, ctx))
+
 	return nil
 }
 
@@ -0,0 +1,147 @@
+package games
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// how many numbered slugs are tried before falling back to the game id
+const maxGameSlugAttempts = 10
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// reservedGameSlugs are the first path segments of the other game routes, a game
+// with one of them as slug could not be reached, or only from some routes.
+var reservedGameSlugs = map[string]bool{
+	"add":             true,
+	"charts":          true,
+	"content":         true,
+	"duplicates":      true,
+	"external":        true,
+	"franchises":      true,
+	"genres":          true,
+	"media":           true,
+	"ratings":         true,
+	"recommendations": true,
+	"recommended":     true,
+	"translations":    true,
+	"trash":           true,
+}
+
+// getGameSlug turns a game title into a url friendly slug, "Half-Life: Alyx"
+// gives "half-life-alyx".
+func getGameSlug(title string) string {
+	slug := nonSlugCharacters.ReplaceAllString(strings.ToLower(title), "-")
+	slug = strings.Trim(slug, "-")
+
+	if slug == "" {
+		slug = "game"
+	}
+
+	return slug
+}
+
+// gameSlugCandidates lists the slugs tried for a game, best first. A remake
+// sharing the title of another game gets its release year appended before
+// falling back to a number, e.g. "resident-evil-2-2019" then "resident-evil-2-2".
+func gameSlugCandidates(game *Game) []string {
+	base := getGameSlug(game.Title)
+	candidates := []string{base}
+
+	if game.ReleaseDate > 0 {
+		candidates = append(candidates, base+"-"+strconv.Itoa(game.ReleaseDate))
+	}
+
+	for attempt := 2; attempt <= maxGameSlugAttempts; attempt++ {
+		candidates = append(candidates, base+"-"+strconv.Itoa(attempt))
+	}
+
+	return candidates
+}
+
+// uniqueGameSlug returns the first slug for the game that no other game uses,
+// either as its current slug or as a redirect from an old one. Slugs the game
+// itself used before can be taken back, e.g. when a title change is reverted.
+func (g *Service) uniqueGameSlug(ctx context.Context, game *Game) (string, error) {
+	candidates := gameSlugCandidates(game)
+
+	for _, slug := range candidates {
+		if reservedGameSlugs[slug] {
+			continue
+		}
+
+		owner, err := g.repository.getGameSlugOwner(ctx, slug)
+
+		if err == ErrNotFound || (err == nil && owner == game.Id) {
+			return slug, nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// a popular title, fall back to a slug nobody else can have
+	return candidates[0] + "-" + game.Id.Hex(), nil
+}
+
+// findGame looks a game up by id or by slug. Old slugs of renamed games resolve
+// through their redirect.
+func (g *Service) findGame(ctx context.Context, key string) (*Game, error) {
+	if primitive.IsValidObjectID(key) {
+		if game, err := g.repository.getGame(ctx, key); err == nil {
+			return game, nil
+		}
+	}
+
+	game, err := g.repository.getGameBySlug(ctx, key)
+
+	if err == nil {
+		return game, nil
+	}
+
+	redirect, err := g.repository.getGameSlugRedirect(ctx, key)
+
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	return g.repository.getGame(ctx, redirect.GameId.Hex())
+}
+
+// BackfillGameSlugs gives a slug to the games added before games had one.
+func (g *Service) BackfillGameSlugs(ctx context.Context) (int, error) {
+
+	games, err := g.repository.getGamesWithoutSlug(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	filled := 0
+
+	for i := range games {
+		slug, err := g.uniqueGameSlug(ctx, &games[i])
+
+		if err != nil {
+			return filled, err
+		}
+
+		if err = g.repository.setGameSlug(ctx, games[i].Id, slug); err != nil {
+			log.Println("could not set the slug of game", games[i].Id.Hex(), err)
+			continue
+		}
+
+		filled++
+	}
+
+	if filled > 0 {
+		g.invalidateGames(ctx)
+	}
+
+	return filled, nil
+}
@@ -11,23 +11,25 @@ import (
 // @Security BearerAuth
 //
 // @Summary Add a review
-// @Description Add a review
+// @Description Add a review, a user reviews a game once. With upsert their existing review of the game is updated instead.
 // @Tags Reviews
 // @ID addReview
 // @Accept json
 // @Produce json
 //
 // @Param addReview body reviews.AddReviewRequest true "addReview request"
+// @Param upsert query bool false "update the existing review of the user"
 //
 // @Success 201 {object} main.JSONResult{data=reviews.AddReviewRes} "Success"
+// @Success 200 {object} main.JSONResult{data=reviews.AddReviewRes} "Existing review updated"
 // @Failure 400 {object} main.JSONErrorRes "Bad request"
 // @Failure 404 {object} main.JSONErrorRes "Game not found"
-// @Failure 409 {object} main.JSONErrorRes "Review already exists"
+// @Failure 409 {object} main.JSONResult{data=reviews.AddReviewRes} "Review already exists, with its id"
 // @Router /api/v1/reviews/add [post]
 func HandleAddReview(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("add review")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.AddReview(ctx, c)
 	}
 }
@@ -53,7 +55,7 @@ func HandleAddReview(handler *Handler, ctx context.Context) fiber.Handler {
 func HandleUpdateReview(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("update review")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.UpdateReview(ctx, c)
 	}
 }
@@ -78,7 +80,7 @@ func HandleUpdateReview(handler *Handler, ctx context.Context) fiber.Handler {
 func HandleGetReview(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("get review")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.GetReview(ctx, c)
 	}
 }
@@ -88,7 +90,7 @@ func HandleGetReview(handler *Handler, ctx context.Context) fiber.Handler {
 // @Security BearerAuth
 //
 // @Summary Get reviews for a game
-// @Description Get reviews for a game
+// @Description Get reviews for a game, sorted by sortKey or most helpful first with sortKey "helpful", the Wilson lower bound of the share of upvotes
 // @Tags Reviews
 // @ID getReviewsForGame
 // @Accept json
@@ -104,7 +106,7 @@ func HandleGetReview(handler *Handler, ctx context.Context) fiber.Handler {
 func HandleGetReviewsForGame(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("HandleGetReviewsForGame")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.GetReviewsForGame(ctx, c)
 	}
 }
@@ -130,7 +132,7 @@ func HandleGetReviewsForGame(handler *Handler, ctx context.Context) fiber.Handle
 func HandleGetReviewsForUser(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("HandleGetReviewsForUser")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.GetReviewsForUser(ctx, c)
 	}
 }
@@ -155,7 +157,7 @@ func HandleGetReviewsForUser(handler *Handler, ctx context.Context) fiber.Handle
 func HandleDeleteReview(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("delete review")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.DeleteReview(ctx, c)
 	}
 }
@@ -163,7 +165,7 @@ func HandleDeleteReview(handler *Handler, ctx context.Context) fiber.Handler {
 func HandleVoteReview(handler *Handler, ctx context.Context, shouldUpvote bool) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("vote review")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		if shouldUpvote {
 			return upvote(ctx, c, handler)
 		}
@@ -213,6 +215,30 @@ func downVote(ctx context.Context, c *fiber.Ctx, handler *Handler) error {
 	return handler.VoteReview(ctx, c, false)
 }
 
+// HandleUnVoteReview godoc
+//
+// @Security BearerAuth
+//
+// @Summary Remove a vote on a review
+// @Description Take back the upvote or downvote of the user on a review, nothing happens when they did not vote
+// @Tags Reviews
+// @ID unVoteReview
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+//
+// @Success 200 {object} main.JSONResult{data=string} "Success"
+// @Failure 404 {object} main.JSONErrorRes "Review not found"
+// @Router /api/v1/reviews/{reviewId}/vote [delete]
+func HandleUnVoteReview(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		log.Println("unvote review")
+		ctx := GetNewContext(ctx, c)
+		return handler.UnVoteReview(ctx, c)
+	}
+}
+
 // HandleGetFlaggedReviews godoc
 //
 // @Security BearerAuth
@@ -233,7 +259,7 @@ func HandleGetFlaggedReviews(handler *Handler, ctx context.Context) fiber.Handle
 
 	return func(c *fiber.Ctx) error {
 		log.Println("HandleGetFlaggedReviews")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.GetFlaggedReviews(ctx, c)
 	}
 }
@@ -258,7 +284,7 @@ func HandleGetFlaggedReviews(handler *Handler, ctx context.Context) fiber.Handle
 func HandleFlagReview(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log.Println("flag review")
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.FlagReview(ctx, c, true)
 	}
 }
@@ -282,7 +308,7 @@ func HandleFlagReview(handler *Handler, ctx context.Context) fiber.Handler {
 // @Router /api/v1/reviews/{reviewId}/unflag [post]
 func HandleUnflagReview(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 		return handler.FlagReview(ctx, c, false)
 	}
 }
@@ -305,13 +331,583 @@ func HandleUnflagReview(handler *Handler, ctx context.Context) fiber.Handler {
 // @Router /api/v1/reviews/locations [get]
 func HandleGetReviewsLocations(handler *Handler, ctx context.Context) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx = GetNewContext(ctx, c)
+		ctx := GetNewContext(ctx, c)
 
 		return handler.GetReviewsLocations(ctx, c)
 	}
 
 }
 
+// HandleAddComment godoc
+//
+// @Security BearerAuth
+//
+// @Summary Comment on a review
+// @Description Comment on a review, or reply to a comment on it with a parent id. Developers of the game can respond officially.
+// @Tags Reviews
+// @ID addComment
+// @Accept json
+// @Produce json
+//
+// @Param addComment body reviews.AddComment true "addComment request"
+// @Param reviewId path string true "review id"
+//
+// @Success 201 {object} main.JSONResult{data=reviews.AddCommentRes} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 403 {object} main.JSONErrorRes "Not a developer of the game"
+// @Failure 404 {object} main.JSONErrorRes "Review or comment not found"
+// @Failure 422 {object} main.JSONErrorRes "Replies nested too deep"
+// @Router /api/v1/reviews/{reviewId}/comments [post]
+func HandleAddComment(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AddComment(ctx, c)
+	}
+}
+
+// HandleGetComments godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get the comments on a review
+// @Description Get the comments on a review, official responses first
+// @Tags Reviews
+// @ID getComments
+// @Accept json
+// @Produce json
+//
+// @Param getComments query reviews.GetComments true "getComments request"
+// @Param reviewId path string true "review id"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.PaginatedResponse[CommentResponse]} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 404 {object} main.JSONErrorRes "Review not found"
+// @Router /api/v1/reviews/{reviewId}/comments [get]
+func HandleGetComments(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetComments(ctx, c)
+	}
+}
+
+// HandleGetReplies godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get the replies to a comment
+// @Description Get the replies to a comment on a review
+// @Tags Reviews
+// @ID getReplies
+// @Accept json
+// @Produce json
+//
+// @Param getComments query reviews.GetComments true "getComments request"
+// @Param reviewId path string true "review id"
+// @Param commentId path string true "comment id"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.PaginatedResponse[CommentResponse]} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 404 {object} main.JSONErrorRes "Review or comment not found"
+// @Router /api/v1/reviews/{reviewId}/comments/{commentId}/replies [get]
+func HandleGetReplies(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetComments(ctx, c)
+	}
+}
+
+// HandleUpdateComment godoc
+//
+// @Security BearerAuth
+//
+// @Summary Edit a comment
+// @Description Edit a comment, by its author
+// @Tags Reviews
+// @ID updateComment
+// @Accept json
+// @Produce json
+//
+// @Param updateComment body reviews.UpdateCommentRequest true "updateComment request"
+// @Param reviewId path string true "review id"
+// @Param commentId path string true "comment id"
+//
+// @Success 202 {object} main.JSONResult{data=string} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 404 {object} main.JSONErrorRes "Comment not found"
+// @Router /api/v1/reviews/{reviewId}/comments/{commentId} [put]
+func HandleUpdateComment(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.UpdateComment(ctx, c)
+	}
+}
+
+// HandleDeleteComment godoc
+//
+// @Security BearerAuth
+//
+// @Summary Delete a comment
+// @Description Delete a comment, by its author or a moderator. Its replies stay in the thread.
+// @Tags Reviews
+// @ID deleteComment
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param commentId path string true "comment id"
+//
+// @Success 202 {object} main.JSONResult{data=string} "Success"
+// @Failure 404 {object} main.JSONErrorRes "Comment not found"
+// @Router /api/v1/reviews/{reviewId}/comments/{commentId} [delete]
+func HandleDeleteComment(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DeleteComment(ctx, c)
+	}
+}
+
+// HandleVoteComment godoc
+//
+// @Security BearerAuth
+//
+// @Summary Vote on a comment
+// @Description Upvote or downvote a comment
+// @Tags Reviews
+// @ID voteComment
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param commentId path string true "comment id"
+// @Param vote path string true "upvote or downvote"
+//
+// @Success 202 {object} main.JSONResult{data=string} "Success"
+// @Failure 404 {object} main.JSONErrorRes "Comment not found"
+// @Router /api/v1/reviews/{reviewId}/comments/{commentId}/{vote} [post]
+func HandleVoteComment(handler *Handler, ctx context.Context, shouldUpvote bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.VoteComment(ctx, c, shouldUpvote)
+	}
+}
+
+// HandleFlagComment godoc
+//
+// @Security BearerAuth
+//
+// @Summary Flag or unflag a comment
+// @Description Flag or unflag a comment, flagged comments are hidden from the thread
+// @Tags Reviews
+// @ID flagComment
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param commentId path string true "comment id"
+// @Param action path string true "flag or unflag"
+//
+// @Success 202 {object} main.JSONResult{data=string} "Success"
+// @Failure 404 {object} main.JSONErrorRes "Comment not found"
+// @Router /api/v1/reviews/{reviewId}/comments/{commentId}/{action} [post]
+func HandleFlagComment(handler *Handler, ctx context.Context, shouldFlag bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.FlagComment(ctx, c, shouldFlag)
+	}
+}
+
+// HandleGetFlaggedComments godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get flagged comments
+// @Description Get flagged comments
+// @Tags Reviews
+// @ID getFlaggedComments
+// @Accept json
+// @Produce json
+//
+// @Param getFlaggedComments query reviews.GetFlaggedReviewsRequest true "getFlaggedComments request"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.PaginatedResponse[Comment]} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Router /api/v1/reviews/comments/flagged [get]
+func HandleGetFlaggedComments(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetFlaggedComments(ctx, c)
+	}
+}
+
+// HandleAddGameDeveloper godoc
+//
+// @Security BearerAuth
+//
+// @Summary Add a game developer
+// @Description Let a user respond officially to the reviews of a game, admins only
+// @Tags Reviews
+// @ID addGameDeveloper
+// @Accept json
+// @Produce json
+//
+// @Param gameDeveloper body reviews.GameDeveloperRequest true "gameDeveloper request"
+//
+// @Success 202 {object} main.JSONResult{data=string} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 404 {object} main.JSONErrorRes "Game not found"
+// @Router /api/v1/reviews/developers [post]
+func HandleAddGameDeveloper(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AddGameDeveloper(ctx, c)
+	}
+}
+
+// HandleRemoveGameDeveloper godoc
+//
+// @Security BearerAuth
+//
+// @Summary Remove a game developer
+// @Description Stop a user from responding officially to the reviews of a game, admins only
+// @Tags Reviews
+// @ID removeGameDeveloper
+// @Accept json
+// @Produce json
+//
+// @Param gameId path string true "game id"
+// @Param userId path string true "user id"
+//
+// @Success 202 {object} main.JSONResult{data=string} "Success"
+// @Failure 404 {object} main.JSONErrorRes "Game developer not found"
+// @Router /api/v1/reviews/developers/{gameId}/{userId} [delete]
+func HandleRemoveGameDeveloper(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.RemoveGameDeveloper(ctx, c)
+	}
+}
+
+// HandleGetReviewRevisions godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get the revisions of a review
+// @Description Get the revisions of a review, newest first. Revision 0 is the review as first written.
+// @Tags Reviews
+// @ID getReviewRevisions
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param getReviewRevisions query reviews.GetFlaggedReviewsRequest true "limit and offset"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.PaginatedResponse[ReviewRevision]} "Success"
+// @Failure 401 {object} main.JSONErrorRes "Unauthorized"
+// @Router /api/v1/reviews/{reviewId}/revisions [get]
+func HandleGetReviewRevisions(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetReviewRevisions(ctx, c)
+	}
+}
+
+// HandleDiffReviewRevisions godoc
+//
+// @Security BearerAuth
+//
+// @Summary Diff two revisions of a review
+// @Description Get the fields changed between two revisions of a review, with a word diff of the comment. Compares the latest revision with the one before it by default.
+// @Tags Reviews
+// @ID diffReviewRevisions
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param from query int false "revision compared from"
+// @Param to query int false "revision compared to"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.ReviewDiff} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 401 {object} main.JSONErrorRes "Unauthorized"
+// @Failure 404 {object} main.JSONErrorRes "Review revision not found"
+// @Router /api/v1/reviews/{reviewId}/revisions/diff [get]
+func HandleDiffReviewRevisions(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DiffReviewRevisions(ctx, c)
+	}
+}
+
+// HandleMarkSpoilers godoc
+//
+// @Security BearerAuth
+//
+// @Summary Mark a review as containing spoilers
+// @Description Mark a review as containing spoilers, or unmark it
+// @Tags Reviews
+// @ID markReviewSpoilers
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param action path string true "spoiler or unspoiler"
+//
+// @Success 202 {object} main.JSONResult{data=string} "Success"
+// @Failure 404 {object} main.JSONErrorRes "Review not found"
+// @Router /api/v1/reviews/{reviewId}/{action} [post]
+func HandleMarkSpoilers(handler *Handler, ctx context.Context, containsSpoilers bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.MarkSpoilers(ctx, c, containsSpoilers)
+	}
+}
+
+// HandleReportReview godoc
+//
+// @Security BearerAuth
+//
+// @Summary Report a review
+// @Description Report a review for abuse, spam, spoilers, offensive content or another reason, explained in details. A player reports a review once, reporting it again changes the reason. Reports weigh by the record of the reporter and hide the review once they weigh enough, until a moderator resolves them.
+// @Tags Reviews
+// @ID reportReview
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param reportReview body reviews.ReportReview true "reportReview request"
+//
+// @Success 202 {object} main.JSONResult{data=reviews.ReportResult} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 404 {object} main.JSONErrorRes "Review not found"
+// @Failure 422 {object} main.JSONErrorRes "Own review"
+// @Router /api/v1/reviews/{reviewId}/report [post]
+func HandleReportReview(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.ReportReview(ctx, c)
+	}
+}
+
+// HandleGetReportQueue godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get reported reviews
+// @Description Get the reviews with pending reports, the most heavily reported first, with the reports counted by reason
+// @Tags Reviews
+// @ID getReportQueue
+// @Accept json
+// @Produce json
+//
+// @Param getReportQueue query reviews.GetFlaggedReviewsRequest true "getReportQueue request"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.PaginatedResponse[ReportedReview]} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Router /api/v1/reviews/reports/queue [get]
+func HandleGetReportQueue(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetReportQueue(ctx, c)
+	}
+}
+
+// HandleGetReviewReports godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get the reports of a review
+// @Description Get the reports of a review, newest first
+// @Tags Reviews
+// @ID getReviewReports
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param getReviewReports query reviews.GetFlaggedReviewsRequest true "getReviewReports request"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.PaginatedResponse[ReviewReport]} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Router /api/v1/reviews/{reviewId}/reports [get]
+func HandleGetReviewReports(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetReviewReports(ctx, c)
+	}
+}
+
+// HandleResolveReports godoc
+//
+// @Security BearerAuth
+//
+// @Summary Resolve the reports of a review
+// @Description Uphold the pending reports of a review, keeping it hidden, or dismiss them, showing it again
+// @Tags Reviews
+// @ID resolveReports
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param resolveReports body reviews.ResolveReports true "resolveReports request"
+//
+// @Success 202 {object} main.JSONResult{data=string} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 404 {object} main.JSONErrorRes "Review not found"
+// @Router /api/v1/reviews/{reviewId}/reports/resolve [post]
+func HandleResolveReports(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.ResolveReports(ctx, c)
+	}
+}
+
+// HandleAppealCase godoc
+//
+// @Security BearerAuth
+//
+// @Summary Appeal the removal of a review
+// @Description The author appeals the removal of their review, once, opening its moderation case again
+// @Tags Reviews
+// @ID appealCase
+// @Accept json
+// @Produce json
+//
+// @Param reviewId path string true "review id"
+// @Param appealCase body reviews.AppealCase true "appealCase request"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.ModerationCase} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 404 {object} main.JSONErrorRes "Moderation case not found"
+// @Failure 422 {object} main.JSONErrorRes "Not appealable"
+// @Router /api/v1/reviews/{reviewId}/appeal [post]
+func HandleAppealCase(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AppealCase(ctx, c)
+	}
+}
+
+// HandleGetModerationCases godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get the moderation queue
+// @Description Get the moderation cases by state, assignee ("me" for yourself), game, source, appealed or overdue, the ones due first or the last opened first
+// @Tags Reviews
+// @ID getModerationCases
+// @Accept json
+// @Produce json
+//
+// @Param getModerationCases query reviews.GetModerationCases true "getModerationCases request"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.PaginatedResponse[ModerationCase]} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Router /api/v1/reviews/moderation/cases [get]
+func HandleGetModerationCases(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetModerationCases(ctx, c)
+	}
+}
+
+// HandleGetModerationCase godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get a moderation case
+// @Description Get a moderation case with its history
+// @Tags Reviews
+// @ID getModerationCase
+// @Accept json
+// @Produce json
+//
+// @Param caseId path string true "case id"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.ModerationCase} "Success"
+// @Failure 404 {object} main.JSONErrorRes "Moderation case not found"
+// @Router /api/v1/reviews/moderation/cases/{caseId} [get]
+func HandleGetModerationCase(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetModerationCase(ctx, c)
+	}
+}
+
+// HandleAssignCase godoc
+//
+// @Security BearerAuth
+//
+// @Summary Assign a moderation case
+// @Description Take an open case, or as an admin assign any active case to a moderator
+// @Tags Reviews
+// @ID assignCase
+// @Accept json
+// @Produce json
+//
+// @Param caseId path string true "case id"
+// @Param assignCase body reviews.AssignCase false "assignCase request"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.ModerationCase} "Success"
+// @Failure 404 {object} main.JSONErrorRes "Moderation case not found"
+// @Failure 409 {object} main.JSONErrorRes "Changed meanwhile"
+// @Failure 422 {object} main.JSONErrorRes "Not assignable"
+// @Router /api/v1/reviews/moderation/cases/{caseId}/assign [post]
+func HandleAssignCase(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.AssignCase(ctx, c)
+	}
+}
+
+// HandleDecideCase godoc
+//
+// @Security BearerAuth
+//
+// @Summary Decide a moderation case
+// @Description Approve the review, remove it or escalate the case to the admins, with a reason the author is notified of
+// @Tags Reviews
+// @ID decideCase
+// @Accept json
+// @Produce json
+//
+// @Param caseId path string true "case id"
+// @Param decideCase body reviews.DecideCase true "decideCase request"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.ModerationCase} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Failure 404 {object} main.JSONErrorRes "Moderation case not found"
+// @Failure 409 {object} main.JSONErrorRes "Changed meanwhile"
+// @Failure 422 {object} main.JSONErrorRes "Not decidable"
+// @Router /api/v1/reviews/moderation/cases/{caseId}/decide [post]
+func HandleDecideCase(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.DecideCase(ctx, c)
+	}
+}
+
+// HandleGetModerationMetrics godoc
+//
+// @Security BearerAuth
+//
+// @Summary Get moderation metrics
+// @Description Get the active and overdue cases and how quickly the cases opened or decided since a time were handled
+// @Tags Reviews
+// @ID getModerationMetrics
+// @Accept json
+// @Produce json
+//
+// @Param getModerationMetrics query reviews.GetModerationMetricsQuery false "getModerationMetrics request"
+//
+// @Success 200 {object} main.JSONResult{data=reviews.ModerationMetrics} "Success"
+// @Failure 400 {object} main.JSONErrorRes "Bad request"
+// @Router /api/v1/reviews/moderation/metrics [get]
+func HandleGetModerationMetrics(handler *Handler, ctx context.Context) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := GetNewContext(ctx, c)
+		return handler.GetModerationMetrics(ctx, c)
+	}
+}
+
 func GetNewContext(ctx context.Context, c *fiber.Ctx) context.Context {
 	userId := c.Locals("userId").(string)
 	role := c.Locals("role").(string)
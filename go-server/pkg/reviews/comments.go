@@ -0,0 +1,461 @@
+package reviews
+
+import (
+	"context"
+	"fmt"
+	"go-server/pkg/games"
+	"go-server/pkg/notifications"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"html"
+	"log"
+	"strings"
+	"time"
+)
+
+// maxCommentDepth is how deep replies nest, the comments on the review itself
+// being at depth 0. Replying to a comment at this depth is refused.
+const maxCommentDepth = 3
+
+// Comment is a reply to a review or, with a parent, to another comment on it.
+// Deleted comments stay in the thread while they have replies, without their body.
+type Comment struct {
+	Id       primitive.ObjectID `json:"id" bson:"_id"`
+	ReviewId string             `json:"reviewId" bson:"reviewId"`
+	ParentId string             `json:"parentId,omitempty" bson:"parentId"`
+	Depth    int                `json:"depth" bson:"depth"`
+	UserId   string             `json:"userId" bson:"userId"`
+	Body     string             `json:"body" bson:"body"`
+	// written by a developer of the game on behalf of the studio
+	IsOfficial    bool      `json:"isOfficial" bson:"isOfficial"`
+	Votes         int       `json:"votes" bson:"votes"`
+	Replies       int       `json:"replies" bson:"replies"`
+	IsDeleted     bool      `json:"isDeleted" bson:"isDeleted"`
+	IsFlagged     bool      `json:"isFlagged" bson:"isFlagged"`
+	CreatedAt     time.Time `json:"createdAt" bson:"createdAt"`
+	LastUpdatedAt time.Time `json:"lastUpdatedAt" bson:"lastUpdatedAt"`
+}
+
+type CommentVote struct {
+	UserId     string    `json:"userId" bson:"userId"`
+	CommentId  string    `json:"commentId" bson:"commentId"`
+	IsUpVote   bool      `json:"isUpVote" bson:"isUpVote"`
+	IsDownVote bool      `json:"isDownVote" bson:"isDownVote"`
+	VotedAt    time.Time `json:"votedAt" bson:"votedAt"`
+}
+
+type CommentResponse struct {
+	Comment Comment     `json:"comment"`
+	User    User        `json:"user"`
+	Vote    CommentVote `json:"vote"`
+	// the body rendered from its markup, see renderMarkup
+	BodyHtml string `json:"bodyHtml"`
+}
+
+// GameDeveloper lets a user answer the reviews of a game officially.
+type GameDeveloper struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"`
+	UserId    string             `json:"userId" bson:"userId"`
+	GameId    string             `json:"gameId" bson:"gameId"`
+	GrantedBy string             `json:"grantedBy" bson:"grantedBy"`
+	GrantedAt time.Time          `json:"grantedAt" bson:"grantedAt"`
+}
+
+type AddComment struct {
+	Body     string `json:"body" validate:"required,min=1,max=2000"`
+	ParentId string `json:"parentId"`
+	Official bool   `json:"official"`
+}
+
+// comment sort orders, official responses always come first on the review itself
+const (
+	CommentSortOldest = "oldest"
+	CommentSortNewest = "newest"
+	CommentSortTop    = "top"
+)
+
+type GetComments struct {
+	ReviewId string `json:"reviewId"`
+	ParentId string `json:"parentId"`
+	UserId   string `json:"userId"`
+	Limit    int    `json:"limit" validate:"number,gte=0,lte=100"`
+	Offset   int    `json:"offset" validate:"number,gte=0"`
+	Sort     string `json:"sort" validate:"omitempty,oneof=oldest newest top"`
+}
+
+func (s *Service) addComment(ctx context.Context, reviewId string, r *AddComment) (string, error) {
+	userId := ctx.Value("userId").(string)
+
+	review, err := s.commentableReview(ctx, reviewId)
+
+	if err != nil {
+		return "", err
+	}
+
+	comment := Comment{
+		Id:            primitive.NewObjectID(),
+		ReviewId:      reviewId,
+		UserId:        userId,
+		Body:          strings.TrimSpace(r.Body),
+		CreatedAt:     time.Now(),
+		LastUpdatedAt: time.Now(),
+	}
+
+	if comment.Body == "" {
+		return "", ErrBadRequest
+	}
+
+	if r.ParentId != "" {
+		parent, err := s.repository.getComment(ctx, r.ParentId)
+
+		if err != nil {
+			return "", err
+		}
+
+		if parent.ReviewId != reviewId || parent.IsDeleted || parent.IsFlagged {
+			return "", ErrCommentNotFound
+		}
+
+		if parent.Depth >= maxCommentDepth {
+			return "", ErrCommentTooDeep
+		}
+
+		comment.ParentId = r.ParentId
+		comment.Depth = parent.Depth + 1
+	}
+
+	if r.Official {
+		isDeveloper, err := s.repository.isGameDeveloper(ctx, userId, review.GameId)
+
+		if err != nil {
+			return "", err
+		}
+
+		if !isDeveloper {
+			return "", ErrNotGameDeveloper
+		}
+
+		comment.IsOfficial = true
+	}
+
+	err = s.repository.addComment(ctx, &comment)
+
+	if err != nil {
+		return "", err
+	}
+
+	s.checkCommentForOffensiveContent(ctx, comment)
+
+	if review.UserId != userId {
+		go s.notifyReviewAuthor(*review, comment)
+	}
+
+	return comment.Id.Hex(), nil
+}
+
+// commentableReview returns the review the calling user is commenting on or
+// reading the comments of, as long as they can see it.
+func (s *Service) commentableReview(ctx context.Context, reviewId string) (*Review, error) {
+	review, _, err := s.repository.GetReview(ctx, reviewId)
+
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+
+	if review.IsDeleted || review.IsFlagged {
+		return nil, ErrReviewNotFound
+	}
+
+	access, err := s.games.GetContentAccess(ctx, []string{review.GameId})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if access[review.GameId] == games.ContentHidden {
+		return nil, ErrContentRestricted
+	}
+
+	return review, nil
+}
+
+// getComments lists a page of the comments on a review, or of the replies to a
+// comment when the request has a parent.
+func (s *Service) getComments(ctx context.Context, req GetComments) (*PaginatedResponse[CommentResponse], error) {
+	req.UserId = ctx.Value("userId").(string)
+
+	_, err := s.commentableReview(ctx, req.ReviewId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ParentId != "" {
+		parent, err := s.repository.getComment(ctx, req.ParentId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if parent.ReviewId != req.ReviewId || parent.IsFlagged {
+			return nil, ErrCommentNotFound
+		}
+	}
+
+	if req.Limit < 1 {
+		req.Limit = 10
+	}
+
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	comments, err := s.repository.getComments(ctx, &req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range comments.Data {
+		if comments.Data[i].Comment.IsDeleted {
+			comments.Data[i].Comment.Body = ""
+			comments.Data[i].User = User{}
+		}
+
+		comments.Data[i].BodyHtml = renderMarkup(comments.Data[i].Comment.Body)
+	}
+
+	return comments, nil
+}
+
+// getCommentOfReview returns the comment, if it is a live comment on the review.
+func (s *Service) getCommentOfReview(ctx context.Context, reviewId string, commentId string) (*Comment, error) {
+	comment, err := s.repository.getComment(ctx, commentId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.ReviewId != reviewId || comment.IsDeleted {
+		return nil, ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+func (s *Service) updateComment(ctx context.Context, reviewId string, commentId string, body string) error {
+	userId := ctx.Value("userId").(string)
+
+	comment, err := s.getCommentOfReview(ctx, reviewId, commentId)
+
+	if err != nil {
+		return err
+	}
+
+	if comment.UserId != userId {
+		return ErrCommentNotFound
+	}
+
+	body = strings.TrimSpace(body)
+
+	if body == "" {
+		return ErrBadRequest
+	}
+
+	comment.Body = body
+	comment.LastUpdatedAt = time.Now()
+
+	err = s.repository.updateComment(ctx, comment)
+
+	if err != nil {
+		return err
+	}
+
+	s.checkCommentForOffensiveContent(ctx, *comment)
+
+	return nil
+}
+
+func (s *Service) deleteComment(ctx context.Context, reviewId string, commentId string) error {
+	userId := ctx.Value("userId").(string)
+	role := ctx.Value("role").(string)
+
+	comment, err := s.getCommentOfReview(ctx, reviewId, commentId)
+
+	if err != nil {
+		return err
+	}
+
+	if comment.UserId != userId && role != "admin" && role != "moderator" {
+		return ErrCommentNotFound
+	}
+
+	return s.repository.deleteComment(ctx, comment)
+}
+
+func (s *Service) voteComment(ctx context.Context, reviewId string, commentId string, shouldUpvote bool) error {
+	userId := ctx.Value("userId").(string)
+
+	comment, err := s.getCommentOfReview(ctx, reviewId, commentId)
+
+	if err != nil {
+		return err
+	}
+
+	if comment.IsFlagged {
+		return ErrCommentNotFound
+	}
+
+	return s.repository.voteComment(ctx, userId, commentId, shouldUpvote)
+}
+
+func (s *Service) flagComment(ctx context.Context, reviewId string, commentId string, flag bool) error {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return ErrUnauthorized
+	}
+
+	comment, err := s.getCommentOfReview(ctx, reviewId, commentId)
+
+	if err != nil {
+		return err
+	}
+
+	comment.IsFlagged = flag
+
+	return s.repository.updateComment(ctx, comment)
+}
+
+func (s *Service) getFlaggedComments(ctx context.Context, limit int, offset int) (*PaginatedResponse[Comment], error) {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	if limit < 1 {
+		limit = 10
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repository.getFlaggedComments(ctx, limit, offset)
+}
+
+type GameDeveloperRequest struct {
+	UserId string `json:"userId" validate:"required"`
+	GameId string `json:"gameId" validate:"required"`
+}
+
+// addGameDeveloper lets the user respond officially to the reviews of the game.
+func (s *Service) addGameDeveloper(ctx context.Context, req GameDeveloperRequest) error {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" {
+		return ErrUnauthorized
+	}
+
+	exists, err := s.repository.GameExists(ctx, req.GameId)
+
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrGameNotFound
+	}
+
+	return s.repository.addGameDeveloper(ctx, &GameDeveloper{
+		Id:        primitive.NewObjectID(),
+		UserId:    req.UserId,
+		GameId:    req.GameId,
+		GrantedBy: ctx.Value("userId").(string),
+		GrantedAt: time.Now(),
+	})
+}
+
+func (s *Service) removeGameDeveloper(ctx context.Context, req GameDeveloperRequest) error {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" {
+		return ErrUnauthorized
+	}
+
+	return s.repository.removeGameDeveloper(ctx, req.UserId, req.GameId)
+}
+
+// checkCommentForOffensiveContent flags the comment when it has any of the words
+// reviews are flagged for.
+func (s *Service) checkCommentForOffensiveContent(ctx context.Context, comment Comment) {
+	word, found := findOffensiveWord(comment.Body)
+
+	if !found {
+		return
+	}
+
+	log.Println("Found offensive word in comment " + comment.Id.Hex() + ": " + word)
+
+	go func(comment Comment) {
+		comment.IsFlagged = true
+
+		err := s.repository.updateComment(ctx, &comment)
+		if err != nil {
+			log.Println("Error flagging comment: " + err.Error())
+		}
+	}(comment)
+}
+
+func findOffensiveWord(text string) (string, bool) {
+	text = strings.ToLower(text)
+
+	for _, word := range offensiveWords {
+		if strings.Contains(text, word) {
+			return word, true
+		}
+	}
+
+	return "", false
+}
+
+// notifyReviewAuthor emails the author of the review about a new comment on it.
+func (s *Service) notifyReviewAuthor(review Review, comment Comment) {
+	ctx := context.Background()
+
+	email, err := s.repository.getUserEmail(ctx, review.UserId)
+
+	if err != nil || email == "" {
+		log.Println("Could not notify the author of review " + review.Id.Hex())
+		return
+	}
+
+	subject := "Someone replied to your review"
+	if comment.IsOfficial {
+		subject = "The developers responded to your review"
+	}
+
+	excerpt := []rune(comment.Body)
+	if len(excerpt) > 280 {
+		excerpt = append(excerpt[:280], []rune("...")...)
+	}
+
+	err = notifications.SendEmail(notifications.EmailRequest{
+		From:    "cool_game_rev.com",
+		To:      email,
+		Subject: subject,
+		Body:    fmt.Sprintf("<p>%s:</p><blockquote>%s</blockquote>", subject, html.EscapeString(string(excerpt))),
+	})
+
+	if err != nil {
+		log.Println("Error notifying review author: " + err.Error())
+	}
+}
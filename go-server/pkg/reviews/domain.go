@@ -3,30 +3,48 @@ package reviews
 import (
 	"context"
 	"fmt"
+	"go-server/pkg/games"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"log"
 	"math"
 	"strings"
-	"sync"
 	"time"
 )
 
 type Service struct {
 	repository Repository
+	games      GameContentGate
+}
+
+// GameContentGate tells how the calling user gets the games shown given their
+// content settings, games.Service enforces them for the review listings too.
+type GameContentGate interface {
+	GetContentAccess(ctx context.Context, gameIds []string) (map[string]games.ContentAccess, error)
 }
 
 type AddReview struct {
-	Rating   int      `json:"rating" validate:"required,number,gte=0,lte2"`
-	Comment  string   `json:"comment" validate:"required,min=5,max=2000"`
-	GameId   string   `json:"gameId" validate:"required"`
-	UserId   string   `json:"userId" validate:"required"`
-	Location Location `json:"location" validate:"required"`
+	Rating    int            `json:"rating" validate:"required,number,gte=1,lte=5"`
+	SubScores map[string]int `json:"subScores,omitempty" validate:"omitempty,dive,keys,oneof=gameplay story graphics audio performance,endkeys,gte=1,lte=5"`
+	Comment   string         `json:"comment" validate:"required,min=5,max=2000"`
+	GameId    string         `json:"gameId" validate:"required"`
+	UserId    string         `json:"userId" validate:"required"`
+	Location  Location       `json:"location" validate:"required"`
+	// nil leaves the spoiler flag of an updated review as it is
+	ContainsSpoilers *bool `json:"containsSpoilers,omitempty"`
 }
 
 type ReviewResponse struct {
 	Review Review `json:"review"`
 	User   User   `json:"user"`
 	Vote   Vote   `json:"vote"`
+	// the game is above the content settings of the user, who asked for it blurred
+	Restricted bool `json:"restricted,omitempty"`
+	// the review was changed since it was first written, EditCount times
+	Edited    bool `json:"edited"`
+	EditCount int  `json:"editCount"`
+	// the comment rendered from its markup, see renderMarkup
+	CommentHtml string `json:"commentHtml"`
 }
 
 func (r *Review) String() string {
@@ -50,6 +68,8 @@ type Vote struct {
 	ReviewId   string `json:"reviewId" bson:"reviewId"`
 	IsUpVote   bool   `json:"isUpVote" bson:"isUpVote"`
 	IsDownVote bool   `json:"isDownVote" bson:"isDownVote"`
+	// when the vote was cast or last changed, counted by the game charts
+	VotedAt time.Time `json:"votedAt" bson:"votedAt"`
 }
 
 type User struct {
@@ -78,12 +98,27 @@ type Review struct {
 	IsDeleted     bool               `json:"isDeleted" bson:"isDeleted"`
 	IsFlagged     bool               `json:"isFlagged" bson:"isFlagged"`
 	Votes         int                `json:"votes"`
-	UserId        string             `json:"userId"bson:"userId"`
+	UserId        string             `json:"userId" bson:"userId"`
 	Location      Location           `json:"location" bson:"location"`
+	// optional scores of aspects of the game, by games.SubScoreDimensions
+	SubScores map[string]int `json:"subScores,omitempty" bson:"subScores,omitempty"`
+	// how many times the content was edited, the number of its latest revision
+	EditCount int `json:"editCount" bson:"editCount"`
+	// the review gives away the story, marked by its author or a moderator
+	ContainsSpoilers bool `json:"containsSpoilers" bson:"containsSpoilers"`
+	// the upvotes and downvotes counted apart, Votes being up minus down, and the
+	// Wilson lower bound of the share of upvotes the most helpful sort is by
+	UpVotes     int     `json:"upVotes" bson:"upVotes"`
+	DownVotes   int     `json:"downVotes" bson:"downVotes"`
+	Helpfulness float64 `json:"helpfulness" bson:"helpfulness"`
+	// hidden from the listings once the weight of its pending reports reached
+	// reportHideThreshold, until a moderator resolves them
+	IsHidden     bool    `json:"isHidden" bson:"isHidden"`
+	ReportWeight float64 `json:"reportWeight" bson:"reportWeight"`
 }
 
 type PaginatedResponseType interface {
-	ReviewResponse | Review
+	ReviewResponse | Review | CommentResponse | Comment | ReviewRevision | ReportedReview | ReviewReport | ModerationCase
 }
 
 type PaginatedResponse[V PaginatedResponseType] struct {
@@ -95,6 +130,7 @@ type PaginatedResponse[V PaginatedResponseType] struct {
 	ItemsPerPage int  `json:"itemsPerPage"`
 }
 
+// Sort orders the reviews by the key, or most helpful first by ReviewSortHelpful.
 type Sort struct {
 	Key string `json:"sortKey"`
 	Asc bool   `json:"asc"`
@@ -110,58 +146,139 @@ type Repository interface {
 	Vote(ctx context.Context, req VoteRequest, shouldUpvote bool) error
 	GetVote(ctx context.Context, userId string, gameId string) (*Vote, error)
 	GetFlaggedReviews(ctx context.Context, gameId string, limit int, offset int) (*PaginatedResponse[Review], error)
-	UpdateReviewStats(ctx context.Context, id string, rating int, ratingCount int) error
 	getReviewersForTimeAgo(ctx context.Context, ago time.Time) (*[]Review, error)
-}
-
-func NewService(r Repository) *Service {
+	getUserReviewForGame(ctx context.Context, userId string, gameId string) (*Review, error)
+	getDuplicateReviews(ctx context.Context) ([][]Review, error)
+	getReviewRevisions(ctx context.Context, reviewId string, limit int, offset int) (*PaginatedResponse[ReviewRevision], error)
+	getReviewRevision(ctx context.Context, reviewId string, revision int) (*ReviewRevision, error)
+	unVote(ctx context.Context, userId string, reviewId string) error
+	dedupeVotes(ctx context.Context) ([]primitive.ObjectID, error)
+	countReviewVotes(ctx context.Context, reviewIds []primitive.ObjectID) (map[primitive.ObjectID]VoteCounts, error)
+	getUncountedReviewVotes(ctx context.Context) (map[primitive.ObjectID]VoteCounts, error)
+	setReviewVoteCounts(ctx context.Context, reviewId primitive.ObjectID, up int, down int) error
+	addComment(ctx context.Context, comment *Comment) error
+	getComment(ctx context.Context, id string) (*Comment, error)
+	getComments(ctx context.Context, req *GetComments) (*PaginatedResponse[CommentResponse], error)
+	updateComment(ctx context.Context, comment *Comment) error
+	deleteComment(ctx context.Context, comment *Comment) error
+	voteComment(ctx context.Context, userId string, commentId string, shouldUpvote bool) error
+	getFlaggedComments(ctx context.Context, limit int, offset int) (*PaginatedResponse[Comment], error)
+	isGameDeveloper(ctx context.Context, userId string, gameId string) (bool, error)
+	addGameDeveloper(ctx context.Context, developer *GameDeveloper) error
+	removeGameDeveloper(ctx context.Context, userId string, gameId string) error
+	getUserEmail(ctx context.Context, userId string) (string, error)
+	getReporterRecord(ctx context.Context, reporterId string) (int, int, error)
+	addReport(ctx context.Context, report *ReviewReport) (bool, error)
+	addReportWeight(ctx context.Context, reviewId primitive.ObjectID, weight float64, threshold float64) (bool, error)
+	getReportQueue(ctx context.Context, limit int, offset int) (*PaginatedResponse[ReportedReview], error)
+	getReviewReports(ctx context.Context, reviewId string, limit int, offset int) (*PaginatedResponse[ReviewReport], error)
+	resolveReports(ctx context.Context, reviewId primitive.ObjectID, decision string, moderatorId string) error
+	getReviewIncludingDeleted(ctx context.Context, id string) (*Review, error)
+	openCase(ctx context.Context, moderationCase *ModerationCase) (bool, error)
+	getCase(ctx context.Context, caseId string) (*ModerationCase, error)
+	getLatestCase(ctx context.Context, reviewId string) (*ModerationCase, error)
+	getCases(ctx context.Context, req *GetModerationCases) (*PaginatedResponse[ModerationCase], error)
+	updateCase(ctx context.Context, moderationCase *ModerationCase) error
+	getModerationMetrics(ctx context.Context, since time.Time) (*ModerationMetrics, error)
+}
+
+func NewService(r Repository, gate GameContentGate) *Service {
 	return &Service{
 		repository: r,
+		games:      gate,
 	}
 }
 
-func (s *Service) addReview(ctx context.Context, r *AddReview) (string, error) {
+// addReview saves the first review of the user for the game. When they already
+// have one, its id is returned with ErrReviewAlreadyExists, or with upsert the
+// existing review is updated instead and the game stats move by the difference.
+func (s *Service) addReview(ctx context.Context, r *AddReview, upsert bool) (string, bool, error) {
 
 	exists, err := s.repository.GameExists(ctx, r.GameId)
 	if err != nil {
-		if !exists {
-			return "", ErrGameNotFound
+		return "", false, err
+	}
+
+	if !exists {
+		return "", false, ErrGameNotFound
+	}
+
+	existing, err := s.repository.getUserReviewForGame(ctx, r.UserId, r.GameId)
+	if err != nil {
+		return "", false, err
+	}
+
+	if existing == nil {
+		// create review
+		review := getReviewFromAddReview(r)
+
+		// save review, the unique index refuses it when a concurrent request saved one first
+		err = s.repository.AddReview(ctx, &review)
+
+		if err == nil {
+			//TODO: we can queue this up to be processed later
+			s.checkForPossibleOffensiveContent(ctx, review)
+
+			return review.Id.Hex(), false, nil
+		}
+
+		if err != ErrReviewAlreadyExists {
+			return "", false, err
+		}
+
+		existing, err = s.repository.getUserReviewForGame(ctx, r.UserId, r.GameId)
+		if err != nil {
+			return "", false, err
+		}
+
+		if existing == nil {
+			return "", false, UnknownError
 		}
+	}
+
+	if !upsert {
+		return existing.Id.Hex(), false, ErrReviewAlreadyExists
+	}
+
+	mergeReviews(existing, r)
 
-		return "", err
+	err = s.repository.UpdateReview(ctx, existing)
+	if err != nil {
+		return "", false, err
 	}
 
-	// create review
-	review := getReviewFromAddReview(r)
+	s.checkForPossibleOffensiveContent(ctx, *existing)
+
+	return existing.Id.Hex(), true, nil
+}
 
-	// save review
-	err = s.repository.AddReview(ctx, &review)
+// dedupeReviews keeps the last updated review of each user for each game and
+// deletes their others, from before a user could review a game only once.
+func (s *Service) dedupeReviews(ctx context.Context) (int, error) {
+
+	duplicates, err := s.repository.getDuplicateReviews(ctx)
 
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	waitGroup := sync.WaitGroup{}
+	deleted := 0
 
-	waitGroup.Add(2)
+	for _, reviews := range duplicates {
+		// the most recently updated review comes first
+		for i := 1; i < len(reviews); i++ {
+			reviews[i].IsDeleted = true
 
-	//TODO: we can queue this up to be processed later
-	go func() {
-		defer waitGroup.Done()
-		s.checkForPossibleOffensiveContent(ctx, review)
-	}()
+			if err = s.repository.UpdateReview(ctx, &reviews[i]); err != nil {
+				log.Println("could not delete the duplicate review", reviews[i].Id.Hex(), err)
+				continue
+			}
 
-	go func() {
-		defer waitGroup.Done()
-		err := s.updateReviewStats(ctx, review.GameId, review.Rating, 1)
-		if err != nil {
-			return
+			deleted++
 		}
-	}()
-
-	waitGroup.Wait()
+	}
 
-	return review.Id.Hex(), nil
+	return deleted, nil
 }
 
 type T struct {
@@ -184,13 +301,23 @@ func (s *Service) getReview(ctx context.Context, id string) (*ReviewResponse, er
 		return nil, ErrReviewNotFound
 	}
 
+	access, err := s.games.GetContentAccess(ctx, []string{review.GameId})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if access[review.GameId] == games.ContentHidden {
+		return nil, ErrContentRestricted
+	}
+
 	vote, _ := s.repository.GetVote(ctx, userId, id)
 
-	return &ReviewResponse{
-		Review: *review,
-		User:   *user,
-		Vote:   *vote,
-	}, nil
+	response := newReviewResponse(*review, *user)
+	response.Vote = *vote
+	response.Restricted = access[review.GameId] == games.ContentBlurred
+
+	return &response, nil
 }
 
 type GetReviewsForGame struct {
@@ -199,10 +326,37 @@ type GetReviewsForGame struct {
 	Offset int    `json:"offset" validate:"required,number,gte=0"`
 	UserId string `json:"userId,omitempty"`
 	SortBy Sort   `json:"sortBy,omitempty"`
+	// reviews scoring the sub-score, at least MinSubScore if set. A sub-score as
+	// sort key sorts by it.
+	SubScore    string `json:"subScore,omitempty"`
+	MinSubScore int    `json:"minSubScore,omitempty"`
+}
+
+// subScoreFilter is the filter of the reviews scoring the requested sub-score, if any.
+func (req *GetReviewsForGame) subScoreFilter() bson.D {
+	if req.SubScore == "" {
+		return nil
+	}
+
+	if req.MinSubScore > 0 {
+		return bson.D{{"subScores." + req.SubScore, bson.D{{"$gte", req.MinSubScore}}}}
+	}
+
+	return bson.D{{"subScores." + req.SubScore, bson.D{{"$exists", true}}}}
 }
 
 func (s *Service) getReviewsForGame(ctx context.Context, req GetReviewsForGame) (*PaginatedResponse[ReviewResponse], error) {
 
+	access, err := s.games.GetContentAccess(ctx, []string{req.GameId})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if access[req.GameId] == games.ContentHidden {
+		return nil, ErrContentRestricted
+	}
+
 	reviews, err := s.repository.GetReviewsForGame(ctx, &req)
 
 	if err != nil {
@@ -213,6 +367,10 @@ func (s *Service) getReviewsForGame(ctx context.Context, req GetReviewsForGame)
 		return nil, ErrReviewNotFound
 	}
 
+	for i := range reviews.Data {
+		reviews.Data[i].Restricted = access[req.GameId] == games.ContentBlurred
+	}
+
 	return reviews, nil
 }
 
@@ -234,6 +392,39 @@ func (s *Service) getReviewsForUser(ctx context.Context, req GetReviewsForGame)
 		return nil, ErrReviewNotFound
 	}
 
+	return s.screenReviews(ctx, reviews)
+}
+
+// screenReviews drops the reviews of games above the content settings of the
+// calling user, the page comes up short, and marks the ones to blur.
+func (s *Service) screenReviews(ctx context.Context, reviews *PaginatedResponse[ReviewResponse]) (*PaginatedResponse[ReviewResponse], error) {
+
+	gameIds := make([]string, 0, len(reviews.Data))
+	for _, review := range reviews.Data {
+		gameIds = append(gameIds, review.Review.GameId)
+	}
+
+	access, err := s.games.GetContentAccess(ctx, gameIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	screened := make([]ReviewResponse, 0, len(reviews.Data))
+
+	for _, review := range reviews.Data {
+		switch access[review.Review.GameId] {
+		case games.ContentHidden:
+			continue
+		case games.ContentBlurred:
+			review.Restricted = true
+		}
+
+		screened = append(screened, review)
+	}
+
+	reviews.Data = screened
+
 	return reviews, nil
 }
 
@@ -252,31 +443,16 @@ func (s *Service) updateReview(ctx context.Context, id string, r *AddReview) err
 
 	mergeReviews(oldReview, r)
 
+	// the game stats are updated in the same transaction as the review
 	err = s.repository.UpdateReview(ctx, oldReview)
 	if err != nil {
 		return err
 	}
 
-	waitGroup := sync.WaitGroup{}
-
-	waitGroup.Add(2)
-
 	if strings.TrimSpace(r.Comment) != "" {
-		go func() {
-			defer waitGroup.Done()
-			s.checkForPossibleOffensiveContent(ctx, *oldReview)
-		}()
+		s.checkForPossibleOffensiveContent(ctx, *oldReview)
 	}
 
-	if r.Rating > 0 {
-		go func() {
-			defer waitGroup.Done()
-			s.updateReviewStats(ctx, r.GameId, r.Rating-oldReview.Rating, 0)
-		}()
-	}
-
-	waitGroup.Wait()
-
 	return nil
 }
 
@@ -302,20 +478,12 @@ func (s *Service) deleteReview(ctx context.Context, id string) error {
 
 	review.IsDeleted = true
 
+	// removes the rating from the game stats in the same transaction
 	err = s.repository.UpdateReview(ctx, review)
 	if err != nil {
 		return err
 	}
 
-	go func() {
-		// update review stats
-		err := s.updateReviewStats(ctx, review.GameId, -review.Rating, -1)
-		if err != nil {
-			log.Println(err)
-		}
-
-	}()
-
 	return nil
 }
 
@@ -336,6 +504,43 @@ func (s *Service) voteReview(ctx context.Context, reviewId string, shouldUpvote
 	return s.repository.Vote(ctx, voteReq, shouldUpvote)
 }
 
+// unVoteReview takes back the vote of the user on the review.
+func (s *Service) unVoteReview(ctx context.Context, reviewId string) error {
+	userId := ctx.Value("userId").(string)
+
+	return s.repository.unVote(ctx, userId, reviewId)
+}
+
+// dedupeVotes keeps the latest vote of each user on each review and counts the
+// votes of the reviews that had duplicates again.
+func (s *Service) dedupeVotes(ctx context.Context) (int, error) {
+
+	reviewIds, err := s.repository.dedupeVotes(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	counts, err := s.repository.countReviewVotes(ctx, reviewIds)
+
+	if err != nil {
+		return 0, err
+	}
+
+	recounted := 0
+
+	for reviewId, count := range counts {
+		if err = s.repository.setReviewVoteCounts(ctx, reviewId, count.Up, count.Down); err != nil {
+			log.Println("could not count the votes of review", reviewId.Hex(), err)
+			continue
+		}
+
+		recounted++
+	}
+
+	return recounted, nil
+}
+
 func (s *Service) flagReview(ctx context.Context, id string, flag bool) error {
 	role := ctx.Value("role").(string)
 
@@ -353,20 +558,37 @@ func (s *Service) flagReview(ctx context.Context, id string, flag bool) error {
 		return ErrReviewNotFound
 	}
 
-	review.IsFlagged = flag
+	if flag {
+		// the author is notified once the case is opened
+		return s.holdForModeration(ctx, review, CaseSourceFlag, "")
+	}
 
-	err = s.repository.UpdateReview(ctx, review)
+	review.IsFlagged = false
+
+	return s.repository.UpdateReview(ctx, review)
+}
+
+// markSpoilers sets whether the review contains spoilers, for moderators.
+func (s *Service) markSpoilers(ctx context.Context, id string, containsSpoilers bool) error {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return ErrUnauthorized
+	}
+
+	review, _, err := s.repository.GetReview(ctx, id)
 
 	if err != nil {
 		return err
 	}
 
-	go func() {
-		//TODO: notify user that their review was deleted
-		log.Println("Notifying user that their review was deleted: " + review.String())
-	}()
+	if review == nil {
+		return ErrReviewNotFound
+	}
+
+	review.ContainsSpoilers = containsSpoilers
 
-	return nil
+	return s.repository.UpdateReview(ctx, review)
 }
 
 func (s *Service) getFlaggedReviews(ctx context.Context, gameId string, limit int, offset int) (*PaginatedResponse[Review], error) {
@@ -410,41 +632,121 @@ func mergeReviews(review *Review, r *AddReview) {
 		review.Rating = r.Rating
 	}
 
+	if r.ContainsSpoilers != nil {
+		review.ContainsSpoilers = *r.ContainsSpoilers
+	}
+
+	for dimension, score := range r.SubScores {
+		if review.SubScores == nil {
+			review.SubScores = map[string]int{}
+		}
+
+		review.SubScores[dimension] = score
+	}
+
 	review.LastUpdatedAt = time.Now()
 }
 
 func (s *Service) checkForPossibleOffensiveContent(ctx context.Context, review Review) {
 	// check for offensive words
 	log.Println("Checking for offensive words in review: " + review.String())
-	for _, word := range offensiveWords {
-		if strings.Contains(strings.ToLower(review.Comment), word) {
-			log.Println("Found offensive word: " + word)
-			go func(reviewId string) {
-				err := s.flagReview(ctx, reviewId, true)
-				if err != nil {
-					log.Println("Error flagging review: " + err.Error())
-					return
-				}
-			}(review.Id.Hex())
-			return
+	if word, found := findOffensiveWord(review.Comment); found {
+		log.Println("Found offensive word: " + word)
+		go func(review Review) {
+			err := s.holdForModeration(ctx, &review, CaseSourceOffensive, "offensive word: "+word)
+			if err != nil {
+				log.Println("Error flagging review: " + err.Error())
+				return
+			}
+		}(review)
+	}
+}
+
+// RatingDelta is the change a review write makes to the rating stats of its game.
+type RatingDelta struct {
+	Sum       int
+	Count     int
+	Histogram map[string]int
+	// the change to the totals of each sub-score
+	SubScores map[string]RatingDelta
+}
+
+// reviewRatingDelta returns the delta of a review going from old to new, a nil
+// or deleted review not counting towards the stats.
+func reviewRatingDelta(old *Review, new *Review) RatingDelta {
+	delta := RatingDelta{Histogram: map[string]int{}, SubScores: map[string]RatingDelta{}}
+
+	if old != nil && !old.IsDeleted {
+		delta.add(old.Rating, -1)
+
+		for dimension, score := range old.SubScores {
+			delta.addSubScore(dimension, score, -1)
+		}
+	}
+
+	if new != nil && !new.IsDeleted {
+		delta.add(new.Rating, 1)
+
+		for dimension, score := range new.SubScores {
+			delta.addSubScore(dimension, score, 1)
 		}
 	}
 
+	return delta
 }
 
-func (s *Service) updateReviewStats(ctx context.Context, gameId string, rating int, ratingCount int) error {
-	// update game stats
-	log.Println("Updating review stats for game: " + gameId)
-	return s.repository.UpdateReviewStats(ctx, gameId, rating, ratingCount)
+func (d *RatingDelta) add(rating int, sign int) {
+	bucket, ok := games.RatingBucket(rating)
+	if !ok {
+		// the same as RecalculateRatings, which leaves it out
+		return
+	}
+
+	d.Sum += sign * rating
+	d.Count += sign
+	d.Histogram[bucket] += sign
+}
+
+func (d *RatingDelta) addSubScore(dimension string, score int, sign int) {
+	if !games.IsSubScoreDimension(dimension) {
+		return
+	}
+
+	subScore, ok := d.SubScores[dimension]
+	if !ok {
+		subScore = RatingDelta{Histogram: map[string]int{}}
+	}
+
+	subScore.add(score, sign)
+	d.SubScores[dimension] = subScore
+}
+
+func (d RatingDelta) isZero() bool {
+	if d.Sum != 0 || d.Count != 0 {
+		return false
+	}
+
+	for _, count := range d.Histogram {
+		if count != 0 {
+			return false
+		}
+	}
+
+	for _, subScore := range d.SubScores {
+		if !subScore.isZero() {
+			return false
+		}
+	}
 
+	return true
 }
 
-type LocationReqType int
+type LocationReqType = games.TimeWindow
 
 const (
-	Day LocationReqType = iota
-	Week
-	Month
+	Day   = games.Day
+	Week  = games.Week
+	Month = games.Month
 )
 
 type LatLng struct {
@@ -567,23 +869,11 @@ func isLocationClose(location1 LatLng, location2 LatLng, proximityInKm float64)
 }
 
 func getTimeAgoFromReqType(reqType LocationReqType, value int) time.Time {
-	if value == 0 {
-		value = 1
-	}
-	switch reqType {
-	case Day:
-		return time.Now().AddDate(0, 0, -value)
-	case Week:
-		return time.Now().AddDate(0, 0, -value*7)
-	case Month:
-		return time.Now().AddDate(0, -value, 0)
-	default:
-		return time.Now().AddDate(0, 0, -value)
-	}
+	return reqType.Since(time.Now(), value)
 }
 
 func getReviewFromAddReview(r *AddReview) Review {
-	return Review{
+	review := Review{
 		Rating:        r.Rating,
 		Comment:       r.Comment,
 		CreatedAt:     time.Now(),
@@ -594,8 +884,12 @@ func getReviewFromAddReview(r *AddReview) Review {
 		IsFlagged:     false,
 		Votes:         0,
 		UserId:        r.UserId,
+		SubScores:     r.SubScores,
 	}
 
+	review.ContainsSpoilers = r.ContainsSpoilers != nil && *r.ContainsSpoilers
+
+	return review
 }
 
 var offensiveWords []string
@@ -12,4 +12,26 @@ var ErrGameNotFound = errors.New("game-not-found")
 
 var ErrReviewNotFound = errors.New("review-not-found")
 
+var ErrReviewAlreadyExists = errors.New("review-already-exists")
+
+var ErrReviewRevisionNotFound = errors.New("review-revision-not-found")
+
 var ErrUnauthorized = errors.New("unauthorized")
+
+var ErrContentRestricted = errors.New("content-restricted")
+
+var ErrCommentNotFound = errors.New("comment-not-found")
+
+var ErrCommentTooDeep = errors.New("comment-too-deep")
+
+var ErrNotGameDeveloper = errors.New("not-game-developer")
+
+var ErrCannotReportOwnReview = errors.New("cannot-report-own-review")
+
+var ErrCaseNotFound = errors.New("case-not-found")
+
+var ErrCaseTransition = errors.New("case-transition-not-allowed")
+
+var ErrCaseConflict = errors.New("case-changed-concurrently")
+
+var ErrAppealNotAllowed = errors.New("appeal-not-allowed")
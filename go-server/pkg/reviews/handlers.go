@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"go-server/pkg/games"
 	"log"
+	"strconv"
+	"time"
 )
 
 type Handler struct {
@@ -19,10 +22,20 @@ func NewHandler(service *Service) *Handler {
 }
 
 type AddReviewRequest struct {
-	Rating   int      `json:"rating" validate:"required,number,gte=0,lte2"`
-	Comment  string   `json:"comment" validate:"required,min=5,max=2000"`
-	GameId   string   `json:"gameId" validate:"required"`
-	Location Location `json:"location" validate:"required"`
+	Rating int `json:"rating" validate:"required,number,gte=1,lte=5"`
+	// gameplay, story, graphics, audio and performance, each optional
+	SubScores map[string]int `json:"subScores,omitempty" validate:"omitempty,dive,keys,oneof=gameplay story graphics audio performance,endkeys,gte=1,lte=5"`
+	// markup with **bold**, *italic*, lists, [links](https://example.com) and ||spoilers||
+	Comment          string   `json:"comment" validate:"required,min=5,max=2000"`
+	GameId           string   `json:"gameId" validate:"required"`
+	Location         Location `json:"location" validate:"required"`
+	ContainsSpoilers bool     `json:"containsSpoilers"`
+}
+
+// AddReviewQuery asks for the existing review of the user to be updated rather
+// than refusing the new one.
+type AddReviewQuery struct {
+	Upsert bool `json:"upsert"`
 }
 
 func (h *Handler) AddReview(ctx context.Context, c *fiber.Ctx) error {
@@ -30,48 +43,71 @@ func (h *Handler) AddReview(ctx context.Context, c *fiber.Ctx) error {
 
 	err := c.BodyParser(&req)
 
-	if err != nil {
+	if err != nil || validator.New().Struct(req) != nil {
 		return AddReviewErrorResponse(c, ErrBadRequest)
 	}
 	userId := c.Locals("userId").(string)
 	review := &AddReview{
-		Rating:   req.Rating,
-		Comment:  req.Comment,
-		GameId:   req.GameId,
-		UserId:   userId,
-		Location: req.Location,
+		Rating:           req.Rating,
+		SubScores:        req.SubScores,
+		Comment:          req.Comment,
+		GameId:           req.GameId,
+		UserId:           userId,
+		Location:         req.Location,
+		ContainsSpoilers: &req.ContainsSpoilers,
 	}
 
-	id, err := h.Service.addReview(ctx, review)
+	var query AddReviewQuery
+
+	if c.QueryParser(&query) != nil {
+		return AddReviewErrorResponse(c, ErrBadRequest)
+	}
+
+	id, updated, err := h.Service.addReview(ctx, review, query.Upsert)
+
+	if err == ErrReviewAlreadyExists {
+		return ReviewAlreadyExistsResponse(c, id)
+	}
 
 	if err != nil {
 		return AddReviewErrorResponse(c, err)
 	}
 
+	if updated {
+		return UpsertReviewSuccessResp(c, id)
+	}
+
 	return AddReviewSuccessResp(c, id)
 }
 
+// UpdateReviewRequest changes the fields that are set, the sub-scores given
+// replacing the ones of the review.
 type UpdateReviewRequest struct {
-	Rating  int    `json:"rating" validate:"required,number,gte=0,lte2"`
-	Comment string `json:"comment" validate:"required,min=5,max=2000"`
-	GameId  string `json:"gameId" validate:"required"`
+	Rating    int            `json:"rating" validate:"omitempty,number,gte=1,lte=5"`
+	SubScores map[string]int `json:"subScores,omitempty" validate:"omitempty,dive,keys,oneof=gameplay story graphics audio performance,endkeys,gte=1,lte=5"`
+	Comment   string         `json:"comment" validate:"omitempty,min=5,max=2000"`
+	GameId    string         `json:"gameId"`
+	// nil leaves the spoiler flag as it is
+	ContainsSpoilers *bool `json:"containsSpoilers"`
 }
 
 func (h *Handler) UpdateReview(ctx context.Context, c *fiber.Ctx) error {
-	var req AddReviewRequest
+	var req UpdateReviewRequest
 
 	err := c.BodyParser(&req)
 
-	if err != nil {
+	if err != nil || validator.New().Struct(req) != nil {
 		return UpdateReviewErrorResponse(c, ErrBadRequest)
 	}
 
 	id := c.Params("id")
 
 	review := &AddReview{
-		Rating:  req.Rating,
-		Comment: req.Comment,
-		GameId:  req.GameId,
+		Rating:           req.Rating,
+		SubScores:        req.SubScores,
+		Comment:          req.Comment,
+		GameId:           req.GameId,
+		ContainsSpoilers: req.ContainsSpoilers,
 	}
 
 	err = h.Service.updateReview(ctx, id, review)
@@ -130,6 +166,19 @@ func (h *Handler) VoteReview(ctx context.Context, c *fiber.Ctx, upvote bool) err
 
 }
 
+func (h *Handler) UnVoteReview(ctx context.Context, c *fiber.Ctx) error {
+
+	id := c.Params("id")
+
+	err := h.Service.unVoteReview(ctx, id)
+
+	if err != nil {
+		return VoteReviewErrorResponse(c, err)
+	}
+
+	return UnVoteReviewSuccessResp(c)
+}
+
 type GetFlaggedReviewsRequest struct {
 	GameId string `json:"gameId"`
 	Limit  int    `json:"limit"`
@@ -249,6 +298,14 @@ func getReview(ctx context.Context, c *fiber.Ctx, isForGame bool, getReview GetR
 		req.SortBy.Asc = false
 	}
 
+	if req.SubScore != "" && !games.IsSubScoreDimension(req.SubScore) {
+		return GetReviewErrorResponse(c, ErrBadRequest)
+	}
+
+	if games.IsSubScoreDimension(req.SortBy.Key) {
+		req.SortBy.Key = "subScores." + req.SortBy.Key
+	}
+
 	reviews, err := getReview(ctx, req)
 
 	if err != nil {
@@ -257,3 +314,393 @@ func getReview(ctx context.Context, c *fiber.Ctx, isForGame bool, getReview GetR
 
 	return GetReviewsSuccessResp(c, reviews)
 }
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" validate:"required,min=1,max=2000"`
+}
+
+func (h *Handler) AddComment(ctx context.Context, c *fiber.Ctx) error {
+	var req AddComment
+
+	err := c.BodyParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return CommentErrorResponse(c, ErrBadRequest)
+	}
+
+	id, err := h.Service.addComment(ctx, c.Params("id"), &req)
+
+	if err != nil {
+		return CommentErrorResponse(c, err)
+	}
+
+	return AddCommentSuccessResp(c, id)
+}
+
+func (h *Handler) GetComments(ctx context.Context, c *fiber.Ctx) error {
+	var req GetComments
+
+	err := c.QueryParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return CommentErrorResponse(c, ErrBadRequest)
+	}
+
+	req.ReviewId = c.Params("id")
+	req.ParentId = c.Params("commentId")
+
+	comments, err := h.Service.getComments(ctx, req)
+
+	if err != nil {
+		return CommentErrorResponse(c, err)
+	}
+
+	return GetCommentsSuccessResp(c, comments)
+}
+
+func (h *Handler) UpdateComment(ctx context.Context, c *fiber.Ctx) error {
+	var req UpdateCommentRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return CommentErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.Service.updateComment(ctx, c.Params("id"), c.Params("commentId"), req.Body)
+
+	if err != nil {
+		return CommentErrorResponse(c, err)
+	}
+
+	return CommentSuccessResp(c, "Comment updated")
+}
+
+func (h *Handler) DeleteComment(ctx context.Context, c *fiber.Ctx) error {
+	err := h.Service.deleteComment(ctx, c.Params("id"), c.Params("commentId"))
+
+	if err != nil {
+		return CommentErrorResponse(c, err)
+	}
+
+	return CommentSuccessResp(c, "Comment deleted")
+}
+
+func (h *Handler) VoteComment(ctx context.Context, c *fiber.Ctx, upvote bool) error {
+	err := h.Service.voteComment(ctx, c.Params("id"), c.Params("commentId"), upvote)
+
+	if err != nil {
+		return CommentErrorResponse(c, err)
+	}
+
+	return CommentSuccessResp(c, "Comment voted")
+}
+
+func (h *Handler) FlagComment(ctx context.Context, c *fiber.Ctx, shouldFlag bool) error {
+	err := h.Service.flagComment(ctx, c.Params("id"), c.Params("commentId"), shouldFlag)
+
+	if err != nil {
+		return CommentErrorResponse(c, err)
+	}
+
+	message := "Comment unflagged"
+	if shouldFlag {
+		message = "Comment flagged"
+	}
+
+	return CommentSuccessResp(c, message)
+}
+
+func (h *Handler) GetFlaggedComments(ctx context.Context, c *fiber.Ctx) error {
+	var req GetFlaggedReviewsRequest
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return CommentErrorResponse(c, ErrBadRequest)
+	}
+
+	comments, err := h.Service.getFlaggedComments(ctx, req.Limit, req.Offset)
+
+	if err != nil {
+		return CommentErrorResponse(c, err)
+	}
+
+	return GetFlaggedCommentsSuccessResp(c, comments)
+}
+
+func (h *Handler) AddGameDeveloper(ctx context.Context, c *fiber.Ctx) error {
+	var req GameDeveloperRequest
+
+	err := c.BodyParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return GameDeveloperErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.Service.addGameDeveloper(ctx, req)
+
+	if err != nil {
+		return GameDeveloperErrorResponse(c, err)
+	}
+
+	return GameDeveloperSuccessResp(c, "Game developer added")
+}
+
+func (h *Handler) RemoveGameDeveloper(ctx context.Context, c *fiber.Ctx) error {
+	req := GameDeveloperRequest{
+		GameId: c.Params("gameId"),
+		UserId: c.Params("userId"),
+	}
+
+	err := h.Service.removeGameDeveloper(ctx, req)
+
+	if err != nil {
+		return GameDeveloperErrorResponse(c, err)
+	}
+
+	return GameDeveloperSuccessResp(c, "Game developer removed")
+}
+
+func (h *Handler) GetReviewRevisions(ctx context.Context, c *fiber.Ctx) error {
+	var req GetFlaggedReviewsRequest
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return ReviewRevisionsErrorResponse(c, ErrBadRequest)
+	}
+
+	revisions, err := h.Service.getReviewRevisions(ctx, c.Params("id"), req.Limit, req.Offset)
+
+	if err != nil {
+		return ReviewRevisionsErrorResponse(c, err)
+	}
+
+	return GetReviewRevisionsSuccessResp(c, revisions)
+}
+
+// DiffReviewRevisions compares the from and to revisions of the query, the
+// latest revision and the one before it by default.
+func (h *Handler) DiffReviewRevisions(ctx context.Context, c *fiber.Ctx) error {
+	from, to := -1, -1
+
+	for param, revision := range map[string]*int{"from": &from, "to": &to} {
+		if value := c.Query(param); value != "" {
+			number, err := strconv.Atoi(value)
+
+			if err != nil || number < 0 {
+				return ReviewRevisionsErrorResponse(c, ErrBadRequest)
+			}
+
+			*revision = number
+		}
+	}
+
+	diff, err := h.Service.diffReviewRevisions(ctx, c.Params("id"), from, to)
+
+	if err != nil {
+		return ReviewRevisionsErrorResponse(c, err)
+	}
+
+	return DiffReviewRevisionsSuccessResp(c, diff)
+}
+
+func (h *Handler) MarkSpoilers(ctx context.Context, c *fiber.Ctx, containsSpoilers bool) error {
+	id := c.Params("id")
+
+	err := h.Service.markSpoilers(ctx, id, containsSpoilers)
+
+	if err != nil {
+		return FlagReviewErrorResponse(c, err)
+	}
+
+	return MarkSpoilersSuccessResp(c, containsSpoilers)
+}
+
+func (h *Handler) ReportReview(ctx context.Context, c *fiber.Ctx) error {
+	var req ReportReview
+
+	err := c.BodyParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return ReportErrorResponse(c, ErrBadRequest)
+	}
+
+	result, err := h.Service.reportReview(ctx, c.Params("id"), &req)
+
+	if err != nil {
+		return ReportErrorResponse(c, err)
+	}
+
+	return ReportReviewSuccessResp(c, result)
+}
+
+func (h *Handler) GetReportQueue(ctx context.Context, c *fiber.Ctx) error {
+	var req GetFlaggedReviewsRequest
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return ReportErrorResponse(c, ErrBadRequest)
+	}
+
+	reviews, err := h.Service.getReportQueue(ctx, req.Limit, req.Offset)
+
+	if err != nil {
+		return ReportErrorResponse(c, err)
+	}
+
+	return GetReportQueueSuccessResp(c, reviews)
+}
+
+func (h *Handler) GetReviewReports(ctx context.Context, c *fiber.Ctx) error {
+	var req GetFlaggedReviewsRequest
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return ReportErrorResponse(c, ErrBadRequest)
+	}
+
+	reports, err := h.Service.getReviewReports(ctx, c.Params("id"), req.Limit, req.Offset)
+
+	if err != nil {
+		return ReportErrorResponse(c, err)
+	}
+
+	return GetReviewReportsSuccessResp(c, reports)
+}
+
+func (h *Handler) ResolveReports(ctx context.Context, c *fiber.Ctx) error {
+	var req ResolveReports
+
+	err := c.BodyParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return ReportErrorResponse(c, ErrBadRequest)
+	}
+
+	err = h.Service.resolveReports(ctx, c.Params("id"), req.Decision)
+
+	if err != nil {
+		return ReportErrorResponse(c, err)
+	}
+
+	return ResolveReportsSuccessResp(c, req.Decision)
+}
+
+func (h *Handler) GetModerationCases(ctx context.Context, c *fiber.Ctx) error {
+	var req GetModerationCases
+
+	err := c.QueryParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return ModerationErrorResponse(c, ErrBadRequest)
+	}
+
+	cases, err := h.Service.getModerationCases(ctx, &req)
+
+	if err != nil {
+		return ModerationErrorResponse(c, err)
+	}
+
+	return GetModerationCasesSuccessResp(c, cases)
+}
+
+func (h *Handler) GetModerationCase(ctx context.Context, c *fiber.Ctx) error {
+	moderationCase, err := h.Service.getModerationCase(ctx, c.Params("caseId"))
+
+	if err != nil {
+		return ModerationErrorResponse(c, err)
+	}
+
+	return ModerationCaseSuccessResp(c, "Moderation case found", moderationCase)
+}
+
+func (h *Handler) AssignCase(ctx context.Context, c *fiber.Ctx) error {
+	var req AssignCase
+
+	// the body is optional, assigning to the moderator asking
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return ModerationErrorResponse(c, ErrBadRequest)
+		}
+	}
+
+	moderationCase, err := h.Service.assignCase(ctx, c.Params("caseId"), req.AssigneeId)
+
+	if err != nil {
+		return ModerationErrorResponse(c, err)
+	}
+
+	return ModerationCaseSuccessResp(c, "Moderation case assigned", moderationCase)
+}
+
+func (h *Handler) DecideCase(ctx context.Context, c *fiber.Ctx) error {
+	var req DecideCase
+
+	err := c.BodyParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return ModerationErrorResponse(c, ErrBadRequest)
+	}
+
+	moderationCase, err := h.Service.decideCase(ctx, c.Params("caseId"), &req)
+
+	if err != nil {
+		return ModerationErrorResponse(c, err)
+	}
+
+	return ModerationCaseSuccessResp(c, "Moderation case "+req.Decision, moderationCase)
+}
+
+func (h *Handler) AppealCase(ctx context.Context, c *fiber.Ctx) error {
+	var req AppealCase
+
+	err := c.BodyParser(&req)
+
+	if err != nil || validator.New().Struct(req) != nil {
+		return ModerationErrorResponse(c, ErrBadRequest)
+	}
+
+	moderationCase, err := h.Service.appealCase(ctx, c.Params("id"), req.Reason)
+
+	if err != nil {
+		return ModerationErrorResponse(c, err)
+	}
+
+	return ModerationCaseSuccessResp(c, "Appeal submitted", moderationCase)
+}
+
+type GetModerationMetricsQuery struct {
+	// RFC 3339, a week ago by default
+	Since string `json:"since"`
+}
+
+func (h *Handler) GetModerationMetrics(ctx context.Context, c *fiber.Ctx) error {
+	var req GetModerationMetricsQuery
+	var since time.Time
+
+	err := c.QueryParser(&req)
+
+	if err != nil {
+		return ModerationErrorResponse(c, ErrBadRequest)
+	}
+
+	if req.Since != "" {
+		since, err = time.Parse(time.RFC3339, req.Since)
+
+		if err != nil {
+			return ModerationErrorResponse(c, ErrBadRequest)
+		}
+	}
+
+	metrics, err := h.Service.getModerationMetrics(ctx, since)
+
+	if err != nil {
+		return ModerationErrorResponse(c, err)
+	}
+
+	return GetModerationMetricsSuccessResp(c, metrics)
+}
@@ -0,0 +1,116 @@
+package reviews
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"log"
+	"math"
+)
+
+// helpfulnessZ is the z-score of the 95% confidence the helpfulness is bounded with.
+const helpfulnessZ = 1.96
+
+// ReviewSortHelpful lists the most helpful reviews first, by their helpfulness.
+const ReviewSortHelpful = "helpful"
+
+// VoteCounts are the upvotes and downvotes of a review.
+type VoteCounts struct {
+	Up   int `json:"up" bson:"up"`
+	Down int `json:"down" bson:"down"`
+}
+
+// wilsonLowerBound is the lower bound of the Wilson score interval of the share
+// of upvotes: the helpfulness the review has at least, with 95% confidence. A
+// review at 1 up and 0 down scores 0.21 while one at 101 up and 100 down scores
+// 0.43, and one at 90 up and 10 down 0.83.
+func wilsonLowerBound(up int, down int) float64 {
+	n := float64(up + down)
+
+	if n <= 0 {
+		return 0
+	}
+
+	p := float64(up) / n
+	z2 := helpfulnessZ * helpfulnessZ
+
+	return (p + z2/(2*n) - helpfulnessZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+// helpfulnessUpdate is an update pipeline recomputing the helpfulness of a review
+// from its stored vote counts, the same as wilsonLowerBound. It is applied after
+// the counts are incremented so it never depends on stale reads.
+func helpfulnessUpdate() bson.A {
+	z2 := helpfulnessZ * helpfulnessZ
+
+	up := bson.D{{"$ifNull", bson.A{"$upVotes", 0}}}
+	down := bson.D{{"$ifNull", bson.A{"$downVotes", 0}}}
+
+	bound := bson.D{{"$divide", bson.A{
+		bson.D{{"$subtract", bson.A{
+			bson.D{{"$add", bson.A{"$$p", bson.D{{"$divide", bson.A{z2, bson.D{{"$multiply", bson.A{2, "$$n"}}}}}}}}},
+			bson.D{{"$multiply", bson.A{helpfulnessZ, bson.D{{"$sqrt", bson.D{{"$divide", bson.A{
+				bson.D{{"$add", bson.A{
+					bson.D{{"$multiply", bson.A{"$$p", bson.D{{"$subtract", bson.A{1, "$$p"}}}}}},
+					bson.D{{"$divide", bson.A{z2, bson.D{{"$multiply", bson.A{4, "$$n"}}}}}},
+				}}},
+				"$$n",
+			}}}}}}}},
+		}}},
+		bson.D{{"$add", bson.A{1, bson.D{{"$divide", bson.A{z2, "$$n"}}}}}},
+	}}}
+
+	return bson.A{
+		bson.D{{"$set", bson.D{{"helpfulness", bson.D{{"$let", bson.D{
+			{"vars", bson.D{{"up", up}, {"n", bson.D{{"$add", bson.A{up, down}}}}}},
+			{"in", bson.D{{"$cond", bson.A{
+				bson.D{{"$gt", bson.A{"$$n", 0}}},
+				bson.D{{"$let", bson.D{
+					{"vars", bson.D{{"p", bson.D{{"$divide", bson.A{"$$up", "$$n"}}}}}},
+					{"in", bound},
+				}}},
+				0,
+			}}}},
+		}}}}}}},
+	}
+}
+
+// sort is the order the reviews are listed in, most helpful first for
+// ReviewSortHelpful and else by the sort key.
+func (req *GetReviewsForGame) sort() bson.D {
+	direction := -1
+	if req.SortBy.Asc {
+		direction = 1
+	}
+
+	if req.SortBy.Key == ReviewSortHelpful {
+		return bson.D{{"helpfulness", direction}, {"upVotes", direction}, {"createdAt", -1}}
+	}
+
+	return bson.D{{req.SortBy.Key, direction}}
+}
+
+// backfillVoteCounts counts the upvotes and downvotes of the reviews from before
+// they were counted apart, from their votes, and scores their helpfulness.
+func (s *Service) backfillVoteCounts(ctx context.Context) (int, error) {
+
+	counts, err := s.repository.getUncountedReviewVotes(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	filled := 0
+
+	for reviewId, count := range counts {
+		err = s.repository.setReviewVoteCounts(ctx, reviewId, count.Up, count.Down)
+
+		if err != nil {
+			log.Println("could not count the votes of review", reviewId.Hex(), err)
+			continue
+		}
+
+		filled++
+	}
+
+	return filled, nil
+}
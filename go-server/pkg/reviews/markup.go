@@ -0,0 +1,216 @@
+package reviews
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// renderMarkup renders the text of a review or comment as HTML. The markup is
+// small on purpose:
+//
+//	**bold**, *italic* or _italic_, ||spoiler||, [text](https://example.com)
+//	lines starting with "- " or "* " make a bulleted list, "1. " a numbered one
+//	blank lines separate paragraphs, other line breaks are kept
+//
+// Everything else is escaped, so the only tags in the output are the ones made
+// here: p, br, strong, em, ul, ol, li, span class="spoiler" and a, whose href is
+// an http or https url and which is rel="nofollow ugc noopener".
+func renderMarkup(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var out strings.Builder
+	var paragraph []string
+
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+
+		out.WriteString("<p>")
+		out.WriteString(renderInline(strings.Join(paragraph, "\n"), false))
+		out.WriteString("</p>")
+
+		paragraph = nil
+	}
+
+	for i := 0; i < len(lines); {
+		line := strings.TrimSpace(lines[i])
+
+		if line == "" {
+			flushParagraph()
+			i++
+			continue
+		}
+
+		if tag, _, ok := listItem(line); ok {
+			flushParagraph()
+
+			out.WriteString("<" + tag + ">")
+
+			for ; i < len(lines); i++ {
+				itemTag, item, ok := listItem(strings.TrimSpace(lines[i]))
+
+				if !ok || itemTag != tag {
+					break
+				}
+
+				out.WriteString("<li>")
+				out.WriteString(renderInline(item, false))
+				out.WriteString("</li>")
+			}
+
+			out.WriteString("</" + tag + ">")
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+		i++
+	}
+
+	flushParagraph()
+
+	return out.String()
+}
+
+// listItem tells whether the line is an item of a bulleted or numbered list,
+// returning the list tag and the text of the item.
+func listItem(line string) (string, string, bool) {
+	if strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") {
+		return "ul", strings.TrimSpace(line[2:]), true
+	}
+
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+
+	if digits > 0 && digits < 4 && strings.HasPrefix(line[digits:], ". ") {
+		return "ol", strings.TrimSpace(line[digits+2:]), true
+	}
+
+	return "", "", false
+}
+
+// renderInline renders the emphasis, spoilers and links of a paragraph or list
+// item. Markers without a closing one are kept as text, links in the text of a
+// link are not made links.
+func renderInline(text string, inLink bool) string {
+	var out strings.Builder
+	var plain strings.Builder
+
+	flushPlain := func() {
+		out.WriteString(strings.ReplaceAll(html.EscapeString(plain.String()), "\n", "<br>"))
+		plain.Reset()
+	}
+
+	wrap := func(open string, close string, inner string) {
+		flushPlain()
+		out.WriteString(open)
+		out.WriteString(renderInline(inner, inLink))
+		out.WriteString(close)
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		if inner, ok := delimited(rest, "||"); ok {
+			wrap(`<span class="spoiler">`, "</span>", inner)
+			i += len(inner) + 4
+			continue
+		}
+
+		if inner, ok := delimited(rest, "**"); ok {
+			wrap("<strong>", "</strong>", inner)
+			i += len(inner) + 4
+			continue
+		}
+
+		if (rest[0] == '*' || rest[0] == '_') && startsWord(text, i) {
+			if inner, ok := delimited(rest, rest[:1]); ok {
+				wrap("<em>", "</em>", inner)
+				i += len(inner) + 2
+				continue
+			}
+		}
+
+		if rest[0] == '[' && !inLink {
+			if label, href, length, ok := link(rest); ok {
+				flushPlain()
+				out.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow ugc noopener" target="_blank">`)
+				out.WriteString(renderInline(label, true))
+				out.WriteString("</a>")
+				i += length
+				continue
+			}
+		}
+
+		plain.WriteByte(text[i])
+		i++
+	}
+
+	flushPlain()
+
+	return out.String()
+}
+
+// delimited returns the text between the marker text starts with and the next
+// one, which must not be empty nor start or end with a space.
+func delimited(text string, marker string) (string, bool) {
+	if !strings.HasPrefix(text, marker) {
+		return "", false
+	}
+
+	end := strings.Index(text[len(marker):], marker)
+
+	if end <= 0 {
+		return "", false
+	}
+
+	inner := text[len(marker) : len(marker)+end]
+
+	if strings.TrimSpace(inner) != inner {
+		return "", false
+	}
+
+	return inner, true
+}
+
+// startsWord tells whether the marker at i opens emphasis rather than being in
+// the middle of a word, like the underscores of snake_case.
+func startsWord(text string, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	previous := rune(text[i-1])
+
+	return !unicode.IsLetter(previous) && !unicode.IsDigit(previous)
+}
+
+// link parses a [label](url) link at the start of text, returning its length.
+// Only absolute http and https urls make links.
+func link(text string) (string, string, int, bool) {
+	closeLabel := strings.Index(text, "](")
+
+	if closeLabel <= 1 || strings.Contains(text[:closeLabel], "\n") {
+		return "", "", 0, false
+	}
+
+	closeUrl := strings.IndexByte(text[closeLabel+2:], ')')
+
+	if closeUrl <= 0 {
+		return "", "", 0, false
+	}
+
+	href := text[closeLabel+2 : closeLabel+2+closeUrl]
+
+	parsed, err := url.Parse(href)
+
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || strings.ContainsAny(href, " \n\"'<>") {
+		return "", "", 0, false
+	}
+
+	return text[1:closeLabel], parsed.String(), closeLabel + 2 + closeUrl + 1, true
+}
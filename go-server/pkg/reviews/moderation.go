@@ -0,0 +1,451 @@
+package reviews
+
+import (
+	"context"
+	"fmt"
+	"go-server/pkg/notifications"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"html"
+	"log"
+	"time"
+)
+
+// states of a moderation case. Open, assigned and escalated cases are active,
+// a review has one active case at most. Approved keeps the review, removed
+// deletes it, and the author can appeal a removal once, opening the case again.
+const (
+	CaseOpen      = "open"
+	CaseAssigned  = "assigned"
+	CaseApproved  = "approved"
+	CaseRemoved   = "removed"
+	CaseEscalated = "escalated"
+)
+
+// what opened a case
+const (
+	CaseSourceFlag      = "flag"
+	CaseSourceReports   = "reports"
+	CaseSourceOffensive = "offensive-content"
+)
+
+// caseSla is how long a case may stay active before it is overdue, from when
+// it was opened or appealed.
+const caseSla = 24 * time.Hour
+
+// caseTransitions lists the states a case moves to from each state by the
+// moderators, appeals aside.
+var caseTransitions = map[string][]string{
+	CaseOpen:      {CaseAssigned, CaseApproved, CaseRemoved, CaseEscalated},
+	CaseAssigned:  {CaseAssigned, CaseApproved, CaseRemoved, CaseEscalated},
+	CaseEscalated: {CaseAssigned, CaseApproved, CaseRemoved},
+}
+
+// CaseEvent is a step of a case: its opening, an assignment, a decision or an appeal.
+type CaseEvent struct {
+	Status     string    `json:"status" bson:"status"`
+	ActorId    string    `json:"actorId,omitempty" bson:"actorId,omitempty"`
+	AssigneeId string    `json:"assigneeId,omitempty" bson:"assigneeId,omitempty"`
+	Reason     string    `json:"reason,omitempty" bson:"reason,omitempty"`
+	At         time.Time `json:"at" bson:"at"`
+}
+
+// CaseAppeal is the appeal of the author against the removal of their review.
+type CaseAppeal struct {
+	Reason    string    `json:"reason" bson:"reason"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// ModerationCase is the moderation of a review, from its opening to the
+// decision of a moderator with their reason, and the appeal of the author if any.
+type ModerationCase struct {
+	Id         primitive.ObjectID `json:"id" bson:"_id"`
+	ReviewId   string             `json:"reviewId" bson:"reviewId"`
+	GameId     string             `json:"gameId" bson:"gameId"`
+	AuthorId   string             `json:"authorId" bson:"authorId"`
+	Source     string             `json:"source" bson:"source"`
+	Note       string             `json:"note,omitempty" bson:"note,omitempty"`
+	Status     string             `json:"status" bson:"status"`
+	Active     bool               `json:"active" bson:"active"`
+	AssigneeId string             `json:"assigneeId,omitempty" bson:"assigneeId,omitempty"`
+	// the last decision, the earlier ones are in the history
+	Decision   string      `json:"decision,omitempty" bson:"decision,omitempty"`
+	Reason     string      `json:"reason,omitempty" bson:"reason,omitempty"`
+	DecidedBy  string      `json:"decidedBy,omitempty" bson:"decidedBy,omitempty"`
+	Appeal     *CaseAppeal `json:"appeal,omitempty" bson:"appeal,omitempty"`
+	History    []CaseEvent `json:"history" bson:"history"`
+	OpenedAt   time.Time   `json:"openedAt" bson:"openedAt"`
+	DueAt      time.Time   `json:"dueAt" bson:"dueAt"`
+	AssignedAt *time.Time  `json:"assignedAt,omitempty" bson:"assignedAt,omitempty"`
+	DecidedAt  *time.Time  `json:"decidedAt,omitempty" bson:"decidedAt,omitempty"`
+	UpdatedAt  time.Time   `json:"updatedAt" bson:"updatedAt"`
+	// counts the saves of the case, a save of a case read before another fails
+	Version int `json:"version" bson:"version"`
+}
+
+// GetModerationCases filters the moderation queue. AssigneeId "me" stands for
+// the moderator asking, Unassigned for the cases nobody took yet.
+type GetModerationCases struct {
+	Status     string `json:"status" validate:"omitempty,oneof=open assigned approved removed escalated"`
+	AssigneeId string `json:"assigneeId"`
+	Unassigned bool   `json:"unassigned"`
+	GameId     string `json:"gameId"`
+	Source     string `json:"source" validate:"omitempty,oneof=flag reports offensive-content"`
+	Appealed   bool   `json:"appealed"`
+	Overdue    bool   `json:"overdue"`
+	// oldest lists the cases due first, newest the last opened first
+	Sort   string `json:"sort" validate:"omitempty,oneof=oldest newest"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
+
+type AssignCase struct {
+	// the moderator asking when empty
+	AssigneeId string `json:"assigneeId"`
+}
+
+type DecideCase struct {
+	Decision string `json:"decision" validate:"required,oneof=approved removed escalated"`
+	Reason   string `json:"reason" validate:"required,max=1000"`
+}
+
+type AppealCase struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
+
+// ModerationMetrics are the size of the queue and how quickly cases were
+// handled since a time, against caseSla.
+type ModerationMetrics struct {
+	Since    time.Time      `json:"since"`
+	SlaHours float64        `json:"slaHours"`
+	Active   map[string]int `json:"active"`
+	Overdue  int            `json:"overdue"`
+	Opened   int            `json:"opened"`
+	Decided  map[string]int `json:"decided"`
+	// decided cases decided before they were due
+	DecidedWithinSla     int     `json:"decidedWithinSla"`
+	AvgMinutesToAssign   float64 `json:"avgMinutesToAssign"`
+	AvgMinutesToDecision float64 `json:"avgMinutesToDecision"`
+	Appealed             int     `json:"appealed"`
+}
+
+// canMoveCase tells whether a moderator can move a case in the state to the next one.
+func canMoveCase(from string, to string) bool {
+	for _, next := range caseTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// holdForModeration flags the review and opens a case for it, unless it has an
+// active one, notifying the author.
+func (s *Service) holdForModeration(ctx context.Context, review *Review, source string, note string) error {
+	if !review.IsFlagged {
+		review.IsFlagged = true
+
+		if err := s.repository.UpdateReview(ctx, review); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+
+	moderationCase := &ModerationCase{
+		Id:        primitive.NewObjectID(),
+		ReviewId:  review.Id.Hex(),
+		GameId:    review.GameId,
+		AuthorId:  review.UserId,
+		Source:    source,
+		Note:      note,
+		Status:    CaseOpen,
+		Active:    true,
+		History:   []CaseEvent{{Status: CaseOpen, Reason: source, At: now}},
+		OpenedAt:  now,
+		DueAt:     now.Add(caseSla),
+		UpdatedAt: now,
+	}
+
+	created, err := s.repository.openCase(ctx, moderationCase)
+
+	if err != nil || !created {
+		return err
+	}
+
+	go s.notifyCaseAuthor(*moderationCase, "Your review is being moderated",
+		"Your review was held for moderation. A moderator will look at it shortly.")
+
+	return nil
+}
+
+// getModerationCases lists the moderation queue, for moderators.
+func (s *Service) getModerationCases(ctx context.Context, req *GetModerationCases) (*PaginatedResponse[ModerationCase], error) {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	if req.AssigneeId == "me" {
+		req.AssigneeId = ctx.Value("userId").(string)
+	}
+
+	if req.Limit < 1 {
+		req.Limit = 10
+	}
+
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	return s.repository.getCases(ctx, req)
+}
+
+func (s *Service) getModerationCase(ctx context.Context, caseId string) (*ModerationCase, error) {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	return s.repository.getCase(ctx, caseId)
+}
+
+// assignCase assigns an active case to a moderator, the one asking by default.
+// Only admins assign cases to others or take escalated ones.
+func (s *Service) assignCase(ctx context.Context, caseId string, assigneeId string) (*ModerationCase, error) {
+	role := ctx.Value("role").(string)
+	userId := ctx.Value("userId").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	if assigneeId == "" {
+		assigneeId = userId
+	}
+
+	moderationCase, err := s.repository.getCase(ctx, caseId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !canMoveCase(moderationCase.Status, CaseAssigned) {
+		return nil, ErrCaseTransition
+	}
+
+	if role != "admin" && (assigneeId != userId || moderationCase.Status == CaseEscalated) {
+		return nil, ErrUnauthorized
+	}
+
+	now := time.Now()
+
+	moderationCase.Status = CaseAssigned
+	moderationCase.AssigneeId = assigneeId
+	moderationCase.AssignedAt = &now
+	moderationCase.UpdatedAt = now
+	moderationCase.History = append(moderationCase.History, CaseEvent{Status: CaseAssigned, ActorId: userId, AssigneeId: assigneeId, At: now})
+
+	if err = s.repository.updateCase(ctx, moderationCase); err != nil {
+		return nil, err
+	}
+
+	return moderationCase, nil
+}
+
+// decideCase approves or removes the review of an active case, or escalates it
+// to the admins, with the reason of the moderator. Approving shows the review
+// again and dismisses its reports, removing deletes it and upholds them, once
+// the case is decided. The author is notified of the decision and how to
+// appeal a removal.
+func (s *Service) decideCase(ctx context.Context, caseId string, req *DecideCase) (*ModerationCase, error) {
+	role := ctx.Value("role").(string)
+	userId := ctx.Value("userId").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	moderationCase, err := s.repository.getCase(ctx, caseId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !canMoveCase(moderationCase.Status, req.Decision) {
+		return nil, ErrCaseTransition
+	}
+
+	// moderators decide the open cases and their own, admins the escalated ones too
+	if role != "admin" && (moderationCase.Status == CaseEscalated ||
+		(moderationCase.Status == CaseAssigned && moderationCase.AssigneeId != userId)) {
+		return nil, ErrUnauthorized
+	}
+
+	previous := *moderationCase
+	now := time.Now()
+
+	moderationCase.Status = req.Decision
+	moderationCase.Active = req.Decision == CaseEscalated
+	moderationCase.UpdatedAt = now
+	moderationCase.History = append(moderationCase.History, CaseEvent{Status: req.Decision, ActorId: userId, Reason: req.Reason, At: now})
+
+	if req.Decision != CaseEscalated {
+		moderationCase.Decision = req.Decision
+		moderationCase.Reason = req.Reason
+		moderationCase.DecidedBy = userId
+		moderationCase.DecidedAt = &now
+	}
+
+	// the case moves first, so of two moderators deciding it at once only the
+	// one whose update wins changes the review and its reports
+	if err = s.repository.updateCase(ctx, moderationCase); err != nil {
+		return nil, err
+	}
+
+	if req.Decision != CaseEscalated {
+		if err = s.applyCaseDecision(ctx, moderationCase, req.Decision); err != nil {
+			// back to where it was, to be decided again, unless it changed since
+			previous.Version = moderationCase.Version
+
+			if rollbackErr := s.repository.updateCase(ctx, &previous); rollbackErr != nil {
+				log.Println("could not reopen moderation case " + caseId + ": " + rollbackErr.Error())
+			}
+
+			return nil, err
+		}
+	}
+
+	switch req.Decision {
+	case CaseApproved:
+		go s.notifyCaseAuthor(*moderationCase, "Your review was approved",
+			"A moderator reviewed your review and kept it: "+req.Reason)
+	case CaseRemoved:
+		body := "A moderator removed your review: " + req.Reason
+		if moderationCase.Appeal == nil {
+			body += " You can appeal this decision once from the review."
+		}
+		go s.notifyCaseAuthor(*moderationCase, "Your review was removed", body)
+	}
+
+	return moderationCase, nil
+}
+
+// applyCaseDecision keeps or deletes the review of the case and resolves its
+// pending reports the same way. Approving restores the review only when the
+// case removed it, a review its author deleted meanwhile stays deleted.
+func (s *Service) applyCaseDecision(ctx context.Context, moderationCase *ModerationCase, decision string) error {
+	review, err := s.repository.getReviewIncludingDeleted(ctx, moderationCase.ReviewId)
+
+	if err != nil {
+		return err
+	}
+
+	remove := decision == CaseRemoved
+	deleted := review.IsDeleted
+
+	if remove {
+		deleted = true
+	} else if moderationCase.Appeal != nil {
+		// only a removal is appealed, approving the appeal undoes it
+		deleted = false
+	}
+
+	if review.IsDeleted != deleted || review.IsFlagged {
+		// deleting or restoring moves the rating in the game stats too
+		review.IsDeleted = deleted
+		review.IsFlagged = false
+
+		if err = s.repository.UpdateReview(ctx, review); err != nil {
+			return err
+		}
+	}
+
+	reports := ReportDismissed
+	if remove {
+		reports = ReportUpheld
+	}
+
+	return s.repository.resolveReports(ctx, review.Id, reports, ctx.Value("userId").(string))
+}
+
+// appealCase lets the author appeal the removal of their review once, opening
+// its case again for a moderator, due within caseSla.
+func (s *Service) appealCase(ctx context.Context, reviewId string, reason string) (*ModerationCase, error) {
+	userId := ctx.Value("userId").(string)
+
+	moderationCase, err := s.repository.getLatestCase(ctx, reviewId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if moderationCase.AuthorId != userId {
+		return nil, ErrCaseNotFound
+	}
+
+	if moderationCase.Status != CaseRemoved || moderationCase.Appeal != nil {
+		return nil, ErrAppealNotAllowed
+	}
+
+	now := time.Now()
+
+	moderationCase.Status = CaseOpen
+	moderationCase.Active = true
+	moderationCase.AssigneeId = ""
+	moderationCase.AssignedAt = nil
+	moderationCase.Appeal = &CaseAppeal{Reason: reason, CreatedAt: now}
+	moderationCase.DueAt = now.Add(caseSla)
+	moderationCase.UpdatedAt = now
+	moderationCase.History = append(moderationCase.History, CaseEvent{Status: CaseOpen, ActorId: userId, Reason: "appeal: " + reason, At: now})
+
+	if err = s.repository.updateCase(ctx, moderationCase); err != nil {
+		return nil, err
+	}
+
+	return moderationCase, nil
+}
+
+// getModerationMetrics measures the queue and the cases opened or decided
+// since the time, the last week by default, for moderators.
+func (s *Service) getModerationMetrics(ctx context.Context, since time.Time) (*ModerationMetrics, error) {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	if since.IsZero() {
+		since = time.Now().AddDate(0, 0, -7)
+	}
+
+	return s.repository.getModerationMetrics(ctx, since)
+}
+
+func (s *Service) notifyCaseAuthor(moderationCase ModerationCase, subject string, body string) {
+	ctx := context.Background()
+
+	email, err := s.repository.getUserEmail(ctx, moderationCase.AuthorId)
+
+	if err != nil || email == "" {
+		log.Println("Could not notify the author of review " + moderationCase.ReviewId)
+		return
+	}
+
+	err = notifications.SendEmail(notifications.EmailRequest{
+		From:    "cool_game_rev.com",
+		To:      email,
+		Subject: subject,
+		Body:    fmt.Sprintf("<p>%s</p>", html.EscapeString(body)),
+	})
+
+	if err != nil {
+		log.Println("Error notifying review author: " + err.Error())
+	}
+}
@@ -37,6 +37,23 @@ func AddReviewSuccessResp(c *fiber.Ctx, reviewId string) error {
 	})
 }
 
+// ReviewAlreadyExistsResponse tells the user they already reviewed the game, with
+// the id of their review to update instead.
+func ReviewAlreadyExistsResponse(c *fiber.Ctx, reviewId string) error {
+	return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+		"message": "You already reviewed this game",
+		"error":   ErrReviewAlreadyExists.Error(),
+		"data":    AddReviewRes{ReviewId: reviewId},
+	})
+}
+
+func UpsertReviewSuccessResp(c *fiber.Ctx, reviewId string) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Review updated",
+		"data":    AddReviewRes{ReviewId: reviewId},
+	})
+}
+
 func UpdateReviewErrorResponse(c *fiber.Ctx, err error) error {
 	status := 0
 	message := ""
@@ -44,7 +61,7 @@ func UpdateReviewErrorResponse(c *fiber.Ctx, err error) error {
 	if err == ErrBadRequest {
 		status = fiber.StatusBadRequest
 		message = "Invalid request body"
-	} else if err == ErrNotFound {
+	} else if err == ErrNotFound || err == ErrReviewNotFound {
 		status = fiber.StatusNotFound
 		message = "Review not found"
 	} else {
@@ -75,6 +92,9 @@ func GetReviewErrorResponse(c *fiber.Ctx, err error) error {
 	} else if err == ErrNotFound {
 		status = fiber.StatusNotFound
 		message = "Review not found"
+	} else if err == ErrContentRestricted {
+		status = fiber.StatusForbidden
+		message = "Game is above your content settings"
 	} else {
 		status = 500
 		message = "Something went wrong"
@@ -135,6 +155,13 @@ func VoteReviewSuccessResp(c *fiber.Ctx) error {
 	})
 }
 
+func UnVoteReviewSuccessResp(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Review vote removed",
+		"data":    "",
+	})
+}
+
 func GetFlaggedReviewsErrorResponse(c *fiber.Ctx, err error) error {
 	status := 0
 	message := ""
@@ -219,3 +246,279 @@ func GetLocationSuccessResp(c *fiber.Ctx, locations []LocationWithCount) error {
 		"data":    locations,
 	})
 }
+
+func CommentErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrNotFound || err == ErrReviewNotFound {
+		status = fiber.StatusNotFound
+		message = "Review not found"
+	} else if err == ErrCommentNotFound {
+		status = fiber.StatusNotFound
+		message = "Comment not found"
+	} else if err == ErrCommentTooDeep {
+		status = fiber.StatusUnprocessableEntity
+		message = "Replies cannot be nested any deeper"
+	} else if err == ErrNotGameDeveloper {
+		status = fiber.StatusForbidden
+		message = "Only the developers of the game can respond officially"
+	} else if err == ErrContentRestricted {
+		status = fiber.StatusForbidden
+		message = "Game is above your content settings"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "Unauthorized"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+type AddCommentRes struct {
+	CommentId string `json:"commentId"`
+}
+
+func AddCommentSuccessResp(c *fiber.Ctx, commentId string) error {
+	return c.Status(fiber.StatusCreated).JSON(&fiber.Map{
+		"message": "Comment added",
+		"data":    AddCommentRes{CommentId: commentId},
+	})
+}
+
+func GetCommentsSuccessResp(c *fiber.Ctx, comments *PaginatedResponse[CommentResponse]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Comments found",
+		"data":    comments,
+	})
+}
+
+func GetFlaggedCommentsSuccessResp(c *fiber.Ctx, comments *PaginatedResponse[Comment]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Flagged comments response",
+		"data":    comments,
+	})
+}
+
+func CommentSuccessResp(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": message,
+		"data":    "",
+	})
+}
+
+func GameDeveloperErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrGameNotFound {
+		status = fiber.StatusNotFound
+		message = "Game not found"
+	} else if err == ErrNotFound {
+		status = fiber.StatusNotFound
+		message = "Game developer not found"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "Unauthorized"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GameDeveloperSuccessResp(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": message,
+		"data":    "",
+	})
+}
+
+func ReviewRevisionsErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request"
+	} else if err == ErrReviewRevisionNotFound {
+		status = fiber.StatusNotFound
+		message = "Review revision not found"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "Unauthorized"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetReviewRevisionsSuccessResp(c *fiber.Ctx, revisions *PaginatedResponse[ReviewRevision]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Review revisions found",
+		"data":    revisions,
+	})
+}
+
+func DiffReviewRevisionsSuccessResp(c *fiber.Ctx, diff *ReviewDiff) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Review revisions diff",
+		"data":    diff,
+	})
+}
+
+func MarkSpoilersSuccessResp(c *fiber.Ctx, containsSpoilers bool) error {
+	message := "Review unmarked as containing spoilers"
+	if containsSpoilers {
+		message = "Review marked as containing spoilers"
+	}
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": message,
+		"data":    "",
+	})
+}
+
+func ReportErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrNotFound || err == ErrReviewNotFound {
+		status = fiber.StatusNotFound
+		message = "Review not found"
+	} else if err == ErrCannotReportOwnReview {
+		status = fiber.StatusUnprocessableEntity
+		message = "You cannot report your own review"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "Unauthorized"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func ReportReviewSuccessResp(c *fiber.Ctx, result *ReportResult) error {
+	message := "Review reported"
+	if !result.Created {
+		message = "Review already reported"
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": message,
+		"data":    result,
+	})
+}
+
+func GetReportQueueSuccessResp(c *fiber.Ctx, reviews *PaginatedResponse[ReportedReview]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Reported reviews",
+		"data":    reviews,
+	})
+}
+
+func GetReviewReportsSuccessResp(c *fiber.Ctx, reports *PaginatedResponse[ReviewReport]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Review reports",
+		"data":    reports,
+	})
+}
+
+func ResolveReportsSuccessResp(c *fiber.Ctx, decision string) error {
+	message := "Reports dismissed"
+	if decision == ReportUpheld {
+		message = "Reports upheld"
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(&fiber.Map{
+		"message": message,
+		"data":    "",
+	})
+}
+
+func ModerationErrorResponse(c *fiber.Ctx, err error) error {
+	status := 0
+	message := ""
+
+	if err == ErrBadRequest {
+		status = fiber.StatusBadRequest
+		message = "Invalid request body"
+	} else if err == ErrNotFound || err == ErrReviewNotFound {
+		status = fiber.StatusNotFound
+		message = "Review not found"
+	} else if err == ErrCaseNotFound {
+		status = fiber.StatusNotFound
+		message = "Moderation case not found"
+	} else if err == ErrCaseTransition {
+		status = fiber.StatusUnprocessableEntity
+		message = "The case cannot move to this state"
+	} else if err == ErrCaseConflict {
+		status = fiber.StatusConflict
+		message = "The case was changed meanwhile, reload it"
+	} else if err == ErrReviewAlreadyExists {
+		status = fiber.StatusConflict
+		message = "The author reviewed the game again since, the review cannot be restored"
+	} else if err == ErrAppealNotAllowed {
+		status = fiber.StatusUnprocessableEntity
+		message = "Only a removal can be appealed, once"
+	} else if err == ErrUnauthorized {
+		status = fiber.StatusUnauthorized
+		message = "Unauthorized"
+	} else {
+		status = 500
+		message = "Something went wrong"
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"message": message,
+		"error":   err.Error(),
+	})
+}
+
+func GetModerationCasesSuccessResp(c *fiber.Ctx, cases *PaginatedResponse[ModerationCase]) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Moderation cases",
+		"data":    cases,
+	})
+}
+
+func ModerationCaseSuccessResp(c *fiber.Ctx, message string, moderationCase *ModerationCase) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": message,
+		"data":    moderationCase,
+	})
+}
+
+func GetModerationMetricsSuccessResp(c *fiber.Ctx, metrics *ModerationMetrics) error {
+	return c.Status(fiber.StatusOK).JSON(&fiber.Map{
+		"message": "Moderation metrics",
+		"data":    metrics,
+	})
+}
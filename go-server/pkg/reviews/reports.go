@@ -0,0 +1,239 @@
+package reviews
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"time"
+)
+
+// reasons players report a review for
+const (
+	ReportAbuse     = "abuse"
+	ReportSpam      = "spam"
+	ReportSpoilers  = "spoilers"
+	ReportOffensive = "offensive"
+	ReportOther     = "other"
+)
+
+// states of a report, it stays pending until a moderator resolves the reports
+// of the review
+const (
+	ReportPending   = "pending"
+	ReportUpheld    = "upheld"
+	ReportDismissed = "dismissed"
+)
+
+// reportHideThreshold is the weight of pending reports hiding a review until a
+// moderator resolves them, three reports of reporters without a record.
+const reportHideThreshold = 3.0
+
+// the weight of a report is kept within these bounds, whatever the record of
+// its reporter
+const (
+	minReportWeight = 0.25
+	maxReportWeight = 3.0
+)
+
+// ReviewReport is a report of a review by a player. A player reports a review
+// once, reporting it again while pending changes the reason. Weight is the
+// reputation of the reporter when they reported.
+type ReviewReport struct {
+	Id         primitive.ObjectID `json:"id" bson:"_id"`
+	ReviewId   string             `json:"reviewId" bson:"reviewId"`
+	ReporterId string             `json:"reporterId" bson:"reporterId"`
+	Reason     string             `json:"reason" bson:"reason"`
+	Details    string             `json:"details" bson:"details"`
+	Weight     float64            `json:"weight" bson:"weight"`
+	Status     string             `json:"status" bson:"status"`
+	CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+	ResolvedAt *time.Time         `json:"resolvedAt,omitempty" bson:"resolvedAt,omitempty"`
+	ResolvedBy string             `json:"resolvedBy,omitempty" bson:"resolvedBy,omitempty"`
+}
+
+type ReportReview struct {
+	Reason  string `json:"reason" validate:"required,oneof=abuse spam spoilers offensive other"`
+	Details string `json:"details" validate:"required_if=Reason other,max=1000"`
+}
+
+type ResolveReports struct {
+	// upheld keeps the review hidden, dismissed shows it again
+	Decision string `json:"decision" validate:"required,oneof=upheld dismissed"`
+}
+
+// ReportedReview is a review in the report queue with its pending reports,
+// counted by reason.
+type ReportedReview struct {
+	Review      `bson:",inline"`
+	ReportCount int            `json:"reportCount" bson:"reportCount"`
+	Reasons     map[string]int `json:"reasons" bson:"reasons"`
+}
+
+// ReportResult tells the reporter whether their report is new and whether the
+// review is now hidden.
+type ReportResult struct {
+	Created bool `json:"created"`
+	Hidden  bool `json:"hidden"`
+}
+
+// reporterWeight is the weight of the reports of a reporter, from how many of
+// their reports moderators upheld and dismissed. A reporter without a record
+// weighs 1, each upheld report adds to it and each dismissed one takes from it.
+func reporterWeight(upheld int, dismissed int) float64 {
+	weight := float64(1+upheld) / float64(1+dismissed)
+
+	if weight < minReportWeight {
+		return minReportWeight
+	}
+
+	if weight > maxReportWeight {
+		return maxReportWeight
+	}
+
+	return weight
+}
+
+// reportReview reports the review for the user, weighted by their reputation.
+// The review is hidden once the weight of its pending reports reaches the
+// threshold, until a moderator resolves them.
+func (s *Service) reportReview(ctx context.Context, reviewId string, req *ReportReview) (*ReportResult, error) {
+	userId := ctx.Value("userId").(string)
+
+	review, _, err := s.repository.GetReview(ctx, reviewId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if review.UserId == userId {
+		return nil, ErrCannotReportOwnReview
+	}
+
+	upheld, dismissed, err := s.repository.getReporterRecord(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReviewReport{
+		Id:         primitive.NewObjectID(),
+		ReviewId:   reviewId,
+		ReporterId: userId,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		Weight:     reporterWeight(upheld, dismissed),
+		Status:     ReportPending,
+		CreatedAt:  time.Now(),
+	}
+
+	created, err := s.repository.addReport(ctx, report)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReportResult{Created: created, Hidden: review.IsHidden}
+
+	if !created {
+		// the reporter already counted
+		return result, nil
+	}
+
+	hidden, err := s.repository.addReportWeight(ctx, review.Id, report.Weight, reportHideThreshold)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if hidden {
+		result.Hidden = true
+
+		// hiding it flagged it already
+		review.IsFlagged = true
+
+		if err = s.holdForModeration(ctx, review, CaseSourceReports, ""); err != nil {
+			log.Println("could not open a case for review " + reviewId + ": " + err.Error())
+		}
+	}
+
+	return result, nil
+}
+
+// getReportQueue lists the reviews with pending reports, the most heavily
+// reported first, for moderators.
+func (s *Service) getReportQueue(ctx context.Context, limit int, offset int) (*PaginatedResponse[ReportedReview], error) {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	if limit < 1 {
+		limit = 10
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repository.getReportQueue(ctx, limit, offset)
+}
+
+// getReviewReports lists the reports of a review, newest first, for moderators.
+func (s *Service) getReviewReports(ctx context.Context, reviewId string, limit int, offset int) (*PaginatedResponse[ReviewReport], error) {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	if limit < 1 {
+		limit = 10
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repository.getReviewReports(ctx, reviewId, limit, offset)
+}
+
+// resolveReports upholds or dismisses the pending reports of a review, for
+// moderators. Upheld reports keep the review hidden, dismissed ones show it
+// again, and either counts towards the reputation of the reporters. The active
+// case of the review is closed the same way, only admins close escalated ones.
+func (s *Service) resolveReports(ctx context.Context, reviewId string, decision string) error {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return ErrUnauthorized
+	}
+
+	review, _, err := s.repository.GetReview(ctx, reviewId)
+
+	if err != nil {
+		return err
+	}
+
+	moderationCase, err := s.repository.getLatestCase(ctx, reviewId)
+
+	if err != nil && err != ErrCaseNotFound {
+		return err
+	}
+
+	if moderationCase != nil && moderationCase.Active && moderationCase.Status == CaseEscalated && role != "admin" {
+		return ErrUnauthorized
+	}
+
+	moderatorId := ctx.Value("userId").(string)
+
+	return s.repository.resolveReports(ctx, review.Id, decision, moderatorId)
+}
@@ -3,6 +3,7 @@ package reviews
 import (
 	"context"
 	"github.com/go-playground/validator/v10"
+	"go-server/pkg/games"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -16,20 +17,144 @@ import (
 type RepositoryImpl struct {
 	mongoDbClient *mongo.Client
 	validate      *validator.Validate
+	ratingPrior   games.RatingPrior
 }
 
 const (
-	reviewsCollection = "reviews"
+	reviewsCollection         = "reviews"
+	reviewRevisionsCollection = "reviewRevisions"
+	votesCollection           = "votes"
+	reviewReportsCollection   = "reviewReports"
+	moderationCasesCollection = "moderationCases"
 )
 
-func NewRepository(mongoClient *mongo.Client) *RepositoryImpl {
+func NewRepository(mongoClient *mongo.Client, ratingPrior games.RatingPrior) *RepositoryImpl {
 	return &RepositoryImpl{
 		mongoDbClient: mongoClient,
+		ratingPrior:   ratingPrior,
 	}
 }
 
+// ensureIndexes lets a user have one review per game, deleted reviews aside, and
+// one vote and one report per review and a review one active moderation case,
+// and indexes the most helpful reviews, the report and moderation queues, the
+// revisions and the comment threads.
+func (r *RepositoryImpl) ensureIndexes(ctx context.Context) error {
+	db := r.mongoDbClient.Database("test")
+
+	_, err := db.Collection(reviewsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{"gameId", 1}, {"userId", 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.D{{"isDeleted", false}}),
+		},
+		// the most helpful reviews of a game
+		{Keys: bson.D{{"gameId", 1}, {"helpfulness", -1}, {"upVotes", -1}, {"createdAt", -1}}},
+		// the report queue
+		{Keys: bson.D{{"reportWeight", -1}}, Options: options.Index().SetPartialFilterExpression(bson.D{{"reportWeight", bson.D{{"$gt", 0}}}})},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(reviewRevisionsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"reviewId", 1}, {"revision", 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(commentsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"reviewId", 1}, {"parentId", 1}, {"createdAt", 1}}},
+		{Keys: bson.D{{"isFlagged", 1}, {"createdAt", -1}}},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(reviewReportsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"reviewId", 1}, {"reporterId", 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{"reporterId", 1}, {"status", 1}}},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(moderationCasesCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{"reviewId", 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.D{{"active", true}}),
+		},
+		{Keys: bson.D{{"reviewId", 1}, {"openedAt", -1}}},
+		{Keys: bson.D{{"status", 1}, {"dueAt", 1}}},
+		{Keys: bson.D{{"assigneeId", 1}, {"status", 1}}},
+		{Keys: bson.D{{"decidedAt", -1}}},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(votesCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"userId", 1}, {"reviewId", 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(commentVotesCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"userId", 1}, {"commentId", 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	_, err = db.Collection(gameDevelopersCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{"userId", 1}, {"gameId", 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// AddReview saves the review and adds its rating to the stats of the game in one transaction.
 func (r *RepositoryImpl) AddReview(ctx context.Context, review *Review) error {
-	_, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).InsertOne(ctx, review)
+	session, err := r.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		_, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).InsertOne(sessCtx, review)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, r.updateReviewStats(sessCtx, review.GameId, reviewRatingDelta(nil, review))
+	})
+
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrReviewAlreadyExists
+	}
 
 	if err != nil {
 		log.Println(err)
@@ -39,12 +164,72 @@ func (r *RepositoryImpl) AddReview(ctx context.Context, review *Review) error {
 	return nil
 }
 
+// UpdateReview saves the review and moves the stats of its game by the difference
+// with the stored review in one transaction. The stored review is read inside the
+// transaction, so concurrent updates of the same review are retried rather than
+// counted twice. When the content changed, the edit is saved as a new revision,
+// the first edit also saving the review as first written.
 func (r *RepositoryImpl) UpdateReview(ctx context.Context, review *Review) error {
+	session, err := r.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
 
-	filter := bson.D{{"_id", review.Id}}
-	update := bson.D{{"$set", review}}
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		var stored Review
 
-	_, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).UpdateOne(ctx, filter, update)
+		filter := bson.D{{"_id", review.Id}}
+
+		err := r.mongoDbClient.Database("test").Collection(reviewsCollection).FindOne(sessCtx, filter).Decode(&stored)
+		if err != nil {
+			return nil, err
+		}
+
+		review.EditCount = stored.EditCount
+
+		// the votes are counted by Vote alone
+		review.Votes = stored.Votes
+		review.UpVotes = stored.UpVotes
+		review.DownVotes = stored.DownVotes
+		review.Helpfulness = stored.Helpfulness
+
+		// and the reports by the report methods
+		review.IsHidden = stored.IsHidden
+		review.ReportWeight = stored.ReportWeight
+
+		if changes := diffReviewContent(stored.content(), review.content()); len(changes) > 0 {
+			review.EditCount++
+
+			if err = r.addReviewRevisions(sessCtx, &stored, review, changes); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = r.mongoDbClient.Database("test").Collection(reviewsCollection).UpdateOne(sessCtx, filter, bson.D{{"$set", review}})
+		if err != nil {
+			return nil, err
+		}
+
+		delta := reviewRatingDelta(&stored, review)
+
+		if delta.isZero() {
+			return nil, nil
+		}
+
+		// the stats belong to the game the review was written for, whatever the request said
+		return nil, r.updateReviewStats(sessCtx, stored.GameId, delta)
+	})
+
+	if err == mongo.ErrNoDocuments {
+		return ErrReviewNotFound
+	}
+
+	if mongo.IsDuplicateKeyError(err) {
+		// restoring a review of a game its author reviewed again since
+		return ErrReviewAlreadyExists
+	}
 
 	if err != nil {
 		log.Println(err)
@@ -70,7 +255,7 @@ func (r *RepositoryImpl) GetReview(ctx context.Context, id string) (*Review, *Us
 
 	rawId, _ := primitive.ObjectIDFromHex(id)
 
-	filter := bson.D{{"_id", rawId}, {"isDeleted", false}}
+	filter := bson.D{{"_id", rawId}, {"isDeleted", false}, {"gameDeleted", bson.D{{"$ne", true}}}}
 
 	err := r.mongoDbClient.Database("test").Collection(reviewsCollection).FindOne(ctx, filter).Decode(&review)
 
@@ -102,7 +287,12 @@ func (r *RepositoryImpl) GetReview(ctx context.Context, id string) (*Review, *Us
 }
 
 func (r *RepositoryImpl) GameExists(ctx context.Context, id string) (bool, error) {
-	res := r.mongoDbClient.Database("test").Collection("games").FindOne(ctx, bson.D{{"_id", id}, {"isDeleted", false}})
+	rawId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, nil
+	}
+
+	res := r.mongoDbClient.Database("test").Collection("games").FindOne(ctx, bson.D{{"_id", rawId}, {"isDeleted", false}})
 
 	if res.Err() != nil {
 		return false, nil
@@ -122,7 +312,7 @@ func (r *RepositoryImpl) GetVote(ctx context.Context, userId string, reviewId st
 	}
 
 	filter := bson.D{{"userId", userId}, {"reviewId", reviewId}}
-	res := r.mongoDbClient.Database("test").Collection("votes").FindOne(ctx, filter)
+	res := r.mongoDbClient.Database("test").Collection(votesCollection).FindOne(ctx, filter)
 
 	if res.Err() != nil {
 		return defaultVote, nil
@@ -139,112 +329,311 @@ func (r *RepositoryImpl) GetVote(ctx context.Context, userId string, reviewId st
 
 }
 
+// Vote saves the vote of the user on the review and counts it in one transaction,
+// so the counts move once however often the user clicks. Voting the same way
+// again changes nothing, voting the other way moves the vote between the counts.
 func (r *RepositoryImpl) Vote(ctx context.Context, req VoteRequest, shouldUpVote bool) error {
-	// increment or decrement the vote count
-	filter := bson.D{{"_id", req.ReviewId}}
+	rawId, err := primitive.ObjectIDFromHex(req.ReviewId)
+	if err != nil {
+		return ErrNotFound
+	}
 
-	voteFilter := bson.D{{"userId", req.UserId}, {"reviewId", req.ReviewId}}
+	filter := bson.D{{"_id", rawId}, {"isDeleted", false}}
 
-	opts := options.Update().SetUpsert(true)
+	if err = r.mongoDbClient.Database("test").Collection(reviewsCollection).FindOne(ctx, filter).Err(); err != nil {
+		return ErrNotFound
+	}
 
-	// check if the user has already voted
-	voteRes := r.mongoDbClient.Database("test").Collection("votes").FindOne(ctx, voteFilter)
-
-	if voteRes.Err() != nil {
-		// user has not voted
-		// add a new vote
-		_, err := r.mongoDbClient.Database("test").Collection("votes").InsertOne(ctx, Vote{
-			UserId:     req.UserId,
-			ReviewId:   req.ReviewId,
-			IsUpVote:   shouldUpVote,
-			IsDownVote: !shouldUpVote,
-		})
+	return r.inVoteTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var previous Vote
 
-		if err != nil {
-			log.Println(err)
-			return UnknownError
+		voteFilter := bson.D{{"userId", req.UserId}, {"reviewId", req.ReviewId}}
+		update := bson.D{{"$set", bson.D{{"isUpVote", shouldUpVote}, {"isDownVote", !shouldUpVote}, {"votedAt", time.Now()}}}}
+		opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+		err := r.mongoDbClient.Database("test").Collection(votesCollection).FindOneAndUpdate(sessCtx, voteFilter, update, opts).Decode(&previous)
+
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
 		}
 
-		// increment the vote count
+		up, down := 0, 0
+
+		if previous.IsUpVote {
+			up--
+		}
 
-		inc := 0
+		if previous.IsDownVote {
+			down--
+		}
 
 		if shouldUpVote {
-			inc = 1
+			up++
 		} else {
-			inc = -1
+			down++
+		}
+
+		if up == 0 && down == 0 {
+			// user is trying to upvote an already upvoted review
+			// or
+			// user is trying to downvote an already downvoted review
+			return nil
 		}
 
-		update := bson.D{{"$inc", bson.D{{"votes", inc}}}}
+		return r.countVotes(sessCtx, filter, up, down)
+	})
+}
+
+// unVote removes the vote of the user on the review, if any, and takes it off
+// the counts in one transaction.
+func (r *RepositoryImpl) unVote(ctx context.Context, userId string, reviewId string) error {
+	rawId, err := primitive.ObjectIDFromHex(reviewId)
+	if err != nil {
+		return ErrNotFound
+	}
+
+	filter := bson.D{{"_id", rawId}, {"isDeleted", false}}
+
+	if err = r.mongoDbClient.Database("test").Collection(reviewsCollection).FindOne(ctx, filter).Err(); err != nil {
+		return ErrNotFound
+	}
 
-		_, err = r.mongoDbClient.Database("test").Collection(reviewsCollection).UpdateOne(ctx, filter, update, opts)
+	return r.inVoteTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		var previous Vote
+
+		voteFilter := bson.D{{"userId", userId}, {"reviewId", reviewId}}
+
+		err := r.mongoDbClient.Database("test").Collection(votesCollection).FindOneAndDelete(sessCtx, voteFilter).Decode(&previous)
+
+		if err == mongo.ErrNoDocuments {
+			// nothing to take back
+			return nil
+		}
 
 		if err != nil {
-			log.Println(err)
-			return UnknownError
+			return err
 		}
 
-		return nil
+		up, down := 0, 0
 
-	} else {
-		// user has already voted
-		// update the vote
-		var vote Vote
-		err := voteRes.Decode(&vote)
-		if err != nil {
-			log.Println(err)
-			return UnknownError
+		if previous.IsUpVote {
+			up--
 		}
 
-		if vote.IsUpVote == shouldUpVote {
-			// user is trying to upvote an already upvoted review
-			// or
-			// user is trying to downvote an already downvoted review
+		if previous.IsDownVote {
+			down--
+		}
+
+		if up == 0 && down == 0 {
 			return nil
 		}
 
-		// update the vote
-		update := bson.D{{"$set", bson.D{{"isUpVote", shouldUpVote}, {"isDownVote", !shouldUpVote}}}}
-		_, err = r.mongoDbClient.Database("test").Collection("votes").UpdateOne(ctx, voteFilter, update)
-		if err != nil {
+		return r.countVotes(sessCtx, filter, up, down)
+	})
+}
+
+// inVoteTransaction runs the vote change in a transaction. Concurrent votes of
+// the same user conflict on the unique vote index, the transaction is then run
+// again once the other vote is saved, seeing it as the previous vote.
+func (r *RepositoryImpl) inVoteTransaction(ctx context.Context, change func(sessCtx mongo.SessionContext) error) error {
+	session, err := r.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	for attempt := 0; ; attempt++ {
+		_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			return nil, change(sessCtx)
+		})
+
+		if err == nil {
+			return nil
+		}
+
+		if !mongo.IsDuplicateKeyError(err) || attempt >= 2 {
 			log.Println(err)
 			return UnknownError
 		}
+	}
+}
 
-		// update the vote count
-		inc := 0
+// countVotes adds to the upvotes and downvotes of the review and scores its
+// helpfulness again, in one update.
+func (r *RepositoryImpl) countVotes(ctx context.Context, filter bson.D, up int, down int) error {
+	update := append(bson.A{
+		bson.D{{"$set", bson.D{
+			{"votes", bson.D{{"$add", bson.A{bson.D{{"$ifNull", bson.A{"$votes", 0}}}, up - down}}}},
+			{"upVotes", bson.D{{"$add", bson.A{bson.D{{"$ifNull", bson.A{"$upVotes", 0}}}, up}}}},
+			{"downVotes", bson.D{{"$add", bson.A{bson.D{{"$ifNull", bson.A{"$downVotes", 0}}}, down}}}},
+		}}},
+	}, helpfulnessUpdate()...)
 
-		if shouldUpVote {
-			inc = 2
-		} else {
-			inc = -2
+	_, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).UpdateOne(ctx, filter, update)
+
+	return err
+}
+
+// getUncountedReviewVotes counts the upvotes and downvotes of the reviews whose
+// votes were not counted apart yet, by review id.
+func (r *RepositoryImpl) getUncountedReviewVotes(ctx context.Context) (map[primitive.ObjectID]VoteCounts, error) {
+	var uncounted []Review
+
+	filter := bson.D{{"upVotes", bson.D{{"$exists", false}}}}
+	opts := options.Find().SetProjection(bson.D{{"_id", 1}})
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &uncounted); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	reviewIds := make([]primitive.ObjectID, 0, len(uncounted))
+
+	for _, review := range uncounted {
+		reviewIds = append(reviewIds, review.Id)
+	}
+
+	return r.countReviewVotes(ctx, reviewIds)
+}
+
+// countReviewVotes counts the upvotes and downvotes of the reviews from their
+// votes, by review id.
+func (r *RepositoryImpl) countReviewVotes(ctx context.Context, reviewIds []primitive.ObjectID) (map[primitive.ObjectID]VoteCounts, error) {
+	counts := make(map[primitive.ObjectID]VoteCounts, len(reviewIds))
+	hexIds := make([]string, 0, len(reviewIds))
+
+	for _, reviewId := range reviewIds {
+		counts[reviewId] = VoteCounts{}
+		hexIds = append(hexIds, reviewId.Hex())
+	}
+
+	if len(hexIds) == 0 {
+		return counts, nil
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"reviewId", bson.D{{"$in", hexIds}}}}}},
+		{{"$group", bson.D{
+			{"_id", "$reviewId"},
+			{"up", bson.D{{"$sum", bson.D{{"$cond", bson.A{"$isUpVote", 1, 0}}}}}},
+			{"down", bson.D{{"$sum", bson.D{{"$cond", bson.A{"$isDownVote", 1, 0}}}}}},
+		}}},
+	}
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(votesCollection).Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	var totals []struct {
+		ReviewId   string `bson:"_id"`
+		VoteCounts `bson:",inline"`
+	}
+
+	if err = cursor.All(ctx, &totals); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	for _, total := range totals {
+		if rawId, err := primitive.ObjectIDFromHex(total.ReviewId); err == nil {
+			counts[rawId] = total.VoteCounts
 		}
+	}
+
+	return counts, nil
+}
+
+// dedupeVotes deletes the votes a user cast more than once on a review, keeping
+// the latest, and returns the ids of the reviews whose votes were deleted.
+func (r *RepositoryImpl) dedupeVotes(ctx context.Context) ([]primitive.ObjectID, error) {
+	var groups []struct {
+		ReviewId string               `bson:"reviewId"`
+		VoteIds  []primitive.ObjectID `bson:"voteIds"`
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$sort", bson.D{{"votedAt", -1}}}},
+		{{"$group", bson.D{
+			{"_id", bson.D{{"userId", "$userId"}, {"reviewId", "$reviewId"}}},
+			{"reviewId", bson.D{{"$first", "$reviewId"}}},
+			{"voteIds", bson.D{{"$push", "$_id"}}},
+			{"count", bson.D{{"$sum", 1}}},
+		}}},
+		{{"$match", bson.D{{"count", bson.D{{"$gt", 1}}}}}},
+	}
+
+	collection := r.mongoDbClient.Database("test").Collection(votesCollection)
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &groups); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
 
-		update = bson.D{{"$inc", bson.D{{"votes", inc}}}}
+	reviewIds := make([]primitive.ObjectID, 0, len(groups))
 
-		_, err = r.mongoDbClient.Database("test").Collection(reviewsCollection).UpdateOne(ctx, filter, update, opts)
+	for _, group := range groups {
+		// the latest vote comes first
+		_, err = collection.DeleteMany(ctx, bson.D{{"_id", bson.D{{"$in", group.VoteIds[1:]}}}})
 
 		if err != nil {
 			log.Println(err)
-			return UnknownError
+			return nil, UnknownError
 		}
 
-		return nil
+		if rawId, err := primitive.ObjectIDFromHex(group.ReviewId); err == nil {
+			reviewIds = append(reviewIds, rawId)
+		}
+	}
+
+	return reviewIds, nil
+}
+
+// setReviewVoteCounts sets the upvotes and downvotes of the review, the votes and
+// the helpfulness following from them.
+func (r *RepositoryImpl) setReviewVoteCounts(ctx context.Context, reviewId primitive.ObjectID, up int, down int) error {
+	update := bson.D{{"$set", bson.D{
+		{"votes", up - down},
+		{"upVotes", up},
+		{"downVotes", down},
+		{"helpfulness", wilsonLowerBound(up, down)},
+	}}}
+
+	_, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).UpdateOne(ctx, bson.D{{"_id", reviewId}}, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
 	}
+
+	return nil
 }
 
 func (r *RepositoryImpl) GetReviewsForGame(ctx context.Context, req *GetReviewsForGame) (*PaginatedResponse[ReviewResponse], error) {
 	var reviews []Review
 	var userRws []UserRw
 
-	gameRevFilter := bson.D{{"gameId", req.GameId}, {"isDeleted", false}}
-
-	sortVal := -1
-	if req.SortBy.Asc {
-		sortVal = 1
-	}
+	gameRevFilter := bson.D{{"gameId", req.GameId}, {"isDeleted", false}, {"isHidden", bson.D{{"$ne", true}}}, {"gameDeleted", bson.D{{"$ne", true}}}}
+	gameRevFilter = append(gameRevFilter, req.subScoreFilter()...)
 
-	opts := options.Find().SetLimit(int64(req.Limit)).SetSkip(int64(req.Offset)).SetSort(bson.D{{req.SortBy.Key, sortVal}})
+	opts := options.Find().SetLimit(int64(req.Limit)).SetSkip(int64(req.Offset)).SetSort(req.sort())
 
 	cursor, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).Find(ctx, gameRevFilter, opts)
 
@@ -355,10 +744,7 @@ func (r *RepositoryImpl) GetReviewsForGame(ctx context.Context, req *GetReviewsF
 			continue
 		}
 
-		reviewResponses = append(reviewResponses, ReviewResponse{
-			Review: review,
-			User:   user,
-		})
+		reviewResponses = append(reviewResponses, newReviewResponse(review, user))
 	}
 
 	if len(reviewResponses) == 0 {
@@ -416,14 +802,10 @@ func (r *RepositoryImpl) GetReviewsForUser(ctx context.Context, req *GetReviewsF
 	var reviews []Review
 	var rawUser UserRw
 
-	gameRevFilter := bson.D{{"userId", req.UserId}, {"isDeleted", false}}
-
-	sortVal := -1
-	if req.SortBy.Asc {
-		sortVal = 1
-	}
+	gameRevFilter := bson.D{{"userId", req.UserId}, {"isDeleted", false}, {"isHidden", bson.D{{"$ne", true}}}, {"gameDeleted", bson.D{{"$ne", true}}}}
+	gameRevFilter = append(gameRevFilter, req.subScoreFilter()...)
 
-	opts := options.Find().SetLimit(int64(req.Limit)).SetSkip(int64(req.Offset)).SetSort(bson.D{{req.SortBy.Key, sortVal}})
+	opts := options.Find().SetLimit(int64(req.Limit)).SetSkip(int64(req.Offset)).SetSort(req.sort())
 
 	cursor, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).Find(ctx, gameRevFilter, opts)
 
@@ -472,10 +854,7 @@ func (r *RepositoryImpl) GetReviewsForUser(ctx context.Context, req *GetReviewsF
 	}
 
 	for _, review := range reviews {
-		reviewResponses = append(reviewResponses, ReviewResponse{
-			Review: review,
-			User:   user,
-		})
+		reviewResponses = append(reviewResponses, newReviewResponse(review, user))
 	}
 
 	// get votes for each reviews with go routines
@@ -521,7 +900,7 @@ func (r *RepositoryImpl) GetReviewsForUser(ctx context.Context, req *GetReviewsF
 func (r *RepositoryImpl) GetFlaggedReviews(ctx context.Context, gameId string, limit int, offset int) (*PaginatedResponse[Review], error) {
 	var reviews []Review
 
-	gameRevFilter := bson.D{{"isDeleted", false}, {"isFlagged", true}}
+	gameRevFilter := bson.D{{"isDeleted", false}, {"isFlagged", true}, {"gameDeleted", bson.D{{"$ne", true}}}}
 
 	if gameId != "" {
 		gameRevFilter = append(gameRevFilter, bson.E{Key: "gameId", Value: gameId})
@@ -580,21 +959,52 @@ func (r *RepositoryImpl) GetFlaggedReviews(ctx context.Context, gameId string, l
 	return response, nil
 }
 
-func (r *RepositoryImpl) UpdateReviewStats(ctx context.Context, gameId string, rating int, ratingCount int) error {
-	//find game
-
-	id := primitive.ObjectID{}
-	id, _ = primitive.ObjectIDFromHex(gameId)
+// updateReviewStats applies the delta to the totals of the game, then recomputes
+// its average and weighted rating from the new totals.
+func (r *RepositoryImpl) updateReviewStats(ctx context.Context, gameId string, delta RatingDelta) error {
+	id, err := primitive.ObjectIDFromHex(gameId)
+	if err != nil {
+		return ErrGameNotFound
+	}
 
 	filter := bson.D{{"_id", id}}
 
-	err := r.mongoDbClient.Database("test").Collection("games").FindOneAndUpdate(ctx, filter, bson.D{{"$inc", bson.D{{"rating.count", ratingCount}, {"rating.sum", rating}}}})
+	inc := bson.D{{"rating.count", delta.Count}, {"rating.sum", delta.Sum}}
+	for bucket, count := range delta.Histogram {
+		if count != 0 {
+			inc = append(inc, bson.E{Key: "rating.histogram." + bucket, Value: count})
+		}
+	}
+
+	var subScores []string
+	for dimension, subScore := range delta.SubScores {
+		if subScore.isZero() {
+			continue
+		}
+
+		path := "rating.subScores." + dimension
+		subScores = append(subScores, dimension)
+
+		inc = append(inc, bson.E{Key: path + ".count", Value: subScore.Count}, bson.E{Key: path + ".sum", Value: subScore.Sum})
+		for bucket, count := range subScore.Histogram {
+			if count != 0 {
+				inc = append(inc, bson.E{Key: path + ".histogram." + bucket, Value: count})
+			}
+		}
+	}
+
+	res, err := r.mongoDbClient.Database("test").Collection("games").UpdateOne(ctx, filter, bson.D{{"$inc", inc}})
 	if err != nil {
-		log.Println(err)
-		return UnknownError
+		return err
 	}
 
-	return nil
+	if res.MatchedCount == 0 {
+		return ErrGameNotFound
+	}
+
+	_, err = r.mongoDbClient.Database("test").Collection("games").UpdateOne(ctx, filter, games.RatingAveragesUpdate(r.ratingPrior, subScores...))
+
+	return err
 }
 
 func (r *RepositoryImpl) getReviewersForTimeAgo(ctx context.Context, ago time.Time) (*[]Review, error) {
@@ -602,7 +1012,7 @@ func (r *RepositoryImpl) getReviewersForTimeAgo(ctx context.Context, ago time.Ti
 	var reviews []Review
 
 	// get all reviews in the last 24 hours
-	filter := bson.D{{"createdAt", bson.D{{"$gte", ago}}}, {"isDeleted", false}}
+	filter := bson.D{{"createdAt", bson.D{{"$gte", ago}}}, {"isDeleted", false}, {"gameDeleted", bson.D{{"$ne", true}}}}
 
 	cursor, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).Find(ctx, filter)
 
@@ -619,3 +1029,1190 @@ func (r *RepositoryImpl) getReviewersForTimeAgo(ctx context.Context, ago time.Ti
 
 	return &reviews, nil
 }
+
+const (
+	commentsCollection       = "comments"
+	commentVotesCollection   = "commentVotes"
+	gameDevelopersCollection = "gameDevelopers"
+)
+
+// addComment saves the comment and counts it in the replies of its parent.
+func (r *RepositoryImpl) addComment(ctx context.Context, comment *Comment) error {
+	_, err := r.mongoDbClient.Database("test").Collection(commentsCollection).InsertOne(ctx, comment)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return r.countReply(ctx, comment.ParentId, 1)
+}
+
+func (r *RepositoryImpl) countReply(ctx context.Context, parentId string, inc int) error {
+	if parentId == "" {
+		return nil
+	}
+
+	id, err := primitive.ObjectIDFromHex(parentId)
+	if err != nil {
+		return ErrCommentNotFound
+	}
+
+	update := bson.D{{"$inc", bson.D{{"replies", inc}}}}
+
+	_, err = r.mongoDbClient.Database("test").Collection(commentsCollection).UpdateOne(ctx, bson.D{{"_id", id}}, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (r *RepositoryImpl) getComment(ctx context.Context, id string) (*Comment, error) {
+	var comment Comment
+
+	rawId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrCommentNotFound
+	}
+
+	err = r.mongoDbClient.Database("test").Collection(commentsCollection).FindOne(ctx, bson.D{{"_id", rawId}}).Decode(&comment)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrCommentNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &comment, nil
+}
+
+// getComments returns a page of the direct replies to the parent, the review
+// itself when there is none. Deleted comments are kept while they have replies.
+func (r *RepositoryImpl) getComments(ctx context.Context, req *GetComments) (*PaginatedResponse[CommentResponse], error) {
+	var comments []Comment
+
+	filter := bson.D{
+		{"reviewId", req.ReviewId},
+		{"parentId", req.ParentId},
+		{"isFlagged", false},
+		{"$or", bson.A{
+			bson.D{{"isDeleted", false}},
+			bson.D{{"replies", bson.D{{"$gt", 0}}}},
+		}},
+	}
+
+	var sort bson.D
+
+	if req.ParentId == "" {
+		sort = append(sort, bson.E{Key: "isOfficial", Value: -1})
+	}
+
+	switch req.Sort {
+	case CommentSortNewest:
+		sort = append(sort, bson.E{Key: "createdAt", Value: -1})
+	case CommentSortTop:
+		sort = append(sort, bson.E{Key: "votes", Value: -1}, bson.E{Key: "createdAt", Value: 1})
+	default:
+		sort = append(sort, bson.E{Key: "createdAt", Value: 1})
+	}
+
+	opts := options.Find().SetLimit(int64(req.Limit)).SetSkip(int64(req.Offset)).SetSort(sort)
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(commentsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &comments)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	count, err := r.mongoDbClient.Database("test").Collection(commentsCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	userIds := make([]string, 0, len(comments))
+	commentIds := make([]string, 0, len(comments))
+
+	for _, comment := range comments {
+		userIds = append(userIds, comment.UserId)
+		commentIds = append(commentIds, comment.Id.Hex())
+	}
+
+	users, err := r.getUsers(ctx, userIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := r.getCommentVotes(ctx, req.UserId, commentIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]CommentResponse, 0, len(comments))
+
+	for _, comment := range comments {
+		vote, ok := votes[comment.Id.Hex()]
+
+		if !ok {
+			vote = CommentVote{UserId: req.UserId, CommentId: comment.Id.Hex()}
+		}
+
+		responses = append(responses, CommentResponse{
+			Comment: comment,
+			User:    users[comment.UserId],
+			Vote:    vote,
+		})
+	}
+
+	return &PaginatedResponse[CommentResponse]{
+		Data:         responses,
+		TotalPages:   int(math.Ceil(float64(count) / float64(req.Limit))),
+		CurrentPage:  int(math.Ceil(float64(req.Offset) / float64(req.Limit))),
+		TotalItems:   int(count),
+		HasMore:      int(count) > (req.Offset + req.Limit),
+		ItemsPerPage: req.Limit,
+	}, nil
+}
+
+// getUsers returns the users by id, leaving out the ones not found.
+func (r *RepositoryImpl) getUsers(ctx context.Context, userIds []string) (map[string]User, error) {
+	users := make(map[string]User, len(userIds))
+
+	ids := make([]primitive.ObjectID, 0, len(userIds))
+
+	for _, userId := range userIds {
+		id, err := primitive.ObjectIDFromHex(userId)
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	var userRws []UserRw
+
+	returnedFields := bson.D{{"_id", 1}, {"displayPic", 1}, {"username", 1}, {"firstName", 1}, {"lastName", 1}, {"location", 1}}
+	opts := options.Find().SetProjection(returnedFields)
+
+	cursor, err := r.mongoDbClient.Database("test").Collection("users").Find(ctx, bson.D{{"_id", bson.D{{"$in", ids}}}}, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &userRws)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	for _, userRw := range userRws {
+		users[userRw.Id.Hex()] = User{
+			UserId:   userRw.Id.Hex(),
+			Avatar:   userRw.Avatar,
+			Username: userRw.Username,
+			FullName: userRw.firstName + " " + userRw.lastName,
+			Location: userRw.Location,
+		}
+	}
+
+	return users, nil
+}
+
+// getCommentVotes returns the votes of the user on the comments, by comment id.
+func (r *RepositoryImpl) getCommentVotes(ctx context.Context, userId string, commentIds []string) (map[string]CommentVote, error) {
+	votes := make(map[string]CommentVote, len(commentIds))
+
+	if len(commentIds) == 0 {
+		return votes, nil
+	}
+
+	var rawVotes []CommentVote
+
+	filter := bson.D{{"userId", userId}, {"commentId", bson.D{{"$in", commentIds}}}}
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(commentVotesCollection).Find(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &rawVotes)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	for _, vote := range rawVotes {
+		votes[vote.CommentId] = vote
+	}
+
+	return votes, nil
+}
+
+// updateComment saves the editable fields of the comment, leaving its counters alone.
+func (r *RepositoryImpl) updateComment(ctx context.Context, comment *Comment) error {
+	update := bson.D{{"$set", bson.D{
+		{"body", comment.Body},
+		{"isFlagged", comment.IsFlagged},
+		{"lastUpdatedAt", comment.LastUpdatedAt},
+	}}}
+
+	res, err := r.mongoDbClient.Database("test").Collection(commentsCollection).UpdateOne(ctx, bson.D{{"_id", comment.Id}}, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// deleteComment soft deletes the comment and takes it out of the replies of its parent.
+func (r *RepositoryImpl) deleteComment(ctx context.Context, comment *Comment) error {
+	filter := bson.D{{"_id", comment.Id}, {"isDeleted", false}}
+	update := bson.D{{"$set", bson.D{{"isDeleted", true}, {"lastUpdatedAt", time.Now()}}}}
+
+	res, err := r.mongoDbClient.Database("test").Collection(commentsCollection).UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.ModifiedCount == 0 {
+		return ErrCommentNotFound
+	}
+
+	return r.countReply(ctx, comment.ParentId, -1)
+}
+
+// voteComment casts or changes the vote of the user, moving the votes of the
+// comment by the difference with their previous vote.
+func (r *RepositoryImpl) voteComment(ctx context.Context, userId string, commentId string, shouldUpvote bool) error {
+	var previous CommentVote
+
+	voteFilter := bson.D{{"userId", userId}, {"commentId", commentId}}
+	update := bson.D{{"$set", bson.D{{"isUpVote", shouldUpvote}, {"isDownVote", !shouldUpvote}, {"votedAt", time.Now()}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	err := r.mongoDbClient.Database("test").Collection(commentVotesCollection).FindOneAndUpdate(ctx, voteFilter, update, opts).Decode(&previous)
+
+	if err != nil && err != mongo.ErrNoDocuments {
+		log.Println(err)
+		return UnknownError
+	}
+
+	inc := 0
+
+	if previous.IsUpVote {
+		inc--
+	}
+
+	if previous.IsDownVote {
+		inc++
+	}
+
+	if shouldUpvote {
+		inc++
+	} else {
+		inc--
+	}
+
+	if inc == 0 {
+		return nil
+	}
+
+	id, _ := primitive.ObjectIDFromHex(commentId)
+
+	_, err = r.mongoDbClient.Database("test").Collection(commentsCollection).UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$inc", bson.D{{"votes", inc}}}})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (r *RepositoryImpl) getFlaggedComments(ctx context.Context, limit int, offset int) (*PaginatedResponse[Comment], error) {
+	var comments []Comment
+
+	filter := bson.D{{"isDeleted", false}, {"isFlagged", true}}
+
+	opts := options.Find().SetLimit(int64(limit)).SetSkip(int64(offset)).SetSort(bson.D{{"createdAt", -1}})
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(commentsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &comments)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	count, err := r.mongoDbClient.Database("test").Collection(commentsCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if comments == nil {
+		comments = []Comment{}
+	}
+
+	return &PaginatedResponse[Comment]{
+		Data:         comments,
+		TotalPages:   int(math.Ceil(float64(count) / float64(limit))),
+		CurrentPage:  int(math.Ceil(float64(offset) / float64(limit))),
+		TotalItems:   int(count),
+		HasMore:      int(count) > (offset + limit),
+		ItemsPerPage: limit,
+	}, nil
+}
+
+func (r *RepositoryImpl) isGameDeveloper(ctx context.Context, userId string, gameId string) (bool, error) {
+	filter := bson.D{{"userId", userId}, {"gameId", gameId}}
+
+	count, err := r.mongoDbClient.Database("test").Collection(gameDevelopersCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return false, UnknownError
+	}
+
+	return count > 0, nil
+}
+
+// addGameDeveloper grants the user, keeping the first grant when they already have one.
+func (r *RepositoryImpl) addGameDeveloper(ctx context.Context, developer *GameDeveloper) error {
+	filter := bson.D{{"userId", developer.UserId}, {"gameId", developer.GameId}}
+	update := bson.D{{"$setOnInsert", developer}}
+	opts := options.Update().SetUpsert(true)
+
+	_, err := r.mongoDbClient.Database("test").Collection(gameDevelopersCollection).UpdateOne(ctx, filter, update, opts)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+func (r *RepositoryImpl) removeGameDeveloper(ctx context.Context, userId string, gameId string) error {
+	filter := bson.D{{"userId", userId}, {"gameId", gameId}}
+
+	res, err := r.mongoDbClient.Database("test").Collection(gameDevelopersCollection).DeleteOne(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.DeletedCount == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *RepositoryImpl) getUserEmail(ctx context.Context, userId string) (string, error) {
+	var user struct {
+		Email string `bson:"email"`
+	}
+
+	id, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return "", ErrNotFound
+	}
+
+	opts := options.FindOne().SetProjection(bson.D{{"email", 1}})
+
+	err = r.mongoDbClient.Database("test").Collection("users").FindOne(ctx, bson.D{{"_id", id}}, opts).Decode(&user)
+
+	if err != nil {
+		log.Println(err)
+		return "", ErrNotFound
+	}
+
+	return user.Email, nil
+}
+
+// getUserReviewForGame returns the review of the user for the game, nil when they have none.
+func (r *RepositoryImpl) getUserReviewForGame(ctx context.Context, userId string, gameId string) (*Review, error) {
+	var review Review
+
+	filter := bson.D{{"gameId", gameId}, {"userId", userId}, {"isDeleted", false}}
+
+	err := r.mongoDbClient.Database("test").Collection(reviewsCollection).FindOne(ctx, filter).Decode(&review)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &review, nil
+}
+
+// getDuplicateReviews groups the reviews of users who reviewed a game more than
+// once, the most recently updated review of each group first.
+func (r *RepositoryImpl) getDuplicateReviews(ctx context.Context) ([][]Review, error) {
+	var groups []struct {
+		Reviews []Review `bson:"reviews"`
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"isDeleted", false}}}},
+		{{"$sort", bson.D{{"lastUpdatedAt", -1}}}},
+		{{"$group", bson.D{
+			{"_id", bson.D{{"gameId", "$gameId"}, {"userId", "$userId"}}},
+			{"reviews", bson.D{{"$push", "$$ROOT"}}},
+			{"count", bson.D{{"$sum", 1}}},
+		}}},
+		{{"$match", bson.D{{"count", bson.D{{"$gt", 1}}}}}},
+	}
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(reviewsCollection).Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &groups)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	duplicates := make([][]Review, 0, len(groups))
+
+	for _, group := range groups {
+		duplicates = append(duplicates, group.Reviews)
+	}
+
+	return duplicates, nil
+}
+
+// addReviewRevisions saves the edit of the stored review as a revision, preceded
+// by the review as first written on its first edit.
+func (r *RepositoryImpl) addReviewRevisions(ctx context.Context, stored *Review, review *Review, changes []string) error {
+	authorId, _ := ctx.Value("userId").(string)
+
+	var revisions []interface{}
+
+	if stored.EditCount == 0 {
+		revisions = append(revisions, ReviewRevision{
+			Id:        primitive.NewObjectID(),
+			ReviewId:  stored.Id.Hex(),
+			Revision:  0,
+			AuthorId:  stored.UserId,
+			Changes:   []string{},
+			Content:   stored.content(),
+			CreatedAt: stored.CreatedAt,
+		})
+	}
+
+	revisions = append(revisions, ReviewRevision{
+		Id:        primitive.NewObjectID(),
+		ReviewId:  review.Id.Hex(),
+		Revision:  review.EditCount,
+		AuthorId:  authorId,
+		Changes:   changes,
+		Content:   review.content(),
+		CreatedAt: time.Now(),
+	})
+
+	_, err := r.mongoDbClient.Database("test").Collection(reviewRevisionsCollection).InsertMany(ctx, revisions)
+
+	return err
+}
+
+func (r *RepositoryImpl) getReviewRevisions(ctx context.Context, reviewId string, limit int, offset int) (*PaginatedResponse[ReviewRevision], error) {
+	var revisions []ReviewRevision
+
+	filter := bson.D{{"reviewId", reviewId}}
+	opts := options.Find().SetSort(bson.D{{"revision", -1}}).SetLimit(int64(limit)).SetSkip(int64(offset))
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(reviewRevisionsCollection).Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &revisions)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if len(revisions) == 0 {
+		return &PaginatedResponse[ReviewRevision]{
+			Data:        []ReviewRevision{},
+			TotalPages:  0,
+			CurrentPage: 0,
+		}, nil
+	}
+
+	count, err := r.mongoDbClient.Database("test").Collection(reviewRevisionsCollection).CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &PaginatedResponse[ReviewRevision]{
+		Data:         revisions,
+		TotalPages:   int(math.Ceil(float64(count) / float64(limit))),
+		CurrentPage:  int(math.Ceil(float64(offset) / float64(limit))),
+		TotalItems:   int(count),
+		HasMore:      int(count) > (offset + limit),
+		ItemsPerPage: limit,
+	}, nil
+}
+
+// getReviewRevision returns the revision of the review, the latest one for a negative revision.
+func (r *RepositoryImpl) getReviewRevision(ctx context.Context, reviewId string, revision int) (*ReviewRevision, error) {
+	var reviewRevision ReviewRevision
+
+	filter := bson.D{{"reviewId", reviewId}}
+	opts := options.FindOne().SetSort(bson.D{{"revision", -1}})
+
+	if revision >= 0 {
+		filter = append(filter, bson.E{Key: "revision", Value: revision})
+	}
+
+	err := r.mongoDbClient.Database("test").Collection(reviewRevisionsCollection).FindOne(ctx, filter, opts).Decode(&reviewRevision)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrReviewRevisionNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &reviewRevision, nil
+}
+
+// getReporterRecord counts the reports of the reporter moderators upheld and dismissed.
+func (r *RepositoryImpl) getReporterRecord(ctx context.Context, reporterId string) (int, int, error) {
+	var totals []struct {
+		Status string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"reporterId", reporterId}, {"status", bson.D{{"$in", bson.A{ReportUpheld, ReportDismissed}}}}}}},
+		{{"$group", bson.D{{"_id", "$status"}, {"count", bson.D{{"$sum", 1}}}}}},
+	}
+
+	cursor, err := r.mongoDbClient.Database("test").Collection(reviewReportsCollection).Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return 0, 0, UnknownError
+	}
+
+	if err = cursor.All(ctx, &totals); err != nil {
+		log.Println(err)
+		return 0, 0, UnknownError
+	}
+
+	upheld, dismissed := 0, 0
+
+	for _, total := range totals {
+		if total.Status == ReportUpheld {
+			upheld = total.Count
+		} else {
+			dismissed = total.Count
+		}
+	}
+
+	return upheld, dismissed, nil
+}
+
+// addReport saves the report, returning false when the reporter already reported
+// the review. Their pending report then takes the new reason.
+func (r *RepositoryImpl) addReport(ctx context.Context, report *ReviewReport) (bool, error) {
+	collection := r.mongoDbClient.Database("test").Collection(reviewReportsCollection)
+
+	_, err := collection.InsertOne(ctx, report)
+
+	if err == nil {
+		return true, nil
+	}
+
+	if !mongo.IsDuplicateKeyError(err) {
+		log.Println(err)
+		return false, UnknownError
+	}
+
+	filter := bson.D{{"reviewId", report.ReviewId}, {"reporterId", report.ReporterId}, {"status", ReportPending}}
+	update := bson.D{{"$set", bson.D{{"reason", report.Reason}, {"details", report.Details}}}}
+
+	_, err = collection.UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		log.Println(err)
+		return false, UnknownError
+	}
+
+	return false, nil
+}
+
+// addReportWeight adds the weight of a report to the review and hides it once
+// the weight reaches the threshold, returning whether this report hid it.
+func (r *RepositoryImpl) addReportWeight(ctx context.Context, reviewId primitive.ObjectID, weight float64, threshold float64) (bool, error) {
+	collection := r.mongoDbClient.Database("test").Collection(reviewsCollection)
+
+	_, err := collection.UpdateOne(ctx, bson.D{{"_id", reviewId}}, bson.D{{"$inc", bson.D{{"reportWeight", weight}}}})
+
+	if err != nil {
+		log.Println(err)
+		return false, UnknownError
+	}
+
+	// only the report crossing the threshold matches
+	filter := bson.D{{"_id", reviewId}, {"reportWeight", bson.D{{"$gte", threshold}}}, {"isHidden", bson.D{{"$ne", true}}}}
+	update := bson.D{{"$set", bson.D{{"isHidden", true}, {"isFlagged", true}}}}
+
+	res, err := collection.UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		log.Println(err)
+		return false, UnknownError
+	}
+
+	return res.ModifiedCount > 0, nil
+}
+
+// getReportQueue lists the reviews with pending reports by their weight, with
+// the pending reports counted by reason.
+func (r *RepositoryImpl) getReportQueue(ctx context.Context, limit int, offset int) (*PaginatedResponse[ReportedReview], error) {
+	var reviews []ReportedReview
+
+	filter := bson.D{{"isDeleted", false}, {"reportWeight", bson.D{{"$gt", 0}}}}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", filter}},
+		{{"$sort", bson.D{{"reportWeight", -1}, {"createdAt", -1}}}},
+		{{"$skip", offset}},
+		{{"$limit", limit}},
+		{{"$lookup", bson.D{
+			{"from", reviewReportsCollection},
+			{"let", bson.D{{"reviewId", bson.D{{"$toString", "$_id"}}}}},
+			{"pipeline", bson.A{
+				bson.D{{"$match", bson.D{{"$expr", bson.D{{"$and", bson.A{
+					bson.D{{"$eq", bson.A{"$reviewId", "$$reviewId"}}},
+					bson.D{{"$eq", bson.A{"$status", ReportPending}}},
+				}}}}}}},
+				bson.D{{"$group", bson.D{{"_id", "$reason"}, {"count", bson.D{{"$sum", 1}}}}}},
+			}},
+			{"as", "reasonCounts"},
+		}}},
+		{{"$set", bson.D{
+			{"reportCount", bson.D{{"$sum", "$reasonCounts.count"}}},
+			{"reasons", bson.D{{"$arrayToObject", bson.D{{"$map", bson.D{
+				{"input", "$reasonCounts"},
+				{"in", bson.D{{"k", "$$this._id"}, {"v", "$$this.count"}}},
+			}}}}}},
+		}}},
+		{{"$unset", "reasonCounts"}},
+	}
+
+	collection := r.mongoDbClient.Database("test").Collection(reviewsCollection)
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &reviews)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	count, err := collection.CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if reviews == nil {
+		reviews = []ReportedReview{}
+	}
+
+	return &PaginatedResponse[ReportedReview]{
+		Data:         reviews,
+		TotalPages:   int(math.Ceil(float64(count) / float64(limit))),
+		CurrentPage:  int(math.Ceil(float64(offset) / float64(limit))),
+		TotalItems:   int(count),
+		HasMore:      int(count) > (offset + limit),
+		ItemsPerPage: limit,
+	}, nil
+}
+
+func (r *RepositoryImpl) getReviewReports(ctx context.Context, reviewId string, limit int, offset int) (*PaginatedResponse[ReviewReport], error) {
+	var reports []ReviewReport
+
+	filter := bson.D{{"reviewId", reviewId}}
+
+	opts := options.Find().SetLimit(int64(limit)).SetSkip(int64(offset)).SetSort(bson.D{{"createdAt", -1}})
+
+	collection := r.mongoDbClient.Database("test").Collection(reviewReportsCollection)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &reports)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	count, err := collection.CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if reports == nil {
+		reports = []ReviewReport{}
+	}
+
+	return &PaginatedResponse[ReviewReport]{
+		Data:         reports,
+		TotalPages:   int(math.Ceil(float64(count) / float64(limit))),
+		CurrentPage:  int(math.Ceil(float64(offset) / float64(limit))),
+		TotalItems:   int(count),
+		HasMore:      int(count) > (offset + limit),
+		ItemsPerPage: limit,
+	}, nil
+}
+
+// resolveReports closes the pending reports of the review with the decision and
+// takes the review out of the report queue in one transaction, hidden when the
+// reports are upheld and shown again when they are dismissed.
+func (r *RepositoryImpl) resolveReports(ctx context.Context, reviewId primitive.ObjectID, decision string, moderatorId string) error {
+	session, err := r.mongoDbClient.StartSession()
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		db := r.mongoDbClient.Database("test")
+		now := time.Now()
+
+		filter := bson.D{{"reviewId", reviewId.Hex()}, {"status", ReportPending}}
+		update := bson.D{{"$set", bson.D{{"status", decision}, {"resolvedAt", now}, {"resolvedBy", moderatorId}}}}
+
+		if _, err := db.Collection(reviewReportsCollection).UpdateMany(sessCtx, filter, update); err != nil {
+			return nil, err
+		}
+
+		hidden := decision == ReportUpheld
+
+		update = bson.D{{"$set", bson.D{{"reportWeight", 0}, {"isHidden", hidden}, {"isFlagged", hidden}}}}
+
+		if _, err := db.Collection(reviewsCollection).UpdateOne(sessCtx, bson.D{{"_id", reviewId}}, update); err != nil {
+			return nil, err
+		}
+
+		// the active case of the review is decided along, or it would stay in the
+		// queue and a later decision would flip the review again. A case decided
+		// by decideCase is no longer active.
+		status := CaseApproved
+		if hidden {
+			status = CaseRemoved
+		}
+
+		reason := "reports " + decision
+
+		update = bson.D{
+			{"$set", bson.D{
+				{"status", status},
+				{"active", false},
+				{"decision", status},
+				{"reason", reason},
+				{"decidedBy", moderatorId},
+				{"decidedAt", now},
+				{"updatedAt", now},
+			}},
+			{"$push", bson.D{{"history", CaseEvent{Status: status, ActorId: moderatorId, Reason: reason, At: now}}}},
+			{"$inc", bson.D{{"version", 1}}},
+		}
+
+		_, err := db.Collection(moderationCasesCollection).UpdateOne(sessCtx, bson.D{{"reviewId", reviewId.Hex()}, {"active", true}}, update)
+
+		return nil, err
+	})
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	return nil
+}
+
+// getReviewIncludingDeleted returns the review even when deleted, for moderators
+// restoring a removed review.
+func (r *RepositoryImpl) getReviewIncludingDeleted(ctx context.Context, id string) (*Review, error) {
+	var review Review
+
+	rawId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+
+	err = r.mongoDbClient.Database("test").Collection(reviewsCollection).FindOne(ctx, bson.D{{"_id", rawId}}).Decode(&review)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrReviewNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &review, nil
+}
+
+// openCase saves the case, returning false when the review already has an active one.
+func (r *RepositoryImpl) openCase(ctx context.Context, moderationCase *ModerationCase) (bool, error) {
+	_, err := r.mongoDbClient.Database("test").Collection(moderationCasesCollection).InsertOne(ctx, moderationCase)
+
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		log.Println(err)
+		return false, UnknownError
+	}
+
+	return true, nil
+}
+
+func (r *RepositoryImpl) getCase(ctx context.Context, caseId string) (*ModerationCase, error) {
+	var moderationCase ModerationCase
+
+	rawId, err := primitive.ObjectIDFromHex(caseId)
+	if err != nil {
+		return nil, ErrCaseNotFound
+	}
+
+	err = r.mongoDbClient.Database("test").Collection(moderationCasesCollection).FindOne(ctx, bson.D{{"_id", rawId}}).Decode(&moderationCase)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrCaseNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &moderationCase, nil
+}
+
+// getLatestCase returns the last opened case of the review.
+func (r *RepositoryImpl) getLatestCase(ctx context.Context, reviewId string) (*ModerationCase, error) {
+	var moderationCase ModerationCase
+
+	opts := options.FindOne().SetSort(bson.D{{"openedAt", -1}})
+
+	err := r.mongoDbClient.Database("test").Collection(moderationCasesCollection).FindOne(ctx, bson.D{{"reviewId", reviewId}}, opts).Decode(&moderationCase)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrCaseNotFound
+	}
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	return &moderationCase, nil
+}
+
+func (r *RepositoryImpl) getCases(ctx context.Context, req *GetModerationCases) (*PaginatedResponse[ModerationCase], error) {
+	var cases []ModerationCase
+
+	filter := bson.D{}
+
+	if req.Status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: req.Status})
+	}
+
+	if req.AssigneeId != "" {
+		filter = append(filter, bson.E{Key: "assigneeId", Value: req.AssigneeId})
+	} else if req.Unassigned {
+		filter = append(filter, bson.E{Key: "active", Value: true}, bson.E{Key: "assigneeId", Value: bson.D{{"$exists", false}}})
+	}
+
+	if req.GameId != "" {
+		filter = append(filter, bson.E{Key: "gameId", Value: req.GameId})
+	}
+
+	if req.Source != "" {
+		filter = append(filter, bson.E{Key: "source", Value: req.Source})
+	}
+
+	if req.Appealed {
+		filter = append(filter, bson.E{Key: "appeal", Value: bson.D{{"$exists", true}}})
+	}
+
+	if req.Overdue {
+		filter = append(filter, bson.E{Key: "active", Value: true}, bson.E{Key: "dueAt", Value: bson.D{{"$lt", time.Now()}}})
+	}
+
+	sort := bson.D{{"dueAt", 1}}
+	if req.Sort == "newest" {
+		sort = bson.D{{"openedAt", -1}}
+	}
+
+	opts := options.Find().SetLimit(int64(req.Limit)).SetSkip(int64(req.Offset)).SetSort(sort)
+
+	collection := r.mongoDbClient.Database("test").Collection(moderationCasesCollection)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	err = cursor.All(ctx, &cases)
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	count, err := collection.CountDocuments(ctx, filter)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if cases == nil {
+		cases = []ModerationCase{}
+	}
+
+	return &PaginatedResponse[ModerationCase]{
+		Data:         cases,
+		TotalPages:   int(math.Ceil(float64(count) / float64(req.Limit))),
+		CurrentPage:  int(math.Ceil(float64(req.Offset) / float64(req.Limit))),
+		TotalItems:   int(count),
+		HasMore:      int(count) > (req.Offset + req.Limit),
+		ItemsPerPage: req.Limit,
+	}, nil
+}
+
+// updateCase saves the case if nobody saved it since it was read, so of two
+// moderators moving it at once, assigning or deciding it, only one does and the
+// other has to reload it. The version of the case counts its saves.
+func (r *RepositoryImpl) updateCase(ctx context.Context, moderationCase *ModerationCase) error {
+	version := bson.D{{"version", moderationCase.Version}}
+
+	if moderationCase.Version == 0 {
+		// the cases from before the saves were counted have no version
+		version = bson.D{{"version", bson.D{{"$in", bson.A{0, nil}}}}}
+	}
+
+	filter := append(bson.D{{"_id", moderationCase.Id}}, version...)
+
+	moderationCase.Version++
+
+	res, err := r.mongoDbClient.Database("test").Collection(moderationCasesCollection).ReplaceOne(ctx, filter, moderationCase)
+
+	if mongo.IsDuplicateKeyError(err) {
+		// another case of the review became active meanwhile
+		return ErrCaseConflict
+	}
+
+	if err != nil {
+		log.Println(err)
+		return UnknownError
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrCaseConflict
+	}
+
+	return nil
+}
+
+// getModerationMetrics counts the active cases by state and the overdue ones,
+// and measures the cases opened and decided since the time.
+func (r *RepositoryImpl) getModerationMetrics(ctx context.Context, since time.Time) (*ModerationMetrics, error) {
+	collection := r.mongoDbClient.Database("test").Collection(moderationCasesCollection)
+	now := time.Now()
+
+	metrics := &ModerationMetrics{
+		Since:    since,
+		SlaHours: caseSla.Hours(),
+		Active:   map[string]int{},
+		Decided:  map[string]int{},
+	}
+
+	var active []struct {
+		Status  string `bson:"_id"`
+		Count   int    `bson:"count"`
+		Overdue int    `bson:"overdue"`
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"active", true}}}},
+		{{"$group", bson.D{
+			{"_id", "$status"},
+			{"count", bson.D{{"$sum", 1}}},
+			{"overdue", bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$lt", bson.A{"$dueAt", now}}}, 1, 0}}}}}},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &active); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	for _, total := range active {
+		metrics.Active[total.Status] = total.Count
+		metrics.Overdue += total.Overdue
+	}
+
+	opened, err := collection.CountDocuments(ctx, bson.D{{"openedAt", bson.D{{"$gte", since}}}})
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	metrics.Opened = int(opened)
+
+	appealed, err := collection.CountDocuments(ctx, bson.D{{"appeal.createdAt", bson.D{{"$gte", since}}}})
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	metrics.Appealed = int(appealed)
+
+	var decided []struct {
+		Decision       string  `bson:"_id"`
+		Count          int     `bson:"count"`
+		WithinSla      int     `bson:"withinSla"`
+		MillisToAssign float64 `bson:"millisToAssign"`
+		Assigned       int     `bson:"assigned"`
+		MillisToDecide float64 `bson:"millisToDecide"`
+	}
+
+	pipeline = mongo.Pipeline{
+		{{"$match", bson.D{{"active", false}, {"decidedAt", bson.D{{"$gte", since}}}}}},
+		{{"$group", bson.D{
+			{"_id", "$decision"},
+			{"count", bson.D{{"$sum", 1}}},
+			{"withinSla", bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$lte", bson.A{"$decidedAt", "$dueAt"}}}, 1, 0}}}}}},
+			{"millisToAssign", bson.D{{"$sum", bson.D{{"$cond", bson.A{
+				bson.D{{"$gt", bson.A{"$assignedAt", nil}}},
+				bson.D{{"$subtract", bson.A{"$assignedAt", "$openedAt"}}},
+				0,
+			}}}}}},
+			{"assigned", bson.D{{"$sum", bson.D{{"$cond", bson.A{bson.D{{"$gt", bson.A{"$assignedAt", nil}}}, 1, 0}}}}}},
+			{"millisToDecide", bson.D{{"$sum", bson.D{{"$subtract", bson.A{"$decidedAt", "$openedAt"}}}}}},
+		}}},
+	}
+
+	cursor, err = collection.Aggregate(ctx, pipeline)
+
+	if err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	if err = cursor.All(ctx, &decided); err != nil {
+		log.Println(err)
+		return nil, UnknownError
+	}
+
+	count, assigned := 0, 0
+	millisToAssign, millisToDecide := 0.0, 0.0
+
+	for _, total := range decided {
+		metrics.Decided[total.Decision] = total.Count
+		metrics.DecidedWithinSla += total.WithinSla
+
+		count += total.Count
+		assigned += total.Assigned
+		millisToAssign += total.MillisToAssign
+		millisToDecide += total.MillisToDecide
+	}
+
+	if assigned > 0 {
+		metrics.AvgMinutesToAssign = millisToAssign / float64(assigned) / float64(time.Minute/time.Millisecond)
+	}
+
+	if count > 0 {
+		metrics.AvgMinutesToDecision = millisToDecide / float64(count) / float64(time.Minute/time.Millisecond)
+	}
+
+	return metrics, nil
+}
@@ -4,14 +4,34 @@ import (
 	"context"
 	"github.com/gofiber/fiber/v2"
 	auth "go-server/pkg/authentication"
+	"go-server/pkg/games"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func Register(mongoClient *mongo.Client, ctx context.Context, app fiber.Router, authNeeds *auth.AuthNeeds) error {
+func Register(mongoClient *mongo.Client, ctx context.Context, app fiber.Router, authNeeds *auth.AuthNeeds, gameContent GameContentGate) error {
 
-	repo := NewRepository(mongoClient)
+	repo := NewRepository(mongoClient, games.GetRatingPrior())
 
-	service := NewService(repo)
+	service := NewService(repo, gameContent)
+
+	// the existing duplicates would keep the unique index from being built
+	if _, err := service.dedupeReviews(ctx); err != nil {
+		return err
+	}
+
+	// the duplicate votes would keep the unique vote index from being built
+	if _, err := service.dedupeVotes(ctx); err != nil {
+		return err
+	}
+
+	// the reviews from before the upvotes and downvotes were counted apart
+	if _, err := service.backfillVoteCounts(ctx); err != nil {
+		return err
+	}
+
+	if err := repo.ensureIndexes(ctx); err != nil {
+		return err
+	}
 
 	handler := NewHandler(service)
 
@@ -0,0 +1,244 @@
+package reviews
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
+)
+
+// ReviewContent is what the author of a review can edit.
+type ReviewContent struct {
+	Rating    int            `json:"rating" bson:"rating"`
+	SubScores map[string]int `json:"subScores,omitempty" bson:"subScores,omitempty"`
+	Comment   string         `json:"comment" bson:"comment"`
+}
+
+// ReviewRevision is the content of a review as of one of its edits, revision 0
+// being the review as first written. Revisions are never changed once saved,
+// Changes lists the fields that differ from the revision before.
+type ReviewRevision struct {
+	Id        primitive.ObjectID `json:"id" bson:"_id"`
+	ReviewId  string             `json:"reviewId" bson:"reviewId"`
+	Revision  int                `json:"revision" bson:"revision"`
+	AuthorId  string             `json:"authorId" bson:"authorId"`
+	Changes   []string           `json:"changes" bson:"changes"`
+	Content   ReviewContent      `json:"content" bson:"content"`
+	CreatedAt time.Time          `json:"createdAt" bson:"createdAt"`
+}
+
+// ReviewFieldChange is a field of the review with its values in the two revisions.
+type ReviewFieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// diff operations of the comment
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// TextDiffOp is a run of words kept, inserted or deleted between two revisions.
+type TextDiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// ReviewDiff lists what changed from one revision of a review to another, with
+// a word diff of the comment when it changed.
+type ReviewDiff struct {
+	ReviewId string              `json:"reviewId"`
+	From     ReviewRevision      `json:"from"`
+	To       ReviewRevision      `json:"to"`
+	Changes  []ReviewFieldChange `json:"changes"`
+	Comment  []TextDiffOp        `json:"comment,omitempty"`
+}
+
+func (r *Review) content() ReviewContent {
+	return ReviewContent{
+		Rating:    r.Rating,
+		SubScores: r.SubScores,
+		Comment:   r.Comment,
+	}
+}
+
+// newReviewResponse shows the review with its author, edited when it has revisions.
+func newReviewResponse(review Review, user User) ReviewResponse {
+	return ReviewResponse{
+		Review:      review,
+		User:        user,
+		Edited:      review.EditCount > 0,
+		EditCount:   review.EditCount,
+		CommentHtml: renderMarkup(review.Comment),
+	}
+}
+
+// diffReviewContent returns the json names of the fields that differ.
+func diffReviewContent(old ReviewContent, new ReviewContent) []string {
+	var changes []string
+
+	if old.Rating != new.Rating {
+		changes = append(changes, "rating")
+	}
+
+	if !sameSubScores(old.SubScores, new.SubScores) {
+		changes = append(changes, "subScores")
+	}
+
+	if old.Comment != new.Comment {
+		changes = append(changes, "comment")
+	}
+
+	return changes
+}
+
+func sameSubScores(a map[string]int, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for dimension, score := range a {
+		if other, ok := b[dimension]; !ok || other != score {
+			return false
+		}
+	}
+
+	return true
+}
+
+// getReviewRevisions lists the revisions of a review, newest first, for moderators.
+func (s *Service) getReviewRevisions(ctx context.Context, reviewId string, limit int, offset int) (*PaginatedResponse[ReviewRevision], error) {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	if limit < 1 {
+		limit = 10
+	}
+
+	if limit > 100 {
+		limit = 100
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repository.getReviewRevisions(ctx, reviewId, limit, offset)
+}
+
+// diffReviewRevisions compares two revisions of a review, for moderators. A
+// negative to stands for the latest revision and a negative from for the one
+// before to.
+func (s *Service) diffReviewRevisions(ctx context.Context, reviewId string, from int, to int) (*ReviewDiff, error) {
+	role := ctx.Value("role").(string)
+
+	if role != "admin" && role != "moderator" {
+		return nil, ErrUnauthorized
+	}
+
+	toRevision, err := s.repository.getReviewRevision(ctx, reviewId, to)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if from < 0 {
+		from = toRevision.Revision - 1
+	}
+
+	fromRevision, err := s.repository.getReviewRevision(ctx, reviewId, from)
+
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ReviewDiff{
+		ReviewId: reviewId,
+		From:     *fromRevision,
+		To:       *toRevision,
+		Changes:  []ReviewFieldChange{},
+	}
+
+	before, after := fromRevision.Content, toRevision.Content
+
+	for _, field := range diffReviewContent(before, after) {
+		switch field {
+		case "rating":
+			diff.Changes = append(diff.Changes, ReviewFieldChange{Field: field, Before: before.Rating, After: after.Rating})
+		case "subScores":
+			diff.Changes = append(diff.Changes, ReviewFieldChange{Field: field, Before: before.SubScores, After: after.SubScores})
+		case "comment":
+			diff.Changes = append(diff.Changes, ReviewFieldChange{Field: field, Before: before.Comment, After: after.Comment})
+			diff.Comment = diffWords(before.Comment, after.Comment)
+		}
+	}
+
+	return diff, nil
+}
+
+// diffWords is a word diff of two texts from their longest common subsequence,
+// consecutive words with the same operation being joined in one run.
+func diffWords(old string, new string) []TextDiffOp {
+	a, b := strings.Fields(old), strings.Fields(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []TextDiffOp
+
+	add := func(op string, word string) {
+		if len(ops) > 0 && ops[len(ops)-1].Op == op {
+			ops[len(ops)-1].Text += " " + word
+			return
+		}
+
+		ops = append(ops, TextDiffOp{Op: op, Text: word})
+	}
+
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		if a[i] == b[j] {
+			add(DiffEqual, a[i])
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			add(DiffDelete, a[i])
+			i++
+		} else {
+			add(DiffInsert, b[j])
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		add(DiffDelete, a[i])
+	}
+
+	for ; j < len(b); j++ {
+		add(DiffInsert, b[j])
+	}
+
+	return ops
+}
@@ -36,11 +36,65 @@ func router(ctx context.Context, app fiber.Router, handler *Handler, middleware
 
 	app.Post("/:id/downvote/", HandleVoteReview(handler, ctx, false))
 
+	app.Delete("/:id/vote/", HandleUnVoteReview(handler, ctx))
+
+	app.Post("/:id/report/", HandleReportReview(handler, ctx))
+
+	app.Post("/:id/appeal/", HandleAppealCase(handler, ctx))
+
+	app.Get("/:id/comments/", HandleGetComments(handler, ctx))
+
+	app.Post("/:id/comments/", HandleAddComment(handler, ctx))
+
+	app.Get("/:id/comments/:commentId/replies/", HandleGetReplies(handler, ctx))
+
+	app.Put("/:id/comments/:commentId/", HandleUpdateComment(handler, ctx))
+
+	app.Delete("/:id/comments/:commentId/", HandleDeleteComment(handler, ctx))
+
+	app.Post("/:id/comments/:commentId/upvote/", HandleVoteComment(handler, ctx, true))
+
+	app.Post("/:id/comments/:commentId/downvote/", HandleVoteComment(handler, ctx, false))
+
 	app.Use(middleware.AuthMiddleware(isAdminOrModerator))
 	app.Post("/:id/unflag/", HandleUnflagReview(handler, ctx))
 
 	app.Post("/:id/flag/", HandleFlagReview(handler, ctx))
 
+	app.Post("/:id/spoiler/", HandleMarkSpoilers(handler, ctx, true))
+
+	app.Post("/:id/unspoiler/", HandleMarkSpoilers(handler, ctx, false))
+
+	app.Get("/:id/revisions/", HandleGetReviewRevisions(handler, ctx))
+
+	app.Get("/:id/revisions/diff/", HandleDiffReviewRevisions(handler, ctx))
+
+	app.Get("/reports/queue/", HandleGetReportQueue(handler, ctx))
+
+	app.Get("/moderation/cases/", HandleGetModerationCases(handler, ctx))
+
+	app.Get("/moderation/metrics/", HandleGetModerationMetrics(handler, ctx))
+
+	app.Get("/moderation/cases/:caseId/", HandleGetModerationCase(handler, ctx))
+
+	app.Post("/moderation/cases/:caseId/assign/", HandleAssignCase(handler, ctx))
+
+	app.Post("/moderation/cases/:caseId/decide/", HandleDecideCase(handler, ctx))
+
+	app.Get("/:id/reports/", HandleGetReviewReports(handler, ctx))
+
+	app.Post("/:id/reports/resolve/", HandleResolveReports(handler, ctx))
+
+	app.Get("/comments/flagged/", HandleGetFlaggedComments(handler, ctx))
+
+	app.Post("/:id/comments/:commentId/unflag/", HandleFlagComment(handler, ctx, false))
+
+	app.Post("/:id/comments/:commentId/flag/", HandleFlagComment(handler, ctx, true))
+
+	app.Post("/developers/", HandleAddGameDeveloper(handler, ctx))
+
+	app.Delete("/developers/:gameId/:userId/", HandleRemoveGameDeveloper(handler, ctx))
+
 	return nil
 }
 
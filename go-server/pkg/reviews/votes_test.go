@@ -0,0 +1,250 @@
+package reviews
+
+import (
+	"context"
+	"github.com/gofiber/fiber/v2"
+	auth "go-server/pkg/authentication"
+	"go-server/pkg/games"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"math"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MongoDbTestUri is the replica set the vote tests run against, they need
+// transactions. The tests write to the test database and are skipped without it.
+const MongoDbTestUri = "MONGODB_TEST_URI"
+
+// concurrentVotes is how many requests each test sends at once.
+const concurrentVotes = 24
+
+// testMiddleware authenticates every request as the user in the X-User-Id header.
+type testMiddleware struct{}
+
+func (testMiddleware) AuthMiddleware(authCheck func(claims *auth.JwtClaims) (string, bool)) interface{} {
+	return func(c *fiber.Ctx) error {
+		c.Locals("userId", c.Get("X-User-Id"))
+		c.Locals("role", "user")
+		return c.Next()
+	}
+}
+
+type voteTest struct {
+	client   *mongo.Client
+	app      *fiber.App
+	reviewId primitive.ObjectID
+}
+
+func newVoteTest(t *testing.T) *voteTest {
+	uri := os.Getenv(MongoDbTestUri)
+
+	if uri == "" {
+		t.Skip(MongoDbTestUri + " is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		client.Disconnect(context.Background())
+	})
+
+	repo := NewRepository(client, games.GetRatingPrior())
+
+	if err = repo.ensureIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	reviewId := primitive.NewObjectID()
+
+	review := bson.D{
+		{"_id", reviewId},
+		{"gameId", primitive.NewObjectID().Hex()},
+		{"userId", "author-" + reviewId.Hex()},
+		{"rating", 4},
+		{"isDeleted", false},
+		{"votes", 0},
+		{"upVotes", 0},
+		{"downVotes", 0},
+		{"helpfulness", 0.0},
+	}
+
+	if _, err = client.Database("test").Collection(reviewsCollection).InsertOne(ctx, review); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db := client.Database("test")
+		db.Collection(reviewsCollection).DeleteOne(context.Background(), bson.D{{"_id", reviewId}})
+		db.Collection(votesCollection).DeleteMany(context.Background(), bson.D{{"reviewId", reviewId.Hex()}})
+	})
+
+	app := fiber.New()
+	appCtx := context.WithValue(context.Background(), "apiVersion", "/v1")
+
+	if err = router(appCtx, app.Group("/api/"), NewHandler(NewService(repo, nil)), testMiddleware{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return &voteTest{client: client, app: app, reviewId: reviewId}
+}
+
+// send sends the requests at once, as the user, and fails the test on any
+// request that does not succeed.
+func (v *voteTest) send(t *testing.T, userId string, requests [][2]string) {
+	var wg sync.WaitGroup
+
+	for _, request := range requests {
+		wg.Add(1)
+
+		go func(method string, path string) {
+			defer wg.Done()
+
+			req := httptest.NewRequest(method, "/api/v1/reviews/"+v.reviewId.Hex()+path, nil)
+			req.Header.Set("X-User-Id", userId)
+
+			resp, err := v.app.Test(req, -1)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			if resp.StatusCode != fiber.StatusOK && resp.StatusCode != fiber.StatusAccepted {
+				t.Errorf("%s %s: status %d", method, path, resp.StatusCode)
+			}
+		}(request[0], request[1])
+	}
+
+	wg.Wait()
+}
+
+// assertCounts checks the votes of the user on the review against the counts
+// of the review, and returns the vote, nil when the user has none.
+func (v *voteTest) assertCounts(t *testing.T, userId string) *Vote {
+	ctx := context.Background()
+	db := v.client.Database("test")
+
+	cursor, err := db.Collection(votesCollection).Find(ctx, bson.D{{"userId", userId}, {"reviewId", v.reviewId.Hex()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var votes []Vote
+	if err = cursor.All(ctx, &votes); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(votes) > 1 {
+		t.Fatalf("got %d votes of the user, want at most 1", len(votes))
+	}
+
+	var review Review
+	if err = db.Collection(reviewsCollection).FindOne(ctx, bson.D{{"_id", v.reviewId}}).Decode(&review); err != nil {
+		t.Fatal(err)
+	}
+
+	up, down := 0, 0
+	var vote *Vote
+
+	if len(votes) == 1 {
+		vote = &votes[0]
+
+		if vote.IsUpVote == vote.IsDownVote {
+			t.Fatalf("vote is up %t and down %t, want exactly one", vote.IsUpVote, vote.IsDownVote)
+		}
+
+		if vote.IsUpVote {
+			up = 1
+		} else {
+			down = 1
+		}
+	}
+
+	if review.UpVotes != up || review.DownVotes != down {
+		t.Errorf("got %d upvotes and %d downvotes, want %d and %d", review.UpVotes, review.DownVotes, up, down)
+	}
+
+	if review.Votes != up-down {
+		t.Errorf("got %d votes, want %d", review.Votes, up-down)
+	}
+
+	if want := wilsonLowerBound(up, down); math.Abs(review.Helpfulness-want) > 1e-9 {
+		t.Errorf("got helpfulness %f, want %f", review.Helpfulness, want)
+	}
+
+	return vote
+}
+
+func TestConcurrentUpVotesCountOnce(t *testing.T) {
+	v := newVoteTest(t)
+	userId := "voter-" + primitive.NewObjectID().Hex()
+
+	requests := make([][2]string, concurrentVotes)
+	for i := range requests {
+		requests[i] = [2]string{fiber.MethodPost, "/upvote/"}
+	}
+
+	v.send(t, userId, requests)
+
+	if vote := v.assertCounts(t, userId); vote == nil || !vote.IsUpVote {
+		t.Errorf("got vote %+v, want an upvote", vote)
+	}
+}
+
+func TestConcurrentUpAndDownVotesCountTheLastVote(t *testing.T) {
+	v := newVoteTest(t)
+	userId := "voter-" + primitive.NewObjectID().Hex()
+
+	requests := make([][2]string, concurrentVotes)
+	for i := range requests {
+		if i%2 == 0 {
+			requests[i] = [2]string{fiber.MethodPost, "/upvote/"}
+		} else {
+			requests[i] = [2]string{fiber.MethodPost, "/downvote/"}
+		}
+	}
+
+	v.send(t, userId, requests)
+
+	if vote := v.assertCounts(t, userId); vote == nil {
+		t.Error("got no vote, want the last one")
+	}
+}
+
+func TestConcurrentVotesAndUnVotesCountTheLastVote(t *testing.T) {
+	v := newVoteTest(t)
+	userId := "voter-" + primitive.NewObjectID().Hex()
+
+	requests := make([][2]string, concurrentVotes)
+	for i := range requests {
+		switch i % 3 {
+		case 0:
+			requests[i] = [2]string{fiber.MethodPost, "/upvote/"}
+		case 1:
+			requests[i] = [2]string{fiber.MethodPost, "/downvote/"}
+		default:
+			requests[i] = [2]string{fiber.MethodDelete, "/vote/"}
+		}
+	}
+
+	v.send(t, userId, requests)
+	v.assertCounts(t, userId)
+
+	// the counts must not have drifted from the votes, whichever request came last
+	v.send(t, userId, [][2]string{{fiber.MethodPost, "/upvote/"}})
+
+	if vote := v.assertCounts(t, userId); vote == nil || !vote.IsUpVote {
+		t.Errorf("got vote %+v, want an upvote", vote)
+	}
+}
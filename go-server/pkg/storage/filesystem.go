@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FileSystemStorage keeps blobs as plain files under a root directory,
+// it is meant for local development and tests.
+type FileSystemStorage struct {
+	root string
+}
+
+func NewFileSystemStorage(root string) *FileSystemStorage {
+	return &FileSystemStorage{
+		root: root,
+	}
+}
+
+func (f *FileSystemStorage) Put(ctx context.Context, key string, contentType string, body io.Reader) (*BlobInfo, error) {
+	filePath, err := f.getPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	// write to a temp file first so readers never see a partial blob
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		log.Println(err)
+		return nil, err
+	}
+
+	_, err = io.Copy(tmp, body)
+	closeErr := tmp.Close()
+
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), filePath)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		log.Println(err)
+		return nil, err
+	}
+
+	return f.stat(key, filePath, contentType)
+}
+
+func (f *FileSystemStorage) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	filePath, err := f.getPath(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info, err := f.stat(key, filePath, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, ErrBlobNotFound
+	}
+
+	return file, info, nil
+}
+
+func (f *FileSystemStorage) Delete(ctx context.Context, key string) error {
+	filePath, err := f.getPath(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filePath)
+
+	if err != nil && !os.IsNotExist(err) {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+func (f *FileSystemStorage) stat(key string, filePath string, contentType string) (*BlobInfo, error) {
+	stat, err := os.Stat(filePath)
+	if err != nil || stat.IsDir() {
+		return nil, ErrBlobNotFound
+	}
+
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filePath))
+	}
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	return &BlobInfo{
+		Key:          key,
+		ContentType:  contentType,
+		Size:         stat.Size(),
+		LastModified: stat.ModTime(),
+		ETag:         fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()),
+	}, nil
+}
+
+// getPath maps a key to a file under root, rejecting keys that would escape it.
+func (f *FileSystemStorage) getPath(key string) (string, error) {
+	if key == "" || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean("/" + key)
+
+	if cleaned == "/" || cleaned != "/"+key {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(f.root, filepath.FromSlash(cleaned)), nil
+}
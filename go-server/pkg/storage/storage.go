@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrBlobNotFound = errors.New("blob-not-found")
+
+var ErrInvalidKey = errors.New("invalid-blob-key")
+
+type BlobInfo struct {
+	Key          string
+	ContentType  string
+	Size         int64
+	LastModified time.Time
+	ETag         string
+}
+
+// BlobStorage stores opaque blobs by key. Keys are slash separated paths
+// such as "games/<gameId>/<mediaId>/thumb.webp".
+type BlobStorage interface {
+	Put(ctx context.Context, key string, contentType string, body io.Reader) (*BlobInfo, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+}